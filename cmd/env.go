@@ -26,6 +26,7 @@ var envList = []string{
 	localdata.EnvNameSSHPassPrompt,
 	localdata.EnvNameSSHPath,
 	localdata.EnvNameSCPPath,
+	localdata.EnvNameSSHAllowedHosts,
 	localdata.EnvNameKeepSourceTarget,
 	localdata.EnvNameMirrorSyncScript,
 	localdata.EnvNameLogPath,
@@ -16,7 +16,11 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/environment"
@@ -28,7 +32,12 @@ import (
 func newHistoryCmd() *cobra.Command {
 	rows := 100
 	var displayMode string
+	var output string
 	var all bool
+	var since time.Duration
+	var sortBy string
+	var compact bool
+	var compactDays int
 	cmd := &cobra.Command{
 		Use:   "history <rows>",
 		Short: "Display the historical execution record of TiUP, displays 100 lines by default",
@@ -42,12 +51,48 @@ func newHistoryCmd() *cobra.Command {
 			}
 
 			env := environment.GlobalEnv()
-			rows, err := env.GetHistory(rows, all)
+			if compact {
+				n, err := env.CompactHistory(compactDays)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Compacted %d history files into monthly archives\n", n)
+				return nil
+			}
+
+			if cmd.Flags().Changed("format") {
+				if cmd.Flags().Changed("output") {
+					return errors.Errorf("--format and --output can't be used together, please use --output only")
+				}
+				// --format json used to print one record per line
+				output = displayMode
+				if output == "json" {
+					output = "json-lines"
+				}
+			}
+
+			rows, err := env.GetHistory(rows, all, since)
 			if err != nil {
 				return err
 			}
+			if err := environment.SortHistory(rows, sortBy); err != nil {
+				return err
+			}
 
-			if displayMode == "json" {
+			switch output {
+			case "default":
+			case "json":
+				if len(rows) == 0 {
+					fmt.Println("[]")
+					return nil
+				}
+				data, err := json.MarshalIndent(rows, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			case "json-lines":
 				for _, r := range rows {
 					rBytes, err := json.Marshal(r)
 					if err != nil {
@@ -56,15 +101,27 @@ func newHistoryCmd() *cobra.Command {
 					fmt.Println(string(rBytes))
 				}
 				return nil
+			default:
+				return errors.Errorf("unsupported output %s, available values are [default, json, json-lines]", output)
 			}
+
 			var table [][]string
-			table = append(table, []string{"Date", "Command", "Code"})
+			table = append(table, []string{"Date", "ID", "User", "Host", "Command", "Code", "Duration", "Reason"})
 
 			for _, r := range rows {
+				duration := "-"
+				if r.Duration > 0 {
+					duration = r.Duration.Round(time.Millisecond).String()
+				}
 				table = append(table, []string{
 					r.Date.Format("2006-01-02T15:04:05"),
+					r.CorrelationID,
+					r.User,
+					r.Host,
 					r.Command,
 					strconv.Itoa(r.Code),
+					duration,
+					r.FailureReason(),
 				})
 			}
 			tui.PrintTable(table, true)
@@ -72,9 +129,16 @@ func newHistoryCmd() *cobra.Command {
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&displayMode, "format", "default", "The format of output, available values are [default, json]")
+	cmd.Flags().StringVar(&displayMode, "format", "default", "The format of output, available values are [default, json], json prints one JSON object per line")
+	cmd.Flags().StringVar(&output, "output", "default", "The format of output, available values are [default, json, json-lines], json prints the records as a single JSON array and json-lines prints one JSON object per line")
+	_ = cmd.Flags().MarkDeprecated("format", "please use --output instead, --format json is the same as --output json-lines")
 	cmd.Flags().BoolVar(&all, "all", false, "Display all execution history")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only display the execution history within the duration, e.g. 2h")
+	cmd.Flags().StringVar(&sortBy, "sort", environment.HistorySortTimeAsc, fmt.Sprintf("The order to display the history by, available values are [%s]", strings.Join(environment.HistorySortOrders, ", ")))
+	cmd.Flags().BoolVar(&compact, "compact", false, "Merge the history files into monthly archives to speed up reading, instead of displaying the history")
+	cmd.Flags().IntVar(&compactDays, "compact-days", 30, "Only compact the history files last written more than the days ago")
 	cmd.AddCommand(newHistoryCleanupCmd())
+	cmd.AddCommand(newHistoryReplayCmd())
 	return cmd
 }
 
@@ -104,3 +168,46 @@ func newHistoryCleanupCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
 	return cmd
 }
+
+func newHistoryReplayCmd() *cobra.Command {
+	var skipConfirm bool
+	cmd := &cobra.Command{
+		Use:   "replay <n|id>",
+		Short: "Run a historical command again, selected by its position back from the latest one or its ID",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			env := environment.GlobalEnv()
+			row, err := env.FindHistory(args[0])
+			if err != nil {
+				return err
+			}
+			replayArgs, err := row.ReplayArgs()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Command ran at %s: %s\n", row.Date.Format("2006-01-02T15:04:05"), row.Command)
+			if !skipConfirm {
+				if err := tui.PromptForConfirmOrAbortError("Do you want to run it again? [y/N]: "); err != nil {
+					return err
+				}
+			}
+
+			bin, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			c := exec.Command(bin, replayArgs...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			return c.Run()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	return cmd
+}
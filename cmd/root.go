@@ -44,6 +44,7 @@ var (
 	reportEnabled bool // is telemetry report enabled
 	eventUUID     = uuid.New().String()
 	teleCommand   string
+	startedAt     time.Time                  // time the command started at, before the env is initialized
 	log           = logprinter.NewLogger("") // use default logger
 )
 
@@ -232,18 +233,25 @@ the latest stable version will be downloaded from the repository.`,
 
 // Execute parses the command line arguments and calls proper functions
 func Execute() {
-	start := time.Now()
+	// the env doesn't exist yet, so it's the system time rather than the
+	// clock of the env, the initialization of the env is counted as well
+	startedAt = time.Now()
 	code := 0
+	// generate the ID before running components so that they inherit it
+	logprinter.CorrelationID()
 
 	err := rootCmd.Execute()
+	stderr := ""
 	if err != nil {
 		// use exit code from component
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			code = exitErr.ExitCode()
+			stderr = tiupexec.StderrTail()
 		} else {
 			fmt.Fprintln(os.Stderr, color.RedString("Error: %v", err))
 			code = 1
+			stderr = fmt.Sprintf("Error: %v", err)
 		}
 	}
 
@@ -260,7 +268,7 @@ func Execute() {
 		reportEnabled = false
 	} else {
 		// record TiUP execution history
-		err := environment.HistoryRecord(env, os.Args, start, code)
+		err := environment.HistoryRecord(env, os.Args, startedAt, code, stderr)
 		if err != nil {
 			log.Warnf("Record TiUP execution history log failed: %v", err)
 		}
@@ -277,7 +285,7 @@ func Execute() {
 
 	if reportEnabled {
 		teleReport.EventUUID = eventUUID
-		teleReport.EventUnixTimestamp = start.Unix()
+		teleReport.EventUnixTimestamp = startedAt.Unix()
 		teleReport.Version = telemetry.TiUPMeta()
 		teleReport.Version.TiUPVersion = version.NewTiUPVersion().SemVer()
 		tiupReport.Command = teleCommand
@@ -296,7 +304,7 @@ func Execute() {
 			}()
 
 			tiupReport.ExitCode = int32(code)
-			tiupReport.TakeMilliseconds = uint64(time.Since(start).Milliseconds())
+			tiupReport.TakeMilliseconds = uint64(time.Since(startedAt).Milliseconds())
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 			tele := telemetry.NewTelemetry()
 			err := tele.Report(ctx, teleReport)
@@ -14,6 +14,9 @@
 package command
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/audit"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
@@ -23,6 +26,9 @@ import (
 // retainDay number of days to keep audit logs for deletion
 var retainDays int
 
+// auditSort is the order to list the audit logs by
+var auditSort string
+
 func newAuditCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "audit [audit-id]",
@@ -30,7 +36,7 @@ func newAuditCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			switch len(args) {
 			case 0:
-				return audit.ShowAuditList(spec.AuditDir())
+				return audit.ShowAuditList(spec.AuditDir(), auditSort)
 			case 1:
 				return audit.ShowAuditLog(spec.AuditDir(), args[0])
 			default:
@@ -38,6 +44,7 @@ func newAuditCmd() *cobra.Command {
 			}
 		},
 	}
+	cmd.Flags().StringVar(&auditSort, "sort", audit.SortTimeAsc, fmt.Sprintf("The order to list the audit logs by, available values are [%s]", strings.Join(audit.SortOrders, ", ")))
 	cmd.AddCommand(newAuditCleanupCmd())
 	return cmd
 }
@@ -51,7 +58,7 @@ func newAuditCleanupCmd() *cobra.Command {
 				return errors.Errorf("retain-days cannot be less than 0")
 			}
 
-			err := audit.DeleteAuditLog(spec.AuditDir(), retainDays, skipConfirm, gOpt.DisplayMode)
+			err := audit.DeleteAuditLog(spec.AuditDir(), retainDays, skipConfirm, gOpt.DisplayMode, cm.Clock())
 			if err != nil {
 				return err
 			}
@@ -14,6 +14,7 @@
 package command
 
 import (
+	"github.com/pingcap/tiup/pkg/cluster/manager"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +22,7 @@ import (
 func newCleanCmd() *cobra.Command {
 	cleanOpt := operator.Options{}
 	cleanALl := false
+	olderThan := ""
 
 	cmd := &cobra.Command{
 		Use:   "clean <cluster-name>",
@@ -34,9 +36,17 @@ You can retain some nodes and roles data when cleanup the cluster, eg:
     $ tiup cluster clean <cluster-name> --log
     $ tiup cluster clean <cluster-name> --data
     $ tiup cluster clean <cluster-name> --audit-log
+    $ tiup cluster clean <cluster-name> --cleanup-crashes
     $ tiup cluster clean <cluster-name> --all --ignore-role prometheus
     $ tiup cluster clean <cluster-name> --all --ignore-node 172.16.13.11:9000
-    $ tiup cluster clean <cluster-name> --all --ignore-node 172.16.13.12`,
+    $ tiup cluster clean <cluster-name> --all --ignore-node 172.16.13.12
+    $ tiup cluster clean <cluster-name> --all --data-only-node 172.16.13.12:20160
+    $ tiup cluster clean <cluster-name> --all --dry-run
+    $ tiup cluster clean <cluster-name> --log --older-than 7d
+    $ tiup cluster clean <cluster-name> --log --age-report
+    $ tiup cluster clean <cluster-name> --data --exclude backup
+    $ tiup cluster clean <cluster-name> --path bin/old-version
+    $ tiup cluster clean <cluster-name> --data --confirm-token <cluster-name>`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return cmd.Help()
@@ -51,10 +61,25 @@ You can retain some nodes and roles data when cleanup the cluster, eg:
 				cleanOpt.CleanupLog = true
 			}
 
-			if !(cleanOpt.CleanupData || cleanOpt.CleanupLog || cleanOpt.CleanupAuditLog) {
+			if !(cleanOpt.CleanupData || cleanOpt.CleanupLog || cleanOpt.CleanupAuditLog || cleanOpt.CleanupCrashes || len(cleanOpt.CleanupPaths) > 0 ||
+				len(cleanOpt.DataOnlyRoles) > 0 || len(cleanOpt.DataOnlyNodes) > 0) {
 				return cmd.Help()
 			}
 
+			age, err := operator.ParseFileAge(olderThan)
+			if err != nil {
+				return err
+			}
+			cleanOpt.OlderThan = age
+			// the files to be deleted are printed with the global --dry-run
+			cleanOpt.DryRun = gOpt.DryRun
+			if err := operator.ValidateCleanupExclude(cleanOpt.ExcludeData); err != nil {
+				return err
+			}
+			if err := operator.ValidateCleanupPaths(cleanOpt.CleanupPaths); err != nil {
+				return err
+			}
+
 			return cm.CleanCluster(clusterName, gOpt, cleanOpt, skipConfirm)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -69,10 +94,22 @@ You can retain some nodes and roles data when cleanup the cluster, eg:
 
 	cmd.Flags().StringArrayVar(&cleanOpt.RetainDataNodes, "ignore-node", nil, "Specify the nodes or hosts whose data will be retained")
 	cmd.Flags().StringArrayVar(&cleanOpt.RetainDataRoles, "ignore-role", nil, "Specify the roles whose data will be retained")
+	cmd.Flags().StringArrayVar(&cleanOpt.DataOnlyNodes, "data-only-node", nil, "Specify the nodes or hosts to cleanup the data only, keeping everything in their deploy directories")
+	cmd.Flags().StringArrayVar(&cleanOpt.DataOnlyRoles, "data-only-role", nil, "Specify the roles to cleanup the data only, keeping everything in their deploy directories")
 	cmd.Flags().BoolVar(&cleanOpt.CleanupData, "data", false, "Cleanup data")
 	cmd.Flags().BoolVar(&cleanOpt.CleanupLog, "log", false, "Cleanup log")
 	cmd.Flags().BoolVar(&cleanOpt.CleanupAuditLog, "audit-log", false, "Cleanup TiDB-server audit log")
+	cmd.Flags().BoolVar(&cleanOpt.CleanupCrashes, "cleanup-crashes", false, "Cleanup core dumps (core, core.*, *.core) in deploy and data directories")
+	cmd.Flags().StringArrayVar(&cleanOpt.CleanupPaths, "path", nil, "Cleanup the path relative to the deploy directory of each instance, e.g. --path bin/old-version, could be specified multiple times")
 	cmd.Flags().BoolVar(&cleanALl, "all", false, "Cleanup both log and data (not include audit log)")
+	cmd.Flags().BoolVar(&cleanOpt.FollowSymlinks, "follow-symlinks", false, "Cleanup the content of data directories that are symlinks, the target may be shared with others")
+	cmd.Flags().BoolVar(&cleanOpt.EstimateSize, "estimate-size", false, "Estimate the space to be freed on each host with du before cleaning up")
+	cmd.Flags().BoolVar(&cleanOpt.AgeReport, "age-report", false, "Report the count, oldest and newest age of the files to be deleted on each host before cleaning up")
+	cmd.Flags().StringVar(&cleanOpt.PlanFile, "plan-file", "", "Write the files to be deleted to the path before any confirmation for review, as JSON if it ends with .json or as text otherwise")
+	cmd.Flags().StringArrayVar(&cleanOpt.ExcludeData, "exclude", nil, "Keep the entries with the name in data directories, e.g. --exclude backup, could be specified multiple times")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only cleanup the files modified longer than the age ago, e.g. 7d or 12h")
+	cmd.Flags().BoolVar(&gOpt.IgnoreProtection, manager.IgnoreProtectionFlag, false, "Proceed even if the cluster is protected")
+	cmd.Flags().StringVar(&gOpt.ConfirmToken, "confirm-token", "", "Confirm the clean without prompting by the name of the cluster, it's aborted if the name does not match")
 
 	return cmd
 }
@@ -15,7 +15,6 @@ package command
 
 import (
 	"context"
-	"os"
 	"path"
 
 	"github.com/pingcap/tiup/pkg/cluster/manager"
@@ -61,7 +60,7 @@ func newDeploy() *cobra.Command {
 			teleCommand = append(teleCommand, version)
 
 			topoFile := args[2]
-			if data, err := os.ReadFile(topoFile); err == nil {
+			if data, err := spec.ReadYamlFile(topoFile); err == nil {
 				teleTopology = string(data)
 			}
 
@@ -0,0 +1,69 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newDescribeCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "describe <cluster-name>",
+		Short: "Regenerate the topology file of a deployed cluster",
+		Long: `Regenerate the topology file of a deployed cluster from its stored topology,
+e.g. when the original file is lost. The defaulted fields like ports and
+directories are written out explicitly, deploying the output reproduces the
+same layout of the cluster.
+
+With --role or --node, only the instances matched by them are written, which
+are exactly the ones an operation scoped the same way acts upon.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			clusterReport.ID = scrubClusterName(clusterName)
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+
+			data, err := cm.Describe(clusterName, gOpt)
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			return utils.WriteFile(output, data, 0644)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the topology to the file instead of stdout")
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only describe the instances of specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only describe specified nodes")
+
+	return cmd
+}
@@ -15,6 +15,7 @@ package command
 
 import (
 	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/manager"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/set"
@@ -51,6 +52,10 @@ You can retain some nodes and roles data when destroy cluster, eg:
 				}
 			}
 
+			if err := operator.ValidateCleanupPaths(destroyOpt.CleanupPaths); err != nil {
+				return err
+			}
+
 			return cm.DestroyCluster(clusterName, gOpt, destroyOpt, skipConfirm)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -66,6 +71,10 @@ You can retain some nodes and roles data when destroy cluster, eg:
 	cmd.Flags().StringArrayVar(&destroyOpt.RetainDataNodes, "retain-node-data", nil, "Specify the nodes or hosts whose data will be retained")
 	cmd.Flags().StringArrayVar(&destroyOpt.RetainDataRoles, "retain-role-data", nil, "Specify the roles whose data will be retained")
 	cmd.Flags().BoolVar(&destroyOpt.Force, "force", false, "Force will ignore remote error while destroy the cluster")
+	cmd.Flags().BoolVar(&destroyOpt.CleanupTLS, "cleanup-tls", false, "Remove the TLS certificates and keys on the hosts even if TLS is enabled")
+	cmd.Flags().StringArrayVar(&destroyOpt.CleanupPaths, "path", nil, "Also remove the path relative to the deploy directory of each instance if the directory is kept, e.g. --path bin/old-version, could be specified multiple times")
+	cmd.Flags().BoolVar(&gOpt.IgnoreProtection, manager.IgnoreProtectionFlag, false, "Proceed even if the cluster is protected")
+	cmd.Flags().StringVar(&gOpt.ConfirmToken, "confirm-token", "", "Confirm the destroy without prompting by the name of the cluster, it's aborted if the name does not match")
 
 	return cmd
 }
@@ -98,7 +98,7 @@ func newDisplayCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&dopt.ShowProcess, "process", false, "display cpu and memory usage of nodes")
 	cmd.Flags().BoolVar(&dopt.ShowManageHost, "manage-host", false, "display manage host of nodes")
 	cmd.Flags().BoolVar(&dopt.ShowNuma, "numa", false, "display numa information of nodes")
-	cmd.Flags().BoolVar(&dopt.ShowVersions, "versions", false, "display component version of instances")
+	cmd.Flags().BoolVar(&dopt.ShowVersions, "versions", false, "display the intended component version of instances and the version they are running")
 	cmd.Flags().Uint64Var(&statusTimeout, "status-timeout", 10, "Timeout in seconds when getting node status")
 
 	return cmd
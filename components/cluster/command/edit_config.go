@@ -33,7 +33,7 @@ func newEditConfigCmd() *cobra.Command {
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
-			return cm.EditConfig(clusterName, opt, skipConfirm)
+			return cm.EditConfig(clusterName, opt, gOpt, skipConfirm)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
@@ -18,6 +18,7 @@ import (
 )
 
 func newEnableCmd() *cobra.Command {
+	var reconcile bool
 	cmd := &cobra.Command{
 		Use:   "enable <cluster-name>",
 		Short: "Enable a TiDB cluster automatically at boot",
@@ -34,6 +35,10 @@ func newEnableCmd() *cobra.Command {
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
+			if reconcile {
+				_, err := cm.ReconcileEnabled(clusterName, gOpt)
+				return err
+			}
 			return cm.EnableCluster(clusterName, gOpt, true)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -48,6 +53,9 @@ func newEnableCmd() *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only enable specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only enable specified nodes")
+	cmd.Flags().BoolVar(&reconcile, "reconcile", false, "Only enable or disable the instances whose enabled state drifts from the topology")
+	cmd.Flags().StringVar(&gOpt.UnitTemplate, "unit-template", "", "Re-render the systemd units with the template file before enabling, it accepts the same variables as the built-in one")
+	cmd.Flags().StringVar(&gOpt.BootDelay, "boot-delay", "", "Delay the start of each instance at boot by a duration spread in the range, e.g. 30s or 10s-2m, 0 removes the delay")
 
 	return cmd
 }
@@ -30,7 +30,8 @@ func newPruneCmd() *cobra.Command {
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
-			return cm.DestroyTombstone(clusterName, gOpt, skipConfirm)
+			_, err := cm.PruneCluster(clusterName, gOpt, skipConfirm)
+			return err
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
@@ -55,6 +55,9 @@ func newReloadCmd() *cobra.Command {
 	cmd.Flags().Uint64Var(&gOpt.APITimeout, "transfer-timeout", 600, "Timeout in seconds when transferring PD and TiKV store leaders, also for TiCDC drain one capture")
 	cmd.Flags().BoolVarP(&gOpt.IgnoreConfigCheck, "ignore-config-check", "", false, "Ignore the config check result")
 	cmd.Flags().BoolVar(&skipRestart, "skip-restart", false, "Only refresh configuration to remote and do not restart services")
+	cmd.Flags().BoolVar(&gOpt.IfChanged, "if-changed", false, "Only restart the instances whose config or start script changed")
+	cmd.Flags().BoolVar(&gOpt.AtomicReload, "atomic", false, "Push the config of each instance right before restarting it, instead of pushing all the configs first")
+	cmd.Flags().StringArrayVar(&gOpt.ConfigOverrides, "set", nil, "(EXPERIMENTAL) Set a one-off config item in the form of component.key=value without changing the topology, e.g. --set tikv.log.level=debug")
 	cmd.Flags().StringVar(&gOpt.SSHCustomScripts.BeforeRestartInstance.Raw, "pre-restart-script", "", "(EXPERIMENTAL) Custom script to be executed on each server before the service is restarted, does not take effect when --skip-restart is set to true")
 	cmd.Flags().StringVar(&gOpt.SSHCustomScripts.AfterRestartInstance.Raw, "post-restart-script", "", "(EXPERIMENTAL) Custom script to be executed on each server after the service is restarted, does not take effect when --skip-restart is set to true")
 
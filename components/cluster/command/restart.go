@@ -14,10 +14,20 @@
 package command
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/manager"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/spf13/cobra"
 )
 
 func newRestartCmd() *cobra.Command {
+	var (
+		pick       bool
+		batchRoles map[string]string
+	)
 	cmd := &cobra.Command{
 		Use:   "restart <cluster-name>",
 		Short: "Restart a TiDB cluster",
@@ -29,11 +39,29 @@ func newRestartCmd() *cobra.Command {
 			if err := validRoles(gOpt.Roles); err != nil {
 				return err
 			}
+			if err := validRoles(gOpt.ExcludeRoles); err != nil {
+				return err
+			}
+
+			if gOpt.RestartBatch < 0 {
+				return fmt.Errorf("invalid batch size %d, should not be negative", gOpt.RestartBatch)
+			}
+			sizes, err := operator.ParseRestartBatch(batchRoles, spec.AllComponentNames())
+			if err != nil {
+				return err
+			}
+			gOpt.RestartBatchRoles = sizes
 
 			clusterName := args[0]
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
+			if pick {
+				if err := cm.SelectNodes(clusterName, &gOpt); err != nil {
+					return err
+				}
+			}
+
 			return cm.RestartCluster(clusterName, gOpt, skipConfirm)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -48,6 +76,20 @@ func newRestartCmd() *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only restart specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only restart specified nodes")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeNodes, "exclude-node", nil, "Skip the specified nodes of the selected ones")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeRoles, "exclude-role", nil, "Skip the specified roles of the selected ones")
+	cmd.Flags().StringArrayVar(&gOpt.ConfigOverrides, "set", nil, "(EXPERIMENTAL) Set a one-off config item in the form of component.key=value without changing the topology, the configs of the restarted instances are refreshed, e.g. --set tikv.log.level=debug")
+	cmd.Flags().BoolVar(&pick, "select", false, "Pick the instances to restart interactively from a list of the ones matching the roles")
+	cmd.Flags().IntVar(&gOpt.RestartBatch, "batch", 0, "Restart the instances of each component in batches of the size, waiting for each batch to be healthy before the next one")
+	cmd.Flags().StringToStringVar(&batchRoles, "batch-role", nil, "Batch size of the specified components overriding --batch, e.g. --batch-role tikv=1")
+	cmd.Flags().StringToStringVar(&gOpt.Labels, "label", nil, "Only restart the instances with all the instance labels, e.g. --label az=a --label canary=true")
+	cmd.Flags().BoolVar(&gOpt.VerifyBinary, "verify-binary", false, "Verify the binaries of the instances against the checksums recorded when deployed before starting them")
+	cmd.Flags().BoolVar(&gOpt.SilenceAlerts, "silence-alerts", false, "Silence the alerts of the cluster in its Alertmanager during the operation")
+	cmd.Flags().DurationVar(&gOpt.SilenceDuration, "silence-duration", time.Hour, "How long the silence of --silence-alerts lasts at most")
+	cmd.Flags().BoolVar(&gOpt.OnlyFailed, "only-failed", false, "Only restart the instances not succeeded in the last restart of the cluster")
+	cmd.Flags().BoolVar(&gOpt.Force, "force", false, "Restart even outside the maintenance windows, errors of stopping instances are ignored as well")
+	cmd.Flags().BoolVar(&gOpt.SkipStopVerify, "skip-stop-verify", false, "Don't wait for the ports of the stopped instances to be closed")
+	cmd.Flags().BoolVar(&gOpt.IgnoreProtection, manager.IgnoreProtectionFlag, false, "Proceed even if the cluster is protected")
 
 	return cmd
 }
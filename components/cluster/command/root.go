@@ -26,8 +26,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/joomcode/errorx"
 	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/audit"
 	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/cluster/manager"
+	"github.com/pingcap/tiup/pkg/cluster/module"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	tiupmeta "github.com/pingcap/tiup/pkg/environment"
@@ -49,7 +51,9 @@ var (
 	rootCmd       *cobra.Command
 	gOpt          operator.Options
 	skipConfirm   bool
-	reportEnabled bool // is telemetry report enabled
+	logFormat     string // format of log lines
+	auditDir      string // directory of audit logs for this invocation
+	reportEnabled bool   // is telemetry report enabled
 	teleReport    *telemetry.Report
 	clusterReport *telemetry.ClusterReport
 	teleNodeInfos []*telemetry.NodeInfo
@@ -61,6 +65,17 @@ var (
 var tidbSpec *spec.SpecManager
 var cm *manager.Manager
 
+// startedAt is the time the command started at, told by the clock of cm
+var startedAt time.Time
+
+// elapsed returns the time since the command started
+func elapsed() time.Duration {
+	if cm == nil {
+		return 0
+	}
+	return cm.Clock().Now().Sub(startedAt)
+}
+
 func scrubClusterName(n string) string {
 	// prepend the telemetry secret to cluster name, so that two installations
 	// of tiup with the same cluster name produce different hashes
@@ -102,15 +117,31 @@ func init() {
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// populate logger
 			log.SetDisplayModeFromString(gOpt.DisplayMode)
+			logprinter.SetLogFormatFromString(logFormat)
+			if gOpt.StreamOutput && log.GetDisplayMode() != logprinter.DisplayModeJSON {
+				executor.SetOutputStream(os.Stdout)
+			}
+			if gOpt.Profile {
+				executor.EnableMetrics(true)
+			}
+			executor.SetKeepAlive(time.Duration(gOpt.SSHKeepAlive) * time.Second)
+			module.SetWaitForJitter(float64(gOpt.WaitJitter) / 100)
+			if cmd.Name() != "__complete" && log.GetDisplayMode() == logprinter.DisplayModeDefault {
+				// printed to stderr to keep the output of commands parsable
+				fmt.Fprintf(os.Stderr, "Correlation ID: %s\n", logprinter.CorrelationID())
+			}
 
 			var err error
 			var env *tiupmeta.Environment
 			if err = spec.Initialize("cluster"); err != nil {
 				return err
 			}
+			spec.SetAuditDir(auditDir)
 
 			tidbSpec = spec.GetSpecManager()
 			cm = manager.NewManager("tidb", tidbSpec, log)
+			startedAt = cm.Clock().Now()
+			cm.ResolveConcurrency(&gOpt, args)
 			if cmd.Name() != "__complete" {
 				logger.EnableAuditLog(spec.AuditDir())
 			}
@@ -159,14 +190,22 @@ func init() {
 	tui.BeautifyCobraUsageAndHelp(rootCmd)
 
 	rootCmd.PersistentFlags().Uint64Var(&gOpt.SSHTimeout, "ssh-timeout", 5, "Timeout in seconds to connect host via SSH, ignored for operations that don't need an SSH connection.")
+	rootCmd.PersistentFlags().Uint64Var(&gOpt.SSHKeepAlive, "ssh-keepalive", uint64(executor.DefaultKeepAlive.Seconds()), "Interval in seconds to send keepalive messages on idle SSH connections, 0 disables them.")
 	// the value of wait-timeout is also used for `systemctl` commands, as the default timeout of systemd for
 	// start/stop operations is 90s, the default value of this argument is better be longer than that
 	rootCmd.PersistentFlags().Uint64Var(&gOpt.OptTimeout, "wait-timeout", 120, "Timeout in seconds to wait for an operation to complete, ignored for operations that don't fit.")
+	rootCmd.PersistentFlags().Uint64Var(&gOpt.WaitJitter, "wait-jitter", 0, "Percentage to randomize the interval between polls of waiting for instances by, e.g. 20 for +/-20%, recommended for large clusters to spread out the polls.")
 	rootCmd.PersistentFlags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	rootCmd.PersistentFlags().BoolVar(&gOpt.DryRun, "dry-run", false, "Print what the operation would do without making any change, for the commands changing the cluster")
 	rootCmd.PersistentFlags().BoolVar(&gOpt.NativeSSH, "native-ssh", gOpt.NativeSSH, "(EXPERIMENTAL) Use the native SSH client installed on local system instead of the build-in one.")
-	rootCmd.PersistentFlags().StringVar((*string)(&gOpt.SSHType), "ssh", "", "(EXPERIMENTAL) The executor type: 'builtin', 'system', 'none'.")
-	rootCmd.PersistentFlags().IntVarP(&gOpt.Concurrency, "concurrency", "c", 5, "max number of parallel tasks allowed")
+	rootCmd.PersistentFlags().StringVar((*string)(&gOpt.SSHType), "ssh", "", "(EXPERIMENTAL) The executor type: 'builtin', 'system', 'none', 'auto' to use the local executor for the hosts that are the control node itself.")
+	rootCmd.PersistentFlags().VarP(operator.NewConcurrencyValue(&gOpt.Concurrency, operator.DefaultConcurrency), "concurrency", "c", "max number of parallel tasks allowed, or 'auto' to size it by the number of hosts")
+	rootCmd.PersistentFlags().IntVar(&gOpt.ConcurrencyCap, "concurrency-cap", operator.DefaultConcurrencyCap, "The upper bound of the concurrency sized by '--concurrency auto'")
 	rootCmd.PersistentFlags().StringVar(&gOpt.DisplayMode, "format", "default", "(EXPERIMENTAL) The format of output, available values are [default, json]")
+	rootCmd.PersistentFlags().StringVar(&auditDir, "audit-dir", "", "The directory to save and read audit logs, overrides the TIUP_AUDIT_DIR environment variable")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "The format of log lines, available values are [text, json], follows --format if not set")
+	rootCmd.PersistentFlags().BoolVar(&gOpt.StreamOutput, "stream-output", false, "(EXPERIMENTAL) Print the output of remote commands line by line, prefixed with the host, as they run.")
+	rootCmd.PersistentFlags().BoolVar(&gOpt.Profile, "profile", false, "Print the number and time of remote commands per host after the operation, sorted by the time spent.")
 	rootCmd.PersistentFlags().StringVar(&gOpt.SSHProxyHost, "ssh-proxy-host", "", "The SSH proxy host used to connect to remote host.")
 	rootCmd.PersistentFlags().StringVar(&gOpt.SSHProxyUser, "ssh-proxy-user", utils.CurrentUser(), "The user name used to login the proxy host.")
 	rootCmd.PersistentFlags().IntVar(&gOpt.SSHProxyPort, "ssh-proxy-port", 22, "The port used to login the proxy host.")
@@ -196,6 +235,7 @@ func init() {
 		newPruneCmd(),
 		newListCmd(),
 		newAuditCmd(),
+		newHistoryCmd(),
 		newImportCmd(),
 		newEditConfigCmd(),
 		newShowConfigCmd(),
@@ -214,6 +254,15 @@ func init() {
 		newTLSCmd(),
 		newMetaCmd(),
 		newRotateSSHCmd(),
+		newPingCmd(),
+		newDiffTopologyCmd(),
+		newValidateConfigCmd(),
+		newValidateTopologyCmd(),
+		newProtectCmd(),
+		newUnprotectCmd(),
+		newMaintenanceWindowCmd(),
+		newScaleOutLockCmd(),
+		newDescribeCmd(),
 	)
 }
 
@@ -292,7 +341,6 @@ func Execute() {
 		teleReport.Version = telemetry.TiUPMeta()
 	}
 
-	start := time.Now()
 	code := 0
 	err := rootCmd.Execute()
 	if err != nil {
@@ -301,6 +349,11 @@ func Execute() {
 
 	zap.L().Info("Execute command finished", zap.Int("code", code), zap.Error(err))
 
+	if gOpt.Profile && log.GetDisplayMode() != logprinter.DisplayModeJSON {
+		fmt.Println()
+		manager.PrintSSHProfile(executor.Metrics())
+	}
+
 	if reportEnabled {
 		f := func() {
 			defer func() {
@@ -334,7 +387,7 @@ func Execute() {
 					clusterReport.Topology = (string(data))
 				}
 			}
-			clusterReport.TakeMilliseconds = uint64(time.Since(start).Milliseconds())
+			clusterReport.TakeMilliseconds = uint64(elapsed().Milliseconds())
 			clusterReport.Command = strings.Join(teleCommand, " ")
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 			tele := telemetry.NewTelemetry()
@@ -389,6 +442,7 @@ func Execute() {
 			}
 		}
 	}
+	audit.SetResult(code, elapsed())
 	err = logger.OutputAuditLogIfEnabled()
 	if err != nil {
 		zap.L().Warn("Write audit log file failed", zap.Error(err))
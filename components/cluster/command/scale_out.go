@@ -14,7 +14,6 @@
 package command
 
 import (
-	"os"
 	"path/filepath"
 
 	"github.com/pingcap/tiup/pkg/cluster/manager"
@@ -61,7 +60,7 @@ func newScaleOutCmd() *cobra.Command {
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
 			// stage2: topoFile is ""
-			if data, err := os.ReadFile(topoFile); err == nil {
+			if data, err := spec.ReadYamlFile(topoFile); err == nil {
 				teleTopology = string(data)
 			}
 
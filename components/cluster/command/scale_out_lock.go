@@ -0,0 +1,87 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newScaleOutLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale-out-lock",
+		Short: "Inspect or release the scale-out lock of a cluster",
+		Long: `Inspect or release the scale-out lock of a cluster.
+The lock is created by scale-out --stage1 and released by scale-out --stage2,
+most operations are refused while it's held. If the scale-out was aborted,
+the lock can be released here instead of deleting the lock file by hand.`,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <cluster-name>",
+		Short: "Show who holds the scale-out lock and the instances pending to start",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			clusterReport.ID = scrubClusterName(clusterName)
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+
+			info, err := cm.ScaleOutLockInfo(clusterName)
+			if err != nil {
+				return err
+			}
+			cm.PrintScaleOutLock(clusterName, info)
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	releaseCmd := &cobra.Command{
+		Use:   "release <cluster-name>",
+		Short: "Force-release the scale-out lock left by an aborted scale-out",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			clusterReport.ID = scrubClusterName(clusterName)
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+
+			return cm.ReleaseScaleOutLock(clusterName, gOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.AddCommand(showCmd)
+	cmd.AddCommand(releaseCmd)
+
+	return cmd
+}
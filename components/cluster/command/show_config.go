@@ -14,15 +14,22 @@
 package command
 
 import (
+	perrs "github.com/pingcap/errors"
 	"github.com/spf13/cobra"
 )
 
 func newShowConfigCmd() *cobra.Command {
+	var diff bool
 	cmd := &cobra.Command{
-		Use:   "show-config <cluster-name>",
+		Use:   "show-config <cluster-name> [instance-id]",
 		Short: "Show TiDB cluster config",
+		Long: `Show the topology config of the cluster, or the config files deployed
+for an instance if its ID is given, e.g.:
+
+  $ tiup cluster show-config <cluster-name>
+  $ tiup cluster show-config <cluster-name> 172.16.5.1:4000 --diff`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 1 {
+			if len(args) != 1 && len(args) != 2 {
 				return cmd.Help()
 			}
 
@@ -30,7 +37,13 @@ func newShowConfigCmd() *cobra.Command {
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
-			return cm.ShowConfig(clusterName)
+			if len(args) == 1 {
+				if diff {
+					return perrs.New("--diff requires the ID of an instance")
+				}
+				return cm.ShowConfig(clusterName)
+			}
+			return cm.ShowInstanceConfig(clusterName, args[1], diff, gOpt)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
@@ -42,5 +55,7 @@ func newShowConfigCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&diff, "diff", false, "Diff the main config file of the instance against the config rendered from the topology")
+
 	return cmd
 }
@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/fatih/color"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/cluster/task"
 	"github.com/pingcap/tiup/pkg/crypto/rand"
@@ -34,6 +36,10 @@ func newStartCmd() *cobra.Command {
 	var (
 		initPasswd    bool
 		restoreLeader bool
+		delayStart    map[string]string
+		parallelRoles []string
+		minFreeSpace  string
+		pick          bool
 	)
 
 	cmd := &cobra.Command{
@@ -47,11 +53,40 @@ func newStartCmd() *cobra.Command {
 			if err := validRoles(gOpt.Roles); err != nil {
 				return err
 			}
+			if err := validRoles(gOpt.ExcludeRoles); err != nil {
+				return err
+			}
+
+			if _, err := operator.ParseFailureThreshold(gOpt.TolerateFailures, 0); err != nil {
+				return err
+			}
+
+			delays, err := operator.ParseDelayStart(delayStart, spec.AllComponentNames())
+			if err != nil {
+				return err
+			}
+			gOpt.DelayStart = delays
+
+			groups, err := operator.ParseParallelRoles(parallelRoles, spec.AllComponentNames())
+			if err != nil {
+				return err
+			}
+			gOpt.ParallelRoles = groups
+
+			if gOpt.MinFreeSpace, err = operator.ParseMinFreeSpace(minFreeSpace); err != nil {
+				return err
+			}
 
 			clusterName := args[0]
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
+			if pick {
+				if err := cm.SelectNodes(clusterName, &gOpt); err != nil {
+					return err
+				}
+			}
+
 			if err := cm.StartCluster(clusterName, gOpt, restoreLeader, func(b *task.Builder, metadata spec.Metadata) {
 				b.UpdateTopology(
 					clusterName,
@@ -95,6 +130,19 @@ func newStartCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&restoreLeader, "restore-leaders", false, "Allow leaders to be scheduled to stores after start")
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only start specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only start specified nodes")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeNodes, "exclude-node", nil, "Skip the specified nodes of the selected ones")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeRoles, "exclude-role", nil, "Skip the specified roles of the selected ones")
+	cmd.Flags().BoolVar(&pick, "select", false, "Pick the instances to start interactively from a list of the ones matching the roles")
+	cmd.Flags().StringToStringVar(&gOpt.Labels, "label", nil, "Only start the instances with all the instance labels, e.g. --label az=a --label canary=true")
+	cmd.Flags().BoolVar(&gOpt.VerifyBinary, "verify-binary", false, "Verify the binaries of the instances against the checksums recorded when deployed before starting them")
+	cmd.Flags().BoolVar(&gOpt.OnlyFailed, "only-failed", false, "Only start the instances not succeeded in the last start of the cluster")
+	cmd.Flags().BoolVar(&gOpt.WaitHealthy, "wait-healthy", false, "Wait until all the started instances report healthy by their status APIs, in the wait timeout")
+	cmd.Flags().Uint64Var(&gOpt.OperationTimeout, "operation-timeout", 0, "Timeout in seconds of the whole start operation, 0 means no limit")
+	cmd.Flags().StringArrayVar(&parallelRoles, "parallel-roles", nil, "Comma separated roles that could be started concurrently, could be specified multiple times, e.g. --parallel-roles prometheus,grafana,alertmanager")
+	cmd.Flags().StringToStringVar(&delayStart, "delay-start", nil, "Warmup delay after all instances of a component are ready before starting the next components, e.g. --delay-start tikv=30s")
+	cmd.Flags().StringVar(&minFreeSpace, "min-free-space", units.BytesSize(operator.DefaultMinFreeSpace), "Warn about the data dirs with less free space than it before starting, e.g. 10GiB, 0 disables the check")
+	cmd.Flags().BoolVar(&gOpt.AbortOnLowSpace, "abort-on-low-space", false, "Abort the start instead of warning if any data dir has less free space than --min-free-space")
+	cmd.Flags().StringVar(&gOpt.TolerateFailures, "tolerate-failures", "", "Number (N) or percentage (N%) of instances allowed to fail, start continues until the failures exceed it")
 
 	_ = cmd.Flags().MarkHidden("restore-leaders")
 
@@ -14,12 +14,16 @@
 package command
 
 import (
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/manager"
 	"github.com/spf13/cobra"
 )
 
 func newStopCmd() *cobra.Command {
 	var evictLeader bool
 
+	var pick bool
 	cmd := &cobra.Command{
 		Use:   "stop <cluster-name>",
 		Short: "Stop a TiDB cluster",
@@ -31,11 +35,20 @@ func newStopCmd() *cobra.Command {
 			if err := validRoles(gOpt.Roles); err != nil {
 				return err
 			}
+			if err := validRoles(gOpt.ExcludeRoles); err != nil {
+				return err
+			}
 
 			clusterName := args[0]
 			clusterReport.ID = scrubClusterName(clusterName)
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 
+			if pick {
+				if err := cm.SelectNodes(clusterName, &gOpt); err != nil {
+					return err
+				}
+			}
+
 			return cm.StopCluster(clusterName, gOpt, skipConfirm, evictLeader)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -50,7 +63,18 @@ func newStopCmd() *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only stop specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only stop specified nodes")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeNodes, "exclude-node", nil, "Skip the specified nodes of the selected ones")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeRoles, "exclude-role", nil, "Skip the specified roles of the selected ones")
+	cmd.Flags().BoolVar(&pick, "select", false, "Pick the instances to stop interactively from a list of the ones matching the roles")
+	cmd.Flags().StringToStringVar(&gOpt.Labels, "label", nil, "Only stop the instances with all the instance labels, e.g. --label az=a --label canary=true")
+	cmd.Flags().BoolVar(&gOpt.SilenceAlerts, "silence-alerts", false, "Silence the alerts of the cluster in its Alertmanager during the operation")
+	cmd.Flags().DurationVar(&gOpt.SilenceDuration, "silence-duration", time.Hour, "How long the silence of --silence-alerts lasts at most")
+	cmd.Flags().BoolVar(&gOpt.OnlyFailed, "only-failed", false, "Only stop the instances not succeeded in the last stop of the cluster")
+	cmd.Flags().StringSliceVar(&gOpt.StopOrder, "stop-order", nil, "Comma separated roles in the order to stop them, roles not listed are stopped in the default order after them")
+	cmd.Flags().BoolVar(&gOpt.Force, "force", false, "Stop even if no PD would be left running or outside the maintenance windows, errors of stopping instances are ignored as well")
 	cmd.Flags().BoolVar(&evictLeader, "evict-leaders", false, "Evict leaders on stores before stop")
+	cmd.Flags().BoolVar(&gOpt.SkipStopVerify, "skip-stop-verify", false, "Don't wait for the ports of the stopped instances to be closed")
+	cmd.Flags().BoolVar(&gOpt.IgnoreProtection, manager.IgnoreProtectionFlag, false, "Proceed even if the cluster is protected")
 
 	_ = cmd.Flags().MarkHidden("evict-leaders")
 
@@ -0,0 +1,57 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/manager"
+	"github.com/spf13/cobra"
+)
+
+func newValidateConfigCmd() *cobra.Command {
+	var version string
+	cmd := &cobra.Command{
+		Use:   "validate-config <topology.yaml>",
+		Short: "Validate the config files rendered from a topology without deploying",
+		Long: `Validate the config files of all instances rendered from the topology file
+locally, without connecting to any host. The TOML syntax is always checked, and
+if --version is specified and the component binaries of that version are installed
+locally, their own config check is run as well, e.g.:
+
+  $ tiup install tidb:v7.1.0 tikv:v7.1.0 pd:v7.1.0
+  $ tiup cluster validate-config topology.yaml --version v7.1.0`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			result, err := cm.TestConfig(args[0], version)
+			if err != nil {
+				return err
+			}
+			manager.PrintConfigCheckResult(result)
+
+			for _, r := range result {
+				if !r.Passed {
+					return perrs.New("some of the configs are invalid")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "Run the config check of the component binaries in the version if installed locally")
+
+	return cmd
+}
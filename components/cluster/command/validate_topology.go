@@ -0,0 +1,59 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"path"
+
+	"github.com/pingcap/tiup/pkg/cluster/manager"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newValidateTopologyCmd() *cobra.Command {
+	var withAuth bool
+	opt := manager.DeployOptions{
+		IdentityFile: path.Join(utils.UserHome(), ".ssh", "id_rsa"),
+	}
+	cmd := &cobra.Command{
+		Use:   "validate-topology <topology.yaml>",
+		Short: "Check that the hosts in a topology resolve and their SSH ports are reachable",
+		Long: `Resolve each host in the topology file and connect to its SSH port in
+parallel, nothing is changed on the hosts. If --auth is set, an SSH login is
+tried as well with the given user and credentials. The hosts behind
+--ssh-proxy-host are only checked by logging in via the proxy, so --auth is
+required with it, e.g.:
+
+  $ tiup cluster validate-topology topology.yaml
+  $ tiup cluster validate-topology topology.yaml --auth -u tidb -i ~/.ssh/id_rsa`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			var auth *manager.DeployOptions
+			if withAuth {
+				auth = &opt
+			}
+			return cm.ValidateTopology(args[0], gOpt, auth)
+		},
+	}
+
+	cmd.Flags().BoolVar(&withAuth, "auth", false, "Try to login to the hosts via SSH as well")
+	cmd.Flags().StringVarP(&opt.User, "user", "u", utils.CurrentUser(), "The user name to login via SSH.")
+	cmd.Flags().StringVarP(&opt.IdentityFile, "identity_file", "i", opt.IdentityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().BoolVarP(&opt.UsePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+
+	return cmd
+}
@@ -14,6 +14,9 @@
 package command
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/audit"
 	cspec "github.com/pingcap/tiup/pkg/cluster/spec"
@@ -22,6 +25,9 @@ import (
 
 var retainDays int
 
+// auditSort is the order to list the audit logs by
+var auditSort string
+
 func newAuditCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "audit [audit-id]",
@@ -29,7 +35,7 @@ func newAuditCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			switch len(args) {
 			case 0:
-				return audit.ShowAuditList(cspec.AuditDir())
+				return audit.ShowAuditList(cspec.AuditDir(), auditSort)
 			case 1:
 				return audit.ShowAuditLog(cspec.AuditDir(), args[0])
 			default:
@@ -37,6 +43,7 @@ func newAuditCmd() *cobra.Command {
 			}
 		},
 	}
+	cmd.Flags().StringVar(&auditSort, "sort", audit.SortTimeAsc, fmt.Sprintf("The order to list the audit logs by, available values are [%s]", strings.Join(audit.SortOrders, ", ")))
 	cmd.AddCommand(newAuditCleanupCmd())
 	return cmd
 }
@@ -50,7 +57,7 @@ func newAuditCleanupCmd() *cobra.Command {
 				return errors.Errorf("retain-days cannot be less than 0")
 			}
 
-			err := audit.DeleteAuditLog(cspec.AuditDir(), retainDays, skipConfirm, gOpt.DisplayMode)
+			err := audit.DeleteAuditLog(cspec.AuditDir(), retainDays, skipConfirm, gOpt.DisplayMode, cm.Clock())
 			if err != nil {
 				return err
 			}
@@ -31,7 +31,7 @@ func newEditConfigCmd() *cobra.Command {
 
 			clusterName := args[0]
 
-			return cm.EditConfig(clusterName, opt, skipConfirm)
+			return cm.EditConfig(clusterName, opt, gOpt, skipConfirm)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
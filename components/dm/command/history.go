@@ -0,0 +1,70 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/pingcap/tiup/pkg/cluster/audit"
+	cspec "github.com/pingcap/tiup/pkg/cluster/spec"
+	tiupmeta "github.com/pingcap/tiup/pkg/environment"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <cluster-name>",
+		Short: "Show the operations on a cluster from TiUP history and audit logs",
+		Long: `Show the operations on a cluster in chronological order, the TiUP history
+records are merged with the audit logs of the same commands, so the exit code
+and the audit ID are shown together.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+
+			rows, err := tiupmeta.GlobalEnv().GetComponentHistory("dm", clusterName)
+			if err != nil {
+				return err
+			}
+			history := make([]audit.HistoryEntry, 0, len(rows))
+			for _, r := range rows {
+				componentArgs, _ := r.ComponentArgs("dm")
+				history = append(history, audit.HistoryEntry{
+					Time:    r.Date,
+					Command: r.Command,
+					Args:    componentArgs,
+					Code:    r.Code,
+				})
+			}
+
+			items, err := audit.GetClusterAuditList(cspec.AuditDir(), clusterName)
+			if err != nil {
+				return err
+			}
+
+			return audit.ShowTimeline(audit.MergeTimeline(history, items), gOpt.DisplayMode)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	return cmd
+}
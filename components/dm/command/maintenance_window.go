@@ -0,0 +1,49 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newMaintenanceWindowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance-window <cluster-name> [<window>...]",
+		Short: "Set the maintenance windows out of which the cluster can't be stopped or restarted",
+		Long: `Set the weekly maintenance windows of the cluster in the local time zone.
+Stopping or restarting the cluster outside all of them is refused unless
+the --force flag is given. Each window is in the format of
+[DAYS] HH:MM-HH:MM, DAYS are comma separated weekdays or ranges of them
+and default to every day, e.g. "Sat,Sun 02:00-06:00" or "Mon-Fri 22:00-01:00".
+Run without windows to remove the restriction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			return cm.SetMaintenanceWindows(clusterName, args[1:], gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	return cmd
+}
@@ -50,6 +50,8 @@ func newReloadCmd() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only reload specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only reload specified nodes")
 	cmd.Flags().BoolVar(&skipRestart, "skip-restart", false, "Only refresh configuration to remote and do not restart services")
+	cmd.Flags().BoolVar(&gOpt.IfChanged, "if-changed", false, "Only restart the instances whose config or start script changed")
+	cmd.Flags().BoolVar(&gOpt.AtomicReload, "atomic", false, "Push the config of each instance right before restarting it, instead of pushing all the configs first")
 
 	return cmd
 }
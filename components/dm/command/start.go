@@ -14,10 +14,19 @@
 package command
 
 import (
+	"github.com/docker/go-units"
+	"github.com/pingcap/tiup/components/dm/spec"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/spf13/cobra"
 )
 
 func newStartCmd() *cobra.Command {
+	var (
+		delayStart    map[string]string
+		parallelRoles []string
+		minFreeSpace  string
+		pick          bool
+	)
 	cmd := &cobra.Command{
 		Use:   "start <cluster-name>",
 		Short: "Start a DM cluster",
@@ -28,6 +37,28 @@ func newStartCmd() *cobra.Command {
 
 			clusterName := args[0]
 
+			delays, err := operator.ParseDelayStart(delayStart, spec.AllDMComponentNames())
+			if err != nil {
+				return err
+			}
+			gOpt.DelayStart = delays
+
+			groups, err := operator.ParseParallelRoles(parallelRoles, spec.AllDMComponentNames())
+			if err != nil {
+				return err
+			}
+			gOpt.ParallelRoles = groups
+
+			if gOpt.MinFreeSpace, err = operator.ParseMinFreeSpace(minFreeSpace); err != nil {
+				return err
+			}
+
+			if pick {
+				if err := cm.SelectNodes(clusterName, &gOpt); err != nil {
+					return err
+				}
+			}
+
 			return cm.StartCluster(clusterName, gOpt, false)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -42,6 +73,17 @@ func newStartCmd() *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only start specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only start specified nodes")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeNodes, "exclude-node", nil, "Skip the specified nodes of the selected ones")
+	cmd.Flags().StringSliceVar(&gOpt.ExcludeRoles, "exclude-role", nil, "Skip the specified roles of the selected ones")
+	cmd.Flags().BoolVar(&pick, "select", false, "Pick the instances to start interactively from a list of the ones matching the roles")
+	cmd.Flags().StringToStringVar(&gOpt.Labels, "label", nil, "Only start the instances with all the instance labels, e.g. --label az=a --label canary=true")
+	cmd.Flags().BoolVar(&gOpt.VerifyBinary, "verify-binary", false, "Verify the binaries of the instances against the checksums recorded when deployed before starting them")
+	cmd.Flags().BoolVar(&gOpt.OnlyFailed, "only-failed", false, "Only start the instances not succeeded in the last start of the cluster")
+	cmd.Flags().StringArrayVar(&parallelRoles, "parallel-roles", nil, "Comma separated roles that could be started concurrently, could be specified multiple times, e.g. --parallel-roles prometheus,grafana,alertmanager")
+	cmd.Flags().StringToStringVar(&delayStart, "delay-start", nil, "Warmup delay after all instances of a component are ready before starting the next components, e.g. --delay-start dm-master=10s")
+	cmd.Flags().StringVar(&minFreeSpace, "min-free-space", units.BytesSize(operator.DefaultMinFreeSpace), "Warn about the data dirs with less free space than it before starting, e.g. 10GiB, 0 disables the check")
+	cmd.Flags().BoolVar(&gOpt.AbortOnLowSpace, "abort-on-low-space", false, "Abort the start instead of warning if any data dir has less free space than --min-free-space")
+	cmd.Flags().Uint64Var(&gOpt.OperationTimeout, "operation-timeout", 0, "Timeout in seconds of the whole start operation, 0 means no limit")
 
 	return cmd
 }
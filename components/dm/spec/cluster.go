@@ -28,6 +28,14 @@ type Metadata struct {
 	User    string `yaml:"user"`       // the user to run and manage cluster on remote
 	Version string `yaml:"dm_version"` // the version of TiDB cluster
 	// EnableFirewall bool   `yaml:"firewall"`
+	// the banner displayed before destructive operations, e.g. "PRODUCTION - change ticket required"
+	EnvironmentBanner string `yaml:"environment_banner,omitempty"`
+	// stop, restart and destroy are refused on a protected cluster
+	Protected bool `yaml:"protected,omitempty"`
+	// stop and restart are refused outside the maintenance windows if any
+	MaintenanceWindows []string `yaml:"maintenance_windows,omitempty"`
+	// checksums of the bin dirs of the instances by ID, verified before starting if asked
+	BinaryChecksums map[string]string `yaml:"binary_checksums,omitempty"`
 
 	Topology *Specification `yaml:"topology"`
 }
@@ -62,8 +70,13 @@ func (m *Metadata) SetTopology(topo cspec.Topology) {
 // GetBaseMeta implements Metadata interface.
 func (m *Metadata) GetBaseMeta() *cspec.BaseMeta {
 	return &cspec.BaseMeta{
-		Version: m.Version,
-		User:    m.User,
+		Version:   m.Version,
+		User:      m.User,
+		Banner:    m.EnvironmentBanner,
+		Protected: &m.Protected,
+
+		MaintenanceWindows: &m.MaintenanceWindows,
+		BinaryChecksums:    &m.BinaryChecksums,
 	}
 }
 
@@ -136,19 +136,21 @@ type MasterSpec struct {
 	Patched        bool   `yaml:"patched,omitempty"`
 	IgnoreExporter bool   `yaml:"ignore_exporter,omitempty"`
 	// Use Name to get the name with a default value if it's empty.
-	Name            string          `yaml:"name,omitempty"`
-	Port            int             `yaml:"port,omitempty" default:"8261"`
-	PeerPort        int             `yaml:"peer_port,omitempty" default:"8291"`
-	DeployDir       string          `yaml:"deploy_dir,omitempty"`
-	DataDir         string          `yaml:"data_dir,omitempty"`
-	LogDir          string          `yaml:"log_dir,omitempty"`
-	Source          string          `yaml:"source,omitempty" validate:"source:editable"`
-	NumaNode        string          `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
-	Config          map[string]any  `yaml:"config,omitempty" validate:"config:ignore"`
-	ResourceControl ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
-	Arch            string          `yaml:"arch,omitempty"`
-	OS              string          `yaml:"os,omitempty"`
-	V1SourcePath    string          `yaml:"v1_source_path,omitempty"`
+	Name            string            `yaml:"name,omitempty"`
+	Port            int               `yaml:"port,omitempty" default:"8261"`
+	PeerPort        int               `yaml:"peer_port,omitempty" default:"8291"`
+	DeployDir       string            `yaml:"deploy_dir,omitempty"`
+	DataDir         string            `yaml:"data_dir,omitempty"`
+	LogDir          string            `yaml:"log_dir,omitempty"`
+	Source          string            `yaml:"source,omitempty" validate:"source:editable"`
+	NumaNode        string            `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
+	Config          map[string]any    `yaml:"config,omitempty" validate:"config:ignore"`
+	ResourceControl ResourceControl   `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool              `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
+	Arch            string            `yaml:"arch,omitempty"`
+	OS              string            `yaml:"os,omitempty"`
+	V1SourcePath    string            `yaml:"v1_source_path,omitempty"`
 }
 
 // Status queries current status of the instance
@@ -220,17 +222,19 @@ type WorkerSpec struct {
 	Patched        bool   `yaml:"patched,omitempty"`
 	IgnoreExporter bool   `yaml:"ignore_exporter,omitempty"`
 	// Use Name to get the name with a default value if it's empty.
-	Name            string          `yaml:"name,omitempty"`
-	Port            int             `yaml:"port,omitempty" default:"8262"`
-	DeployDir       string          `yaml:"deploy_dir,omitempty"`
-	DataDir         string          `yaml:"data_dir,omitempty"`
-	LogDir          string          `yaml:"log_dir,omitempty"`
-	Source          string          `yaml:"source,omitempty" validate:"source:editable"`
-	NumaNode        string          `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
-	Config          map[string]any  `yaml:"config,omitempty" validate:"config:ignore"`
-	ResourceControl ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
-	Arch            string          `yaml:"arch,omitempty"`
-	OS              string          `yaml:"os,omitempty"`
+	Name            string            `yaml:"name,omitempty"`
+	Port            int               `yaml:"port,omitempty" default:"8262"`
+	DeployDir       string            `yaml:"deploy_dir,omitempty"`
+	DataDir         string            `yaml:"data_dir,omitempty"`
+	LogDir          string            `yaml:"log_dir,omitempty"`
+	Source          string            `yaml:"source,omitempty" validate:"source:editable"`
+	NumaNode        string            `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
+	Config          map[string]any    `yaml:"config,omitempty" validate:"config:ignore"`
+	ResourceControl ResourceControl   `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool              `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
+	Arch            string            `yaml:"arch,omitempty"`
+	OS              string            `yaml:"os,omitempty"`
 }
 
 // Status queries current status of the instance
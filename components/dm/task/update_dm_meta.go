@@ -92,6 +92,11 @@ func (u *UpdateDMMeta) Execute(ctx context.Context) error {
 	}
 	topo.Alertmanagers = alertmanagers
 
+	// the binary checksums of the removed instances are dropped as well
+	for id := range deleted {
+		delete(u.metadata.BinaryChecksums, id)
+	}
+
 	return dmspec.GetSpecManager().SaveMeta(u.cluster, u.metadata)
 }
 
@@ -15,28 +15,118 @@ package audit
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/base52"
 	"github.com/pingcap/tiup/pkg/crypto/rand"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
 	"github.com/pingcap/tiup/pkg/tui"
 	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/pingcap/tiup/pkg/version"
+	"go.uber.org/zap"
 )
 
 const (
 	// EnvNameAuditID is the alternative ID appended to time based audit ID
 	EnvNameAuditID = "TIUP_AUDIT_ID"
+
+	// versionLinePrefix is the prefix of the line recording TiUP version in audit log
+	versionLinePrefix = "# tiup-version: "
+	// correlationLinePrefix is the prefix of the line recording the correlation ID in audit log
+	correlationLinePrefix = "# correlation-id: "
+	// topologyLinePrefix is the prefix of the line recording the hash of the topology in audit log
+	topologyLinePrefix = "# topology-hash: "
+	// exitCodeLinePrefix is the prefix of the line recording the exit code of the operation in audit log
+	exitCodeLinePrefix = "# exit-code: "
+	// durationLinePrefix is the prefix of the line recording how long the operation took in audit log
+	durationLinePrefix = "# duration: "
+)
+
+var (
+	topologyMu   sync.Mutex
+	topologyHash string
+)
+
+// SetTopologyHash records the hash of the topology the operation acts upon into
+// the audit log, only the first one is kept as it's the topology before the
+// operation changes it
+func SetTopologyHash(hash string) {
+	topologyMu.Lock()
+	defer topologyMu.Unlock()
+	if topologyHash == "" {
+		topologyHash = hash
+	}
+}
+
+func getTopologyHash() string {
+	topologyMu.Lock()
+	defer topologyMu.Unlock()
+	return topologyHash
+}
+
+var (
+	resultMu       sync.Mutex
+	resultCode     *int
+	resultDuration time.Duration
 )
 
+// SetResult records the exit code and the duration of the operation into the
+// audit log, it should be called right before the audit log is written
+func SetResult(code int, duration time.Duration) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	resultCode = &code
+	resultDuration = duration
+}
+
+func getResult() (*int, time.Duration) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	return resultCode, resultDuration
+}
+
+// readAuditResult reads the exit code and duration from the header lines of
+// an audit log, they are unknown for logs written by older versions
+func readAuditResult(fp string) (*int, time.Duration) {
+	file, err := os.Open(fp)
+	if err != nil {
+		return nil, 0
+	}
+	defer file.Close()
+
+	var code *int
+	var duration time.Duration
+	scanner := bufio.NewScanner(file)
+	for i := 0; scanner.Scan(); i++ {
+		line := scanner.Text()
+		// the first line is the command
+		if i > 0 && !strings.HasPrefix(line, "# ") {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, exitCodeLinePrefix); ok {
+			if c, err := strconv.Atoi(v); err == nil {
+				code = &c
+			}
+		}
+		if v, ok := strings.CutPrefix(line, durationLinePrefix); ok {
+			duration, _ = time.ParseDuration(v)
+		}
+	}
+	return code, duration
+}
+
 // CommandArgs returns the original commands from the first line of a file
 func CommandArgs(fp string) ([]string, error) {
 	file, err := os.Open(fp)
@@ -80,21 +170,33 @@ func decodeCommandArgs(args []string) ([]string, error) {
 	return decoded, nil
 }
 
-// ShowAuditList show the audit list.
-func ShowAuditList(dir string) error {
+// ShowAuditList show the audit list, sorted by the order sortBy.
+func ShowAuditList(dir, sortBy string) error {
 	// Header
-	clusterTable := [][]string{{"ID", "Time", "Command"}}
+	clusterTable := [][]string{{"ID", "Time", "Command", "Code", "Duration"}}
 
 	auditList, err := GetAuditList(dir)
 	if err != nil {
 		return err
 	}
+	if err := SortAuditList(auditList, sortBy); err != nil {
+		return err
+	}
 
 	for _, item := range auditList {
+		code, duration := "-", "-"
+		if item.ExitCode != nil {
+			code = strconv.Itoa(*item.ExitCode)
+		}
+		if item.Duration > 0 {
+			duration = item.Duration.String()
+		}
 		clusterTable = append(clusterTable, []string{
 			item.ID,
 			item.Time,
 			item.Command,
+			code,
+			duration,
 		})
 	}
 
@@ -107,6 +209,62 @@ type Item struct {
 	ID      string `json:"id"`
 	Time    string `json:"time"`
 	Command string `json:"command"`
+	// ExitCode and Duration are unknown for logs written by older versions
+	ExitCode *int          `json:"exit_code,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// The orders the audit list can be sorted by
+const (
+	SortTimeAsc  = "time-asc"
+	SortTimeDesc = "time-desc"
+	SortStatus   = "status"
+	SortDuration = "duration"
+)
+
+// SortOrders are the available values of the sort order
+var SortOrders = []string{SortTimeAsc, SortTimeDesc, SortStatus, SortDuration}
+
+// SortAuditList sorts the audit items in place, sorting by status puts the
+// failed operations first and sorting by duration puts the slowest first,
+// items with the same key or the key unknown are kept ordered by time
+func SortAuditList(items []Item, by string) error {
+	byTime := func(i, j int) bool {
+		return items[i].Time < items[j].Time
+	}
+	switch by {
+	case "", SortTimeAsc:
+		sort.SliceStable(items, byTime)
+	case SortTimeDesc:
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Time > items[j].Time
+		})
+	case SortStatus:
+		sort.SliceStable(items, byTime)
+		sort.SliceStable(items, func(i, j int) bool {
+			return statusRank(items[i].ExitCode) < statusRank(items[j].ExitCode)
+		})
+	case SortDuration:
+		sort.SliceStable(items, byTime)
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Duration > items[j].Duration
+		})
+	default:
+		return errors.Errorf("unsupported sort order %s, available values are [%s]", by, strings.Join(SortOrders, ", "))
+	}
+	return nil
+}
+
+// statusRank ranks failed operations before the succeeded ones and the unknown ones
+func statusRank(code *int) int {
+	switch {
+	case code == nil:
+		return 2
+	case *code != 0:
+		return 0
+	default:
+		return 1
+	}
 }
 
 // GetAuditList get the audit item list
@@ -130,10 +288,13 @@ func GetAuditList(dir string) ([]Item, error) {
 			continue
 		}
 		cmd := strings.Join(args, " ")
+		code, duration := readAuditResult(filepath.Join(dir, fi.Name()))
 		auditList = append(auditList, Item{
-			ID:      fi.Name(),
-			Time:    t.Format(time.RFC3339),
-			Command: cmd,
+			ID:       fi.Name(),
+			Time:     t.Format(time.RFC3339),
+			Command:  cmd,
+			ExitCode: code,
+			Duration: duration,
 		})
 	}
 
@@ -144,9 +305,11 @@ func GetAuditList(dir string) ([]Item, error) {
 	return auditList, nil
 }
 
-// OutputAuditLog outputs audit log.
+// OutputAuditLog outputs audit log, and delivers it to the registered sink
+// after it's written
 func OutputAuditLog(dir, fileSuffix string, data []byte) error {
-	auditID := base52.Encode(time.Now().UnixNano() + rand.Int63n(1000))
+	now := time.Now()
+	auditID := base52.Encode(now.UnixNano() + rand.Int63n(1000))
 	if customID := os.Getenv(EnvNameAuditID); customID != "" {
 		auditID = fmt.Sprintf("%s_%s", auditID, customID)
 	}
@@ -161,13 +324,48 @@ func OutputAuditLog(dir, fileSuffix string, data []byte) error {
 	}
 	defer f.Close()
 
-	args := encodeCommandArgs(os.Args)
+	event := Event{
+		ID:            auditID,
+		Time:          now,
+		Command:       os.Args,
+		Version:       fmt.Sprintf("%s (%s)", version.NewTiUPVersion().SemVer(), version.GitHash),
+		CorrelationID: logprinter.CorrelationID(),
+		TopologyHash:  getTopologyHash(),
+		Log:           data,
+	}
+	event.ExitCode, event.Duration = getResult()
+
+	args := encodeCommandArgs(event.Command)
 	if _, err := f.Write([]byte(strings.Join(args, " ") + "\n")); err != nil {
 		return errors.Annotate(err, "write audit log")
 	}
+	if _, err := f.Write([]byte(versionLinePrefix + event.Version + "\n")); err != nil {
+		return errors.Annotate(err, "write audit log")
+	}
+	if id := event.CorrelationID; id != "" {
+		if _, err := f.Write([]byte(correlationLinePrefix + id + "\n")); err != nil {
+			return errors.Annotate(err, "write audit log")
+		}
+	}
+	if hash := event.TopologyHash; hash != "" {
+		if _, err := f.Write([]byte(topologyLinePrefix + hash + "\n")); err != nil {
+			return errors.Annotate(err, "write audit log")
+		}
+	}
+	if code, duration := event.ExitCode, event.Duration; code != nil {
+		result := fmt.Sprintf("%s%d\n%s%s\n", exitCodeLinePrefix, *code, durationLinePrefix, duration.Round(time.Millisecond))
+		if _, err := f.Write([]byte(result)); err != nil {
+			return errors.Annotate(err, "write audit log")
+		}
+	}
 	if _, err := f.Write(data); err != nil {
 		return errors.Annotate(err, "write audit log")
 	}
+
+	// the audit log is already persisted, failures of the sink don't fail the command
+	if err := deliverEvent(event); err != nil {
+		zap.L().Warn("Deliver audit event failed", zap.String("id", auditID), zap.Error(err))
+	}
 	return nil
 }
 
@@ -188,13 +386,89 @@ func ShowAuditLog(dir string, auditID string) error {
 		return errors.Trace(err)
 	}
 
+	ver, content := splitAuditVersion(content)
+	id, content := splitAuditCorrelationID(content)
+	hash, content := splitAuditTopologyHash(content)
+	content = splitAuditResult(content)
 	hint := fmt.Sprintf("- OPERATION TIME: %s -", t.Format("2006-01-02T15:04:05"))
-	line := strings.Repeat("-", len(hint))
-	_, _ = os.Stdout.WriteString(color.MagentaString("%s\n%s\n%s\n", line, hint, line))
+	verHint := fmt.Sprintf("- TIUP VERSION: %s -", ver)
+	hints := []string{hint, verHint}
+	if id != "" {
+		hints = append(hints, fmt.Sprintf("- CORRELATION ID: %s -", id))
+	}
+	hints = append(hints, fmt.Sprintf("- TOPOLOGY HASH: %s -", hash))
+	width := 0
+	for _, h := range hints {
+		width = max(width, len(h))
+	}
+	line := strings.Repeat("-", width)
+	_, _ = os.Stdout.WriteString(color.MagentaString("%s\n%s\n%s\n", line, strings.Join(hints, "\n"), line))
 	_, _ = os.Stdout.Write(content)
 	return nil
 }
 
+// splitAuditVersion extracts the TiUP version from the second line of audit
+// log content, "unknown" is returned for logs written by older versions
+func splitAuditVersion(content []byte) (string, []byte) {
+	lines := bytes.SplitN(content, []byte("\n"), 3)
+	if len(lines) < 2 || !bytes.HasPrefix(lines[1], []byte(versionLinePrefix)) {
+		return "unknown", content
+	}
+	ver := string(bytes.TrimPrefix(lines[1], []byte(versionLinePrefix)))
+	if len(lines) == 2 {
+		return ver, lines[0]
+	}
+	return ver, append(append(lines[0], '\n'), lines[2]...)
+}
+
+// splitAuditCorrelationID extracts the correlation ID from the second line of
+// audit log content with the version line removed, it's empty for logs
+// written by older versions
+func splitAuditCorrelationID(content []byte) (string, []byte) {
+	lines := bytes.SplitN(content, []byte("\n"), 3)
+	if len(lines) < 2 || !bytes.HasPrefix(lines[1], []byte(correlationLinePrefix)) {
+		return "", content
+	}
+	id := string(bytes.TrimPrefix(lines[1], []byte(correlationLinePrefix)))
+	if len(lines) == 2 {
+		return id, lines[0]
+	}
+	return id, append(append(lines[0], '\n'), lines[2]...)
+}
+
+// splitAuditTopologyHash extracts the topology hash from the second line of
+// audit log content with the version and correlation lines removed, it's
+// "not recorded" for logs written by older versions or operations without
+// a topology
+func splitAuditTopologyHash(content []byte) (string, []byte) {
+	lines := bytes.SplitN(content, []byte("\n"), 3)
+	if len(lines) < 2 || !bytes.HasPrefix(lines[1], []byte(topologyLinePrefix)) {
+		return "not recorded", content
+	}
+	hash := string(bytes.TrimPrefix(lines[1], []byte(topologyLinePrefix)))
+	if len(lines) == 2 {
+		return hash, lines[0]
+	}
+	return hash, append(append(lines[0], '\n'), lines[2]...)
+}
+
+// splitAuditResult removes the exit code and duration lines following the
+// command line of audit log content, they are shown in the audit list
+func splitAuditResult(content []byte) []byte {
+	for _, prefix := range []string{exitCodeLinePrefix, durationLinePrefix} {
+		lines := bytes.SplitN(content, []byte("\n"), 3)
+		if len(lines) < 2 || !bytes.HasPrefix(lines[1], []byte(prefix)) {
+			continue
+		}
+		if len(lines) == 2 {
+			content = lines[0]
+			continue
+		}
+		content = append(append(lines[0], '\n'), lines[2]...)
+	}
+	return content
+}
+
 // decodeAuditID decodes the auditID to unix timestamp
 func decodeAuditID(auditID string) (time.Time, error) {
 	tsID := auditID
@@ -220,8 +494,8 @@ type deleteAuditLog struct {
 	DelBeforeTime time.Time `json:"delete_before_time"` // audit logs before `DelBeforeTime` will be deleted
 }
 
-// DeleteAuditLog  cleanup audit log
-func DeleteAuditLog(dir string, retainDays int, skipConfirm bool, displayMode string) error {
+// DeleteAuditLog  cleanup audit log, the retain days are counted back from the time of clock
+func DeleteAuditLog(dir string, retainDays int, skipConfirm bool, displayMode string, clock tiuputils.Clock) error {
 	if retainDays < 0 {
 		return errors.Errorf("retainDays cannot be less than 0")
 	}
@@ -233,8 +507,7 @@ func DeleteAuditLog(dir string, retainDays int, skipConfirm bool, displayMode st
 	}
 
 	//  audit logs before `DelBeforeTime` will be deleted
-	oneDayDuration, _ := time.ParseDuration("-24h")
-	deleteLog.DelBeforeTime = time.Now().Add(oneDayDuration * time.Duration(retainDays))
+	deleteLog.DelBeforeTime = tiuputils.RetainBefore(clock, retainDays)
 
 	fileInfos, err := os.ReadDir(dir)
 	if err != nil {
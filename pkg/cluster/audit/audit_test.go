@@ -25,7 +25,11 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/base52"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/pingcap/tiup/pkg/version"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -85,6 +89,30 @@ func (s *testAuditSuite) TestOutputAuditLog(c *C) {
 	c.Assert(len(paths), Equals, 20)
 }
 
+func (s *testAuditSuite) TestAuditSink(c *C) {
+	dir := auditDir()
+	resetDir()
+
+	var events []Event
+	RegisterSink(SinkFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}))
+	defer RegisterSink(nil)
+
+	c.Assert(OutputAuditLog(dir, "sink", []byte("audit log")), IsNil)
+	c.Assert(len(events), Equals, 1)
+	c.Assert(strings.HasSuffix(events[0].ID, "_sink"), IsTrue)
+	c.Assert(string(events[0].Log), Equals, "audit log")
+	c.Assert(utils.IsExist(filepath.Join(dir, events[0].ID)), IsTrue)
+
+	// failures of the sink don't fail writing the audit log
+	RegisterSink(SinkFunc(func(e Event) error {
+		return errors.New("sink is down")
+	}))
+	c.Assert(OutputAuditLog(dir, "", []byte("audit log")), IsNil)
+}
+
 func (s *testAuditSuite) TestShowAuditLog(c *C) {
 	dir := auditDir()
 	resetDir()
@@ -114,12 +142,12 @@ func (s *testAuditSuite) TestShowAuditLog(c *C) {
 	c.Assert(os.WriteFile(fname, []byte("test with nanosecond"), 0644), IsNil)
 
 	f := openStdout()
-	c.Assert(ShowAuditList(dir), IsNil)
+	c.Assert(ShowAuditList(dir, SortTimeAsc), IsNil)
 	// tabby table size is based on column width, while time.RFC3339 maybe print out timezone like +08:00 or Z(UTC)
 	// skip the first two lines
 	list := strings.Join(strings.Split(readFakeStdout(f), "\n")[2:], "\n")
-	c.Assert(list, Equals, fmt.Sprintf(`4F7ZTL       %s  test with second
-ftmpqzww84Q  %s  test with nanosecond
+	c.Assert(list, Equals, fmt.Sprintf(`4F7ZTL       %s  test with second      -     -
+ftmpqzww84Q  %s  test with nanosecond  -     -
 `,
 		time.Unix(second, 0).Format(time.RFC3339),
 		time.Unix(nanoSecond/1e9, 0).Format(time.RFC3339),
@@ -130,6 +158,8 @@ ftmpqzww84Q  %s  test with nanosecond
 	c.Assert(ShowAuditLog(dir, "4F7ZTL"), IsNil)
 	c.Assert(readFakeStdout(f), Equals, fmt.Sprintf(`---------------------------------------
 - OPERATION TIME: %s -
+- TIUP VERSION: unknown -
+- TOPOLOGY HASH: not recorded -
 ---------------------------------------
 test with second`,
 		time.Unix(second, 0).Format("2006-01-02T15:04:05"),
@@ -141,9 +171,145 @@ test with second`,
 	c.Assert(ShowAuditLog(dir, "ftmpqzww84Q"), IsNil)
 	c.Assert(readFakeStdout(f), Equals, fmt.Sprintf(`---------------------------------------
 - OPERATION TIME: %s -
+- TIUP VERSION: unknown -
+- TOPOLOGY HASH: not recorded -
 ---------------------------------------
 test with nanosecond`,
 		time.Unix(nanoSecond/1e9, 0).Format("2006-01-02T15:04:05"),
 	))
 	f.Close()
+
+	c.Assert(OutputAuditLog(dir, "ver", []byte("test with version")), IsNil)
+	items, err := GetAuditList(dir)
+	c.Assert(err, IsNil)
+	c.Assert(items, HasLen, 3)
+	f = openStdout()
+	c.Assert(ShowAuditLog(dir, items[2].ID), IsNil)
+	out := readFakeStdout(f)
+	c.Assert(strings.Contains(out, "- TIUP VERSION: "+version.NewTiUPVersion().SemVer()), IsTrue)
+	c.Assert(strings.Contains(out, versionLinePrefix), IsFalse)
+	c.Assert(strings.Contains(out, "- CORRELATION ID: "+logprinter.CorrelationID()+" -"), IsTrue)
+	c.Assert(strings.Contains(out, correlationLinePrefix), IsFalse)
+	c.Assert(strings.Contains(out, "- TOPOLOGY HASH: not recorded -"), IsTrue)
+	c.Assert(strings.HasSuffix(out, "test with version"), IsTrue)
+	f.Close()
+
+	SetTopologyHash("0123456789abcdef")
+	SetTopologyHash("fedcba9876543210") // only the first one is kept
+	defer func() { topologyHash = "" }()
+	c.Assert(OutputAuditLog(dir, "topo", []byte("test with topology")), IsNil)
+	items, err = GetAuditList(dir)
+	c.Assert(err, IsNil)
+	c.Assert(items, HasLen, 4)
+	for _, item := range items {
+		if !strings.HasSuffix(item.ID, "_topo") {
+			continue
+		}
+		f = openStdout()
+		c.Assert(ShowAuditLog(dir, item.ID), IsNil)
+		out = readFakeStdout(f)
+		c.Assert(strings.Contains(out, "- TOPOLOGY HASH: 0123456789abcdef -"), IsTrue)
+		c.Assert(strings.Contains(out, topologyLinePrefix), IsFalse)
+		c.Assert(strings.HasSuffix(out, "test with topology"), IsTrue)
+		f.Close()
+	}
+}
+
+func (s *testAuditSuite) TestAuditResult(c *C) {
+	dir := c.MkDir()
+
+	SetResult(1, 1500*time.Millisecond)
+	defer func() { resultCode, resultDuration = nil, 0 }()
+	c.Assert(OutputAuditLog(dir, "", []byte("test with result")), IsNil)
+	items, err := GetAuditList(dir)
+	c.Assert(err, IsNil)
+	c.Assert(items, HasLen, 1)
+	c.Assert(*items[0].ExitCode, Equals, 1)
+	c.Assert(items[0].Duration, Equals, 1500*time.Millisecond)
+}
+
+func (s *testAuditSuite) TestSortAuditList(c *C) {
+	failed, succeeded := 1, 0
+	items := []Item{
+		{ID: "b", Time: "2023-05-01T10:00:02Z", ExitCode: &succeeded, Duration: time.Minute},
+		{ID: "a", Time: "2023-05-01T10:00:01Z"},
+		{ID: "c", Time: "2023-05-01T10:00:03Z", ExitCode: &failed, Duration: time.Second},
+	}
+	ids := func() string {
+		res := ""
+		for _, item := range items {
+			res += item.ID
+		}
+		return res
+	}
+
+	c.Assert(SortAuditList(items, SortTimeAsc), IsNil)
+	c.Assert(ids(), Equals, "abc")
+	c.Assert(SortAuditList(items, SortTimeDesc), IsNil)
+	c.Assert(ids(), Equals, "cba")
+	// failed first and unknown last
+	c.Assert(SortAuditList(items, SortStatus), IsNil)
+	c.Assert(ids(), Equals, "cba")
+	c.Assert(SortAuditList(items, SortDuration), IsNil)
+	c.Assert(ids(), Equals, "bca")
+	c.Assert(SortAuditList(items, "size"), NotNil)
+}
+
+func (s *testAuditSuite) TestDeleteAuditLog(c *C) {
+	dir := c.MkDir()
+
+	// one log at 23:59 of day 1 and another at 00:01 of day 2
+	old := time.Date(2023, 1, 1, 23, 59, 0, 0, time.Local)
+	recent := time.Date(2023, 1, 2, 0, 1, 0, 0, time.Local)
+	for _, t := range []time.Time{old, recent} {
+		fname := filepath.Join(dir, base52.Encode(t.Unix()))
+		c.Assert(os.WriteFile(fname, []byte("test"), 0644), IsNil)
+	}
+
+	// keeping one day at 00:00 of day 3 only removes the log of day 1
+	now := time.Date(2023, 1, 3, 0, 0, 0, 0, time.Local)
+	c.Assert(DeleteAuditLog(dir, 1, true, "json", utils.FixedClock(now)), IsNil)
+	list, err := GetAuditList(dir)
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 1)
+	c.Assert(list[0].ID, Equals, base52.Encode(recent.Unix()))
+}
+
+func (s *testAuditSuite) TestGetClusterAuditList(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(dir, base52.Encode(1604413577)), []byte("\nempty command"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, base52.Encode(1604413578)), []byte("/bin/tiup-cluster\nno args"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, base52.Encode(1604413579)), []byte("/bin/tiup-cluster start foo\nstarted"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, base52.Encode(1604413580)), []byte("/bin/tiup-cluster start bar\nstarted"), 0644), IsNil)
+
+	items, err := GetClusterAuditList(dir, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(items, HasLen, 1)
+	c.Assert(items[0].Command, Equals, "/bin/tiup-cluster start foo")
+}
+
+func (s *testAuditSuite) TestMergeTimeline(c *C) {
+	t0 := time.Date(2023, 5, 1, 10, 0, 0, 500, time.UTC)
+	history := []HistoryEntry{
+		{Time: t0.Add(time.Minute), Command: "tiup cluster stop foo", Args: []string{"stop", "foo"}, Code: 1},
+		{Time: t0, Command: "tiup cluster start foo", Args: []string{"start", "foo"}, Code: 0},
+	}
+	items := []Item{
+		{ID: "a", Time: t0.Add(10 * time.Second).Format(time.RFC3339), Command: "/bin/tiup-cluster start foo"},
+		{ID: "b", Time: t0.Add(2 * time.Minute).Format(time.RFC3339), Command: "/bin/tiup-cluster display foo"},
+		{ID: "c", Time: t0.Add(70 * time.Second).Format(time.RFC3339), Command: "/bin/tiup-cluster stop foo"},
+	}
+
+	timeline := MergeTimeline(history, items)
+	c.Assert(timeline, HasLen, 3)
+	c.Assert(timeline[0].Command, Equals, "tiup cluster start foo")
+	c.Assert(timeline[0].AuditID, Equals, "a")
+	c.Assert(*timeline[0].ExitCode, Equals, 0)
+	c.Assert(timeline[1].Command, Equals, "tiup cluster stop foo")
+	c.Assert(timeline[1].AuditID, Equals, "c")
+	c.Assert(*timeline[1].ExitCode, Equals, 1)
+	// run without tiup
+	c.Assert(timeline[2].Command, Equals, "/bin/tiup-cluster display foo")
+	c.Assert(timeline[2].AuditID, Equals, "b")
+	c.Assert(timeline[2].ExitCode, IsNil)
 }
@@ -0,0 +1,76 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is an audit record of a command, it's delivered to the registered sink
+// after the audit log is written to the audit dir
+type Event struct {
+	ID            string
+	Time          time.Time
+	Command       []string
+	Version       string
+	CorrelationID string
+	TopologyHash  string
+	// ExitCode is nil if the result of the command is not recorded
+	ExitCode *int
+	Duration time.Duration
+	// Log is the content of the audit log following the header lines
+	Log []byte
+}
+
+// Sink receives the audit events, e.g. to forward them to an external system
+// in real time. Deliver is called synchronously when the command exits, so it
+// should not block for long.
+type Sink interface {
+	Deliver(e Event) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(e Event) error
+
+// Deliver implements Sink
+func (f SinkFunc) Deliver(e Event) error {
+	return f(e)
+}
+
+type nopSink struct{}
+
+func (nopSink) Deliver(Event) error { return nil }
+
+var (
+	sinkMu sync.RWMutex
+	sink   Sink = nopSink{}
+)
+
+// RegisterSink sets the sink the audit events are delivered to, nil restores
+// the default one which drops them
+func RegisterSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	if s == nil {
+		s = nopSink{}
+	}
+	sink = s
+}
+
+func deliverEvent(e Event) error {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	return sink.Deliver(e)
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// HistoryEntry is a command recorded in the TiUP history
+type HistoryEntry struct {
+	Time    time.Time
+	Command string
+	Args    []string // the args passed to the component
+	Code    int
+}
+
+// TimelineEntry is an operation in the merged view of history and audit logs,
+// the exit code is only known from history and the audit ID only from audit logs
+type TimelineEntry struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	AuditID  string    `json:"audit_id,omitempty"`
+}
+
+// GetClusterAuditList returns the audit items of commands on the cluster
+func GetClusterAuditList(dir, name string) ([]Item, error) {
+	items, err := GetAuditList(dir)
+	if err != nil {
+		// no audit log has been written yet
+		if os.IsNotExist(err) {
+			return []Item{}, nil
+		}
+		return nil, err
+	}
+	res := make([]Item, 0)
+	for _, item := range items {
+		// the first field is the binary of the component
+		fields := strings.Fields(item.Command)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, arg := range fields[1:] {
+			if arg == name {
+				res = append(res, item)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// MergeTimeline correlates the history entries with audit items running the
+// same args, an audit log is written when the command finishes so it is
+// matched with the latest history entry started before it
+func MergeTimeline(history []HistoryEntry, items []Item) []TimelineEntry {
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Time.Before(history[j].Time)
+	})
+
+	matched := make([]bool, len(items))
+	timeline := make([]TimelineEntry, 0, len(history)+len(items))
+	for _, h := range history {
+		code := h.Code
+		entry := TimelineEntry{Time: h.Time, Command: h.Command, ExitCode: &code}
+		// audit time is in seconds
+		start := h.Time.Truncate(time.Second)
+		for i, item := range items {
+			if matched[i] || auditArgs(item) != strings.Join(h.Args, " ") {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, item.Time)
+			if err != nil || t.Before(start) {
+				continue
+			}
+			matched[i] = true
+			entry.AuditID = item.ID
+			break
+		}
+		timeline = append(timeline, entry)
+	}
+
+	// commands not run through tiup have no history
+	for i, item := range items {
+		if matched[i] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, item.Time)
+		if err != nil {
+			continue
+		}
+		timeline = append(timeline, TimelineEntry{Time: t, Command: item.Command, AuditID: item.ID})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+	return timeline
+}
+
+// auditArgs returns the args of the audit command without the binary
+func auditArgs(item Item) string {
+	_, args, _ := strings.Cut(item.Command, " ")
+	return args
+}
+
+// ShowTimeline prints the merged timeline
+func ShowTimeline(timeline []TimelineEntry, displayMode string) error {
+	if displayMode == "json" {
+		data, err := json.MarshalIndent(timeline, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	table := [][]string{{"Time", "Command", "Code", "Audit ID"}}
+	for _, e := range timeline {
+		code := "-"
+		if e.ExitCode != nil {
+			code = strconv.Itoa(*e.ExitCode)
+		}
+		auditID := e.AuditID
+		if auditID == "" {
+			auditID = "-"
+		}
+		table = append(table, []string{e.Time.Format(time.RFC3339), e.Command, code, auditID})
+	}
+	tui.PrintTable(table, true)
+	return nil
+}
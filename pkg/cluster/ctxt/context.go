@@ -77,6 +77,8 @@ type (
 		PublicKeyPath  string
 
 		Concurrency int // max number of parallel tasks running at the same time
+
+		shared map[any]any // values shared by the tasks of the operation
 	}
 )
 
@@ -118,6 +120,29 @@ func GetInner(ctx context.Context) *Context {
 	return ctx.Value(ctxKey).(*Context)
 }
 
+// LookupInner returns the *Context from context.Context's value if any
+func LookupInner(ctx context.Context) (*Context, bool) {
+	inner, ok := ctx.Value(ctxKey).(*Context)
+	return inner, ok
+}
+
+// Shared returns the value shared by the tasks of the operation with the key,
+// it's created by create if not exist yet
+func (ctx *Context) Shared(key any, create func() any) any {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.shared == nil {
+		ctx.shared = make(map[any]any)
+	}
+	v, ok := ctx.shared[key]
+	if !ok {
+		v = create()
+		ctx.shared[key] = v
+	}
+	return v
+}
+
 // Get implements the operation.ExecutorGetter interface.
 func (ctx *Context) Get(host string) (e Executor) {
 	ctx.mutex.Lock()
@@ -0,0 +1,103 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tiup/pkg/localdata"
+)
+
+// ErrHostNotAllowed means connecting to a host out of the allow-list
+var ErrHostNotAllowed = errNS.NewType("host_not_allowed")
+
+// HostAllowList is the list of hosts TiUP may connect to with ssh, the entries
+// are either hosts matched exactly or CIDRs matching IP addresses
+type HostAllowList struct {
+	hosts map[string]struct{}
+	nets  []*net.IPNet
+}
+
+// ParseHostAllowList parses the comma separated list of hosts or CIDRs, it's
+// nil if the list is empty which allows all hosts
+func ParseHostAllowList(s string) *HostAllowList {
+	var l *HostAllowList
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if l == nil {
+			l = &HostAllowList{hosts: make(map[string]struct{})}
+		}
+		if _, ipNet, err := net.ParseCIDR(item); err == nil {
+			l.nets = append(l.nets, ipNet)
+			continue
+		}
+		l.hosts[item] = struct{}{}
+	}
+	return l
+}
+
+// AllowedHosts returns the allow-list set by the environment variable, it's
+// nil if it's not set
+func AllowedHosts() *HostAllowList {
+	return ParseHostAllowList(os.Getenv(localdata.EnvNameSSHAllowedHosts))
+}
+
+// Allowed checks whether the host is in the list
+func (l *HostAllowList) Allowed(host string) bool {
+	if l == nil {
+		return true
+	}
+	if _, ok := l.hosts[host]; ok {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range l.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckAllowedHosts returns an error listing the hosts not in the allow-list
+// set by the environment variable, all hosts are allowed if it's not set
+func CheckAllowedHosts(hosts ...string) error {
+	l := AllowedHosts()
+	if l == nil {
+		return nil
+	}
+	denied := make(map[string]struct{})
+	for _, host := range hosts {
+		if host != "" && !l.Allowed(host) {
+			denied[host] = struct{}{}
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(denied))
+	for host := range denied {
+		list = append(list, host)
+	}
+	sort.Strings(list)
+	return ErrHostNotAllowed.New("host %s not in the allow-list set by %s",
+		strings.Join(list, ", "), localdata.EnvNameSSHAllowedHosts)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostAllowList(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Nil(ParseHostAllowList(""))
+	assert.Nil(ParseHostAllowList(" , "))
+	var l *HostAllowList
+	assert.True(l.Allowed("172.16.5.1"))
+
+	l = ParseHostAllowList("tikv-1.example.com, 172.16.5.0/24,10.0.0.1")
+	assert.True(l.Allowed("tikv-1.example.com"))
+	assert.True(l.Allowed("172.16.5.99"))
+	assert.True(l.Allowed("10.0.0.1"))
+	assert.False(l.Allowed("10.0.0.2"))
+	assert.False(l.Allowed("tikv-2.example.com"))
+	assert.False(l.Allowed("172.16.6.1"))
+}
+
+func TestCheckAllowedHosts(t *testing.T) {
+	assert := require.New(t)
+
+	t.Setenv(localdata.EnvNameSSHAllowedHosts, "")
+	assert.Nil(CheckAllowedHosts("172.16.5.1"))
+
+	t.Setenv(localdata.EnvNameSSHAllowedHosts, "172.16.5.0/24")
+	assert.Nil(CheckAllowedHosts("172.16.5.1", "172.16.5.2", ""))
+	err := CheckAllowedHosts("172.16.5.1", "172.16.6.2", "172.16.6.1", "172.16.6.2")
+	assert.True(errorx.IsOfType(err, ErrHostNotAllowed))
+	assert.Contains(err.Error(), "172.16.6.1, 172.16.6.2")
+
+	_, err = New(SSHTypeBuiltin, false, SSHConfig{Host: "172.16.6.1", User: "tidb"})
+	assert.True(errorx.IsOfType(err, ErrHostNotAllowed))
+}
@@ -83,7 +83,10 @@ func (c *CheckPointExecutor) Execute(ctx context.Context, cmd string, sudo bool,
 		return []byte(point.Hit()["stdout"].(string)), []byte(point.Hit()["stderr"].(string)), nil
 	}
 
-	return c.Executor.Execute(ctx, cmd, sudo, timeout...)
+	begin := time.Now()
+	stdout, stderr, err = c.Executor.Execute(ctx, cmd, sudo, timeout...)
+	recordCommand(c.config.Host, cmd, time.Since(begin))
+	return stdout, stderr, err
 }
 
 // Transfer implements Executer interface.
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tiup/pkg/cluster/ctxt"
 	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // SSHType represent the type of the chanel used by ssh
@@ -43,6 +45,10 @@ var (
 	// SSHTypeNone is the type of local executor (no ssh will be used)
 	SSHTypeNone SSHType = "none"
 
+	// SSHTypeAuto is the type using the local executor for the hosts that are
+	// the control node itself and the builtin ssh executor for the others
+	SSHTypeAuto SSHType = "auto"
+
 	executeDefaultTimeout = time.Second * 60
 
 	// This command will be execute once the NativeSSHExecutor is created.
@@ -52,8 +58,40 @@ var (
 
 	// SSH authorized_keys file
 	defaultSSHAuthorizedKeys = "~/.ssh/authorized_keys"
+
+	// The name of the control socket of the master connection used by the system
+	// ssh client, %C is a hash of the local host, remote host, port and user. It's
+	// put in sshControlDir as it gives access to the host without login.
+	sshControlName = "%C"
+	// How long the idle master connection is kept after the last command
+	sshControlPersist = time.Second * 60
 )
 
+// sshControlDir returns the dir of the control sockets of the system ssh client,
+// it's in the profile dir of the user and only accessible by the user
+func sshControlDir() (string, error) {
+	home := os.Getenv(localdata.EnvNameHome)
+	if home == "" {
+		home = localdata.DefaultTiUPHome
+	}
+	if home == "" {
+		home = filepath.Join(utils.UserHome(), localdata.ProfileDirName)
+	}
+	dir := filepath.Join(home, "ssh")
+	// the socket path is limited to ~100 bytes, %C expands to 40
+	if len(dir) > 60 {
+		return "", fmt.Errorf("the path %s is too long for ssh control sockets", dir)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	// the dir may be created with looser permissions by others
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
 // New create a new Executor
 func New(etype SSHType, sudo bool, c SSHConfig) (ctxt.Executor, error) {
 	if etype == "" {
@@ -82,6 +120,25 @@ func New(etype SSHType, sudo bool, c SSHConfig) (ctxt.Executor, error) {
 		c.Timeout = time.Second * 5 // default timeout is 5 sec
 	}
 
+	if etype == SSHTypeAuto {
+		etype = SSHTypeBuiltin
+		if IsLocalHost(c.Host) {
+			etype = SSHTypeNone
+		}
+	}
+
+	// refuse to connect to hosts out of the allow-list, it's the last line of
+	// defense in case a check before the operation is missed
+	if etype != SSHTypeNone {
+		hosts := []string{c.Host}
+		if c.Proxy != nil {
+			hosts = append(hosts, c.Proxy.Host)
+		}
+		if err := CheckAllowedHosts(hosts...); err != nil {
+			return nil, err
+		}
+	}
+
 	var executor ctxt.Executor
 	switch etype {
 	case SSHTypeBuiltin:
@@ -131,6 +188,18 @@ func UnwarpCheckPointExecutor(e ctxt.Executor) ctxt.Executor {
 	}
 }
 
+// IsLocalHost checks whether the host is the control node itself, which is
+// either a loopback address or an address of its network interfaces
+func IsLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	return checkLocalIP(host) == nil
+}
+
 func checkLocalIP(ip string) error {
 	ifaces, err := net.Interfaces()
 	if err != nil {
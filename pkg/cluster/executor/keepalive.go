@@ -0,0 +1,72 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultKeepAlive is the default interval of keepalive messages sent on idle
+// SSH connections
+const DefaultKeepAlive = 30 * time.Second
+
+// keepAlive is the interval of keepalive messages in nanoseconds, 0 disables them
+var keepAlive atomic.Int64
+
+func init() {
+	keepAlive.Store(int64(DefaultKeepAlive))
+}
+
+// SetKeepAlive sets the interval of keepalive messages sent on SSH connections,
+// so that the idle ones are not dropped by stateful firewalls during long
+// waits, e.g. for a slow starting instance. Pass 0 to disable it.
+func SetKeepAlive(d time.Duration) {
+	keepAlive.Store(int64(d))
+}
+
+// keepAliveArgs makes the system ssh client send keepalive messages
+func keepAliveArgs() []string {
+	d := time.Duration(keepAlive.Load())
+	if d <= 0 {
+		return nil
+	}
+	// the option is in seconds and 0 disables it
+	return []string{"-o", fmt.Sprintf("ServerAliveInterval=%d", max(int64(d.Seconds()), 1))}
+}
+
+// startKeepAlive sends keepalive messages on the connection until it fails,
+// then the connection is closed so that the next command reconnects rather
+// than hanging on it
+func startKeepAlive(client *ssh.Client, host string) {
+	d := time.Duration(keepAlive.Load())
+	if d <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				zap.L().Debug("SSH keepalive failed", zap.String("host", host), zap.Error(err))
+				client.Close()
+				return
+			}
+		}
+	}()
+}
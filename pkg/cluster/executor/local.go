@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"os/user"
 	"path/filepath"
@@ -81,6 +82,13 @@ func (l *Local) Execute(ctx context.Context, cmd string, sudo bool, timeout ...t
 	stderr := new(bytes.Buffer)
 	command.Stdout = stdout
 	command.Stderr = stderr
+	if streaming() {
+		outStream, errStream := newLineWriter(l.Config.Host), newLineWriter(l.Config.Host)
+		defer outStream.Close()
+		defer errStream.Close()
+		command.Stdout = io.MultiWriter(stdout, outStream)
+		command.Stderr = io.MultiWriter(stderr, errStream)
+	}
 
 	err = command.Run()
 
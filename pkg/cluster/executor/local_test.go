@@ -95,3 +95,24 @@ func TestLocalExecuteWithQuotes(t *testing.T) {
 		assert.Nil(err)
 	}
 }
+
+func TestAutoExecutor(t *testing.T) {
+	assert := require.New(t)
+
+	assert.True(IsLocalHost("localhost"))
+	assert.True(IsLocalHost("127.0.0.1"))
+	assert.True(IsLocalHost("::1"))
+	assert.False(IsLocalHost("192.0.2.1"))
+
+	user, err := user.Current()
+	assert.Nil(err)
+	e, err := New(SSHTypeAuto, false, SSHConfig{Host: "127.0.0.1", User: user.Username})
+	assert.Nil(err)
+	_, ok := UnwarpCheckPointExecutor(e).(*Local)
+	assert.True(ok)
+
+	e, err = New(SSHTypeAuto, false, SSHConfig{Host: "192.0.2.1", User: user.Username})
+	assert.Nil(err)
+	_, ok = UnwarpCheckPointExecutor(e).(*EasySSHExecutor)
+	assert.True(ok)
+}
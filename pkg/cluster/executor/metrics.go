@@ -0,0 +1,85 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HostMetrics is the statistics of the commands executed on a host
+type HostMetrics struct {
+	Host           string        `json:"host"`
+	Commands       int           `json:"commands"`
+	Total          time.Duration `json:"total"`
+	Slowest        time.Duration `json:"slowest"`
+	SlowestCommand string        `json:"slowest_command"`
+}
+
+var (
+	// statistics of commands keyed by host, nil means they are not collected
+	metrics   map[string]*HostMetrics
+	metricsMu sync.Mutex
+)
+
+// EnableMetrics starts collecting the number and time of commands executed on
+// each host, the collected ones are dropped. Pass false to stop it.
+func EnableMetrics(enable bool) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if enable {
+		metrics = make(map[string]*HostMetrics)
+	} else {
+		metrics = nil
+	}
+}
+
+// Metrics returns the collected statistics of hosts, the ones taking the most
+// time come first
+func Metrics() []HostMetrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	result := make([]HostMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result
+}
+
+// recordCommand adds a command executed on the host to the statistics
+func recordCommand(host, cmd string, elapsed time.Duration) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if metrics == nil {
+		return
+	}
+	m, ok := metrics[host]
+	if !ok {
+		m = &HostMetrics{Host: host}
+		metrics[host] = m
+	}
+	m.Commands++
+	m.Total += elapsed
+	if elapsed > m.Slowest {
+		m.Slowest = elapsed
+		m.SlowestCommand = cmd
+	}
+}
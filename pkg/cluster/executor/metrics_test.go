@@ -0,0 +1,47 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	// not collected unless enabled
+	recordCommand("127.0.0.1", "ls", time.Second)
+	assert.Empty(Metrics())
+
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+
+	recordCommand("127.0.0.1", "ls", time.Second)
+	recordCommand("127.0.0.1", "rm -rf /tmp/a", 3*time.Second)
+	recordCommand("127.0.0.2", "ls", 5*time.Second)
+	recordCommand("127.0.0.3", "ls", 2*time.Second)
+
+	assert.Equal([]HostMetrics{
+		{Host: "127.0.0.2", Commands: 1, Total: 5 * time.Second, Slowest: 5 * time.Second, SlowestCommand: "ls"},
+		{Host: "127.0.0.1", Commands: 2, Total: 4 * time.Second, Slowest: 3 * time.Second, SlowestCommand: "rm -rf /tmp/a"},
+		{Host: "127.0.0.3", Commands: 1, Total: 2 * time.Second, Slowest: 2 * time.Second, SlowestCommand: "ls"},
+	}, Metrics())
+
+	// enabling again drops the collected ones
+	EnableMetrics(true)
+	assert.Empty(Metrics())
+}
@@ -17,11 +17,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/appleboy/easyssh-proxy"
@@ -33,6 +35,7 @@ import (
 	"github.com/pingcap/tiup/pkg/tui"
 	"github.com/pingcap/tiup/pkg/utils"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
 )
 
 var (
@@ -70,6 +73,11 @@ type (
 		Config *easyssh.MakeConfig
 		Locale string // the locale used when executing the command
 		Sudo   bool   // all commands run with this executor will be using sudo
+
+		mu       sync.Mutex
+		client   *ssh.Client // the connection shared by all commands run with this executor
+		sessions int         // number of sessions open on the connection
+		idle     *time.Timer // closes the connection once it's idle for sshControlPersist
 	}
 
 	// NativeSSHExecutor implements Excutor with native SSH transportation layer.
@@ -155,7 +163,7 @@ func (e *EasySSHExecutor) Execute(ctx context.Context, cmd string, sudo bool, ti
 		timeout = append(timeout, executeDefaultTimeout)
 	}
 
-	stdout, stderr, done, err := e.Config.Run(cmd, timeout...)
+	stdout, stderr, done, err := e.run(cmd, timeout...)
 
 	logfn := zap.L().Info
 	if err != nil {
@@ -197,6 +205,180 @@ func (e *EasySSHExecutor) Execute(ctx context.Context, cmd string, sudo bool, ti
 	return []byte(stdout), []byte(stderr), nil
 }
 
+// run executes the command in a new session of the connection shared by this
+// executor, so that the connection is established only once for all commands
+// sent to the host. It has the same return values as easyssh.MakeConfig.Run()
+func (e *EasySSHExecutor) run(cmd string, timeout ...time.Duration) (outStr string, errStr string, done bool, err error) {
+	session, reused, err := e.newSession()
+	if err == nil {
+		defer e.releaseSession()
+	}
+	if err != nil {
+		if !reused {
+			return outStr, errStr, done, err
+		}
+		// the shared connection is broken or the server refuses to open more
+		// sessions on it (MaxSessions), fallback to a dedicated connection
+		zap.L().Debug("SSH connection reuse failed, use a new connection",
+			zap.String("host", e.Config.Server),
+			zap.Error(err))
+		if streaming() {
+			return e.runStream(cmd, timeout...)
+		}
+		return e.Config.Run(cmd, timeout...)
+	}
+	defer session.Close()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if streaming() {
+		outStream, errStream := newLineWriter(e.Config.Server), newLineWriter(e.Config.Server)
+		defer outStream.Close()
+		defer errStream.Close()
+		session.Stdout = io.MultiWriter(stdout, outStream)
+		session.Stderr = io.MultiWriter(stderr, errStream)
+	}
+
+	if err = session.Start(cmd); err != nil {
+		return outStr, errStr, done, err
+	}
+
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- session.Wait()
+	}()
+
+	executeTimeout := executeDefaultTimeout
+	if len(timeout) > 0 {
+		executeTimeout = timeout[0]
+	}
+	select {
+	case err = <-waitCh:
+		done = true
+	case <-time.After(executeTimeout):
+		err = fmt.Errorf("Run Command Timeout")
+		// closing the session makes Wait() return, then the output is safe to read
+		session.Close()
+		select {
+		case <-waitCh:
+		case <-time.After(time.Second):
+			// the output may still be written, don't touch it
+			return outStr, errStr, done, err
+		}
+	}
+	return stdout.String(), stderr.String(), done, err
+}
+
+// newSession opens a session on the shared connection, the connection is
+// established on first use. reused reports whether an existing connection was used.
+func (e *EasySSHExecutor) newSession() (session *ssh.Session, reused bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.idle != nil {
+		e.idle.Stop()
+		e.idle = nil
+	}
+
+	if e.client == nil {
+		session, client, err := e.Config.Connect()
+		if err != nil {
+			return nil, false, err
+		}
+		e.client = client
+		e.sessions++
+		startKeepAlive(client, e.Config.Server)
+		return session, false, nil
+	}
+
+	session, err = e.client.NewSession()
+	if err != nil {
+		// drop the connection so that the next command reconnects
+		e.client.Close()
+		e.client = nil
+		return nil, true, err
+	}
+	e.sessions++
+	return session, true, nil
+}
+
+// releaseSession marks a session closed, the connection is closed if no more
+// sessions are opened on it in sshControlPersist
+func (e *EasySSHExecutor) releaseSession() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sessions--
+	if e.sessions > 0 || e.client == nil {
+		return
+	}
+	client := e.client
+	e.idle = time.AfterFunc(sshControlPersist, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.client == client && e.sessions == 0 {
+			e.client.Close()
+			e.client = nil
+		}
+	})
+}
+
+// Close closes the connection shared by the commands, the next command
+// reconnects if the executor is used again
+func (e *EasySSHExecutor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.idle != nil {
+		e.idle.Stop()
+		e.idle = nil
+	}
+	if e.client == nil {
+		return nil
+	}
+	err := e.client.Close()
+	e.client = nil
+	return err
+}
+
+// runStream is the same as easyssh.MakeConfig.Run() but also writes each
+// line of the output to the output stream as soon as it is received
+func (e *EasySSHExecutor) runStream(cmd string, timeout ...time.Duration) (outStr string, errStr string, done bool, err error) {
+	stdoutChan, stderrChan, doneChan, errChan, err := e.Config.Stream(cmd, timeout...)
+	if err != nil {
+		return outStr, errStr, done, err
+	}
+	stream := newLineWriter(e.Config.Server)
+	// read from the output channel until the done signal is passed
+loop:
+	for {
+		select {
+		case done = <-doneChan:
+			break loop
+		case outline, ok := <-stdoutChan:
+			if !ok {
+				stdoutChan = nil
+			}
+			if outline != "" {
+				outStr += outline + "\n"
+				stream.writeLine([]byte(outline))
+			}
+		case errline, ok := <-stderrChan:
+			if !ok {
+				stderrChan = nil
+			}
+			if errline != "" {
+				errStr += errline + "\n"
+				stream.writeLine([]byte(errline))
+			}
+		case err = <-errChan:
+		}
+	}
+	return outStr, errStr, done, err
+}
+
 // Transfer copies files via SCP
 // This function depends on `scp` (a tool from OpenSSH or other SSH implementation)
 // This function is based on easyssh.MakeConfig.Scp() but with support of copying
@@ -273,6 +455,21 @@ func (e *NativeSSHExecutor) configArgs(args []string, isScp bool) []string {
 	return args
 }
 
+// controlArgs makes the system ssh client share one master connection for
+// all commands and transfers sent to the same host
+func controlArgs() []string {
+	dir, err := sshControlDir()
+	if err != nil {
+		zap.L().Debug("SSH connection sharing is disabled", zap.Error(err))
+		return nil
+	}
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", fmt.Sprintf("ControlPath=%s", filepath.Join(dir, sshControlName)),
+		"-o", fmt.Sprintf("ControlPersist=%d", int64(sshControlPersist.Seconds())),
+	}
+}
+
 // Execute run the command via SSH, it's not invoking any specific shell by default.
 func (e *NativeSSHExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
 	if e.ConnectionTestResult != nil {
@@ -315,6 +512,8 @@ func (e *NativeSSHExecutor) Execute(ctx context.Context, cmd string, sudo bool,
 	args := []string{ssh, "-o", "StrictHostKeyChecking=no"}
 
 	args = e.configArgs(args, false) // prefix and postfix args
+	args = append(args, controlArgs()...)
+	args = append(args, keepAliveArgs()...)
 	args = append(args, fmt.Sprintf("%s@%s", e.Config.User, e.Config.Host), cmd)
 
 	command := exec.CommandContext(ctx, args[0], args[1:]...)
@@ -323,6 +522,13 @@ func (e *NativeSSHExecutor) Execute(ctx context.Context, cmd string, sudo bool,
 	stderr := new(bytes.Buffer)
 	command.Stdout = stdout
 	command.Stderr = stderr
+	if streaming() {
+		outStream, errStream := newLineWriter(e.Config.Host), newLineWriter(e.Config.Host)
+		defer outStream.Close()
+		defer errStream.Close()
+		command.Stdout = io.MultiWriter(stdout, outStream)
+		command.Stderr = io.MultiWriter(stderr, errStream)
+	}
 
 	err := command.Run()
 
@@ -381,6 +587,8 @@ func (e *NativeSSHExecutor) Transfer(ctx context.Context, src, dst string, downl
 		args = append(args, "-C")
 	}
 	args = e.configArgs(args, true) // prefix and postfix args
+	args = append(args, controlArgs()...)
+	args = append(args, keepAliveArgs()...)
 
 	if download {
 		targetPath := filepath.Dir(dst)
@@ -14,10 +14,13 @@
 package executor
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pingcap/tiup/pkg/localdata"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,3 +136,25 @@ func TestNativeSSHConfigArgs(t *testing.T) {
 		assert.Equal(t, tc.e, strings.Join(e.configArgs([]string{}, tc.s), " "))
 	}
 }
+
+func TestSSHControlDir(t *testing.T) {
+	home, err := os.MkdirTemp("", "tiup")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+	t.Setenv(localdata.EnvNameHome, home)
+
+	// a dir created by others with loose permissions is fixed
+	assert.Nil(t, os.Mkdir(filepath.Join(home, "ssh"), 0777))
+	dir, err := sshControlDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, "ssh"), dir)
+	fi, err := os.Stat(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0700), fi.Mode().Perm())
+
+	args := strings.Join(controlArgs(), " ")
+	assert.Contains(t, args, "ControlPath="+filepath.Join(dir, "%C"))
+
+	t.Setenv(localdata.EnvNameHome, "/"+strings.Repeat("x", 80))
+	assert.Nil(t, controlArgs())
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	// outputStream is where the command output is streamed to line by line,
+	// nil means the output is only returned after the command finishes
+	outputStream io.Writer
+	// streamMu serializes lines written by different hosts
+	streamMu sync.Mutex
+)
+
+// SetOutputStream sets the writer that all remote command output is streamed to
+// as it happens, each line is prefixed with the host. Pass nil to disable it.
+func SetOutputStream(w io.Writer) {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	outputStream = w
+}
+
+func streaming() bool {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	return outputStream != nil
+}
+
+// lineWriter writes complete lines to the output stream with a host prefix,
+// partial lines are buffered until the newline arrives or it is closed
+type lineWriter struct {
+	host string
+	buf  bytes.Buffer
+}
+
+func newLineWriter(host string) *lineWriter {
+	return &lineWriter{host: host}
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		idx := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+		line := w.buf.Next(idx + 1)
+		w.writeLine(line[:idx])
+	}
+	return len(p), nil
+}
+
+// Close flushes the remaining partial line
+func (w *lineWriter) Close() error {
+	if w.buf.Len() > 0 {
+		w.writeLine(w.buf.Bytes())
+		w.buf.Reset()
+	}
+	return nil
+}
+
+func (w *lineWriter) writeLine(line []byte) {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	if outputStream == nil {
+		return
+	}
+	fmt.Fprintf(outputStream, "[%s] %s\n", w.host, line)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	assert := require.New(t)
+
+	out := new(bytes.Buffer)
+	SetOutputStream(out)
+	defer SetOutputStream(nil)
+
+	w := newLineWriter("127.0.0.1")
+	_, err := w.Write([]byte("first line\nsecond"))
+	assert.Nil(err)
+	assert.Equal("[127.0.0.1] first line\n", out.String())
+
+	_, err = w.Write([]byte(" line\nthird"))
+	assert.Nil(err)
+	assert.Equal("[127.0.0.1] first line\n[127.0.0.1] second line\n", out.String())
+
+	assert.Nil(w.Close())
+	assert.Equal("[127.0.0.1] first line\n[127.0.0.1] second line\n[127.0.0.1] third\n", out.String())
+}
@@ -24,16 +24,19 @@ import (
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
 	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/checkpoint"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
 	"github.com/pingcap/tiup/pkg/cluster/ctxt"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/cluster/task"
+	system "github.com/pingcap/tiup/pkg/cluster/template/systemd"
 	"github.com/pingcap/tiup/pkg/meta"
 	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/tidbver"
 	"github.com/pingcap/tiup/pkg/tui"
 	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/sync/errgroup"
 )
 
 // EnableCluster enable/disable the service in a cluster
@@ -52,11 +55,25 @@ func (m *Manager) EnableCluster(name string, gOpt operator.Options, isEnable boo
 	topo := metadata.GetTopology()
 	base := metadata.GetBaseMeta()
 
+	if _, _, err := operator.ParseBootDelay(gOpt.BootDelay); err != nil {
+		return err
+	}
+
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
 		return err
 	}
 
+	if isEnable && gOpt.UnitTemplate != "" {
+		tpl, err := loadUnitTemplate(gOpt.UnitTemplate)
+		if err != nil {
+			return err
+		}
+		b = b.Func("RenderSystemdUnit", func(ctx context.Context) error {
+			return m.renderSystemdUnits(ctx, name, topo, base.User, gOpt, tpl)
+		})
+	}
+
 	if isEnable {
 		b = b.Func("EnableCluster", func(ctx context.Context) error {
 			return operator.Enable(ctx, topo, gOpt, isEnable)
@@ -72,8 +89,11 @@ func (m *Manager) EnableCluster(name string, gOpt operator.Options, isEnable boo
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "enable"),
 	)
+	if m.dryRun("enable", t, gOpt) {
+		return operator.Enable(ctx, topo, gOpt, isEnable)
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -91,7 +111,49 @@ func (m *Manager) EnableCluster(name string, gOpt operator.Options, isEnable boo
 	return nil
 }
 
+// loadUnitTemplate reads the systemd unit template and checks that it only
+// refers to the variables available in the built-in one
+func loadUnitTemplate(fname string) (string, error) {
+	tpl, err := os.ReadFile(fname)
+	if err != nil {
+		return "", perrs.Annotatef(err, "read systemd unit template %s", fname)
+	}
+	if _, err := system.NewConfig("", "", "").ConfigWithTemplate(string(tpl)); err != nil {
+		return "", perrs.Annotatef(err, "invalid systemd unit template %s", fname)
+	}
+	return string(tpl), nil
+}
+
+// renderSystemdUnits re-renders the systemd units of the instances matching
+// the roles and nodes in gOpt with the template
+func (m *Manager) renderSystemdUnits(ctx context.Context, name string, topo spec.Topology, user string, gOpt operator.Options, tpl string) error {
+	roleFilter := set.NewStringSet(gOpt.Roles...)
+	nodeFilter := set.NewStringSet(gOpt.Nodes...)
+	opt := *topo.BaseTopo().GlobalOptions
+
+	errg, _ := errgroup.WithContext(ctx)
+	for _, comp := range operator.FilterComponent(topo.ComponentsByStartOrder(), roleFilter) {
+		for _, inst := range operator.FilterInstance(comp.Instances(), nodeFilter) {
+			inst := inst
+			// the checkpoint part of context can't be shared between goroutines
+			nctx := checkpoint.NewContext(ctx)
+			errg.Go(func() error {
+				m.logger.Infof("\tRender systemd unit of %s", inst.ID())
+				e := ctxt.GetInner(nctx).Get(inst.GetManageHost())
+				paths := meta.DirPaths{
+					Deploy: spec.Abs(user, inst.DeployDir()),
+					Cache:  m.specManager.Path(name, spec.TempConfigPath),
+				}
+				return inst.RenderSystemdUnit(nctx, e, opt, user, paths, tpl)
+			})
+		}
+	}
+	return errg.Wait()
+}
+
 // StartCluster start the cluster with specified name.
+// Set gOpt.Result to collect the outcome of every instance, it's populated
+// even when an error is returned.
 func (m *Manager) StartCluster(name string, gOpt operator.Options, restoreLeader bool, fn ...func(b *task.Builder, metadata spec.Metadata)) error {
 	m.logger.Infof("Starting cluster %s...", name)
 
@@ -113,11 +175,34 @@ func (m *Manager) StartCluster(name string, gOpt operator.Options, restoreLeader
 		return err
 	}
 
+	if err := m.applyOnlyFailed(name, "start", &gOpt); err != nil {
+		return err
+	}
+	excludeHint, excludedAll := exclusionHint(topo, gOpt)
+	if excludedAll {
+		m.logger.Infof("All the selected instances of cluster %s are excluded, nothing to start", name)
+		return nil
+	}
+	labelHint, err := labelSelectionHint(topo, gOpt)
+	if err != nil {
+		return err
+	}
+	labelHint += excludeHint
+	if labelHint != "" {
+		m.logger.Infof("%s", strings.TrimPrefix(labelHint, "\n"))
+	}
+
+	saveResult := m.trackResult(name, "start", topo, &gOpt)
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
 		return err
 	}
 
+	if gOpt.VerifyBinary {
+		b.Func("VerifyBinaryChecksums", func(ctx context.Context) error {
+			return verifyBinaryChecksums(ctx, m.logger, base, selectedInstances(topo, gOpt))
+		})
+	}
 	b.Func("StartCluster", func(ctx context.Context) error {
 		return operator.Start(ctx, topo, gOpt, restoreLeader, tlsCfg)
 	})
@@ -126,14 +211,48 @@ func (m *Manager) StartCluster(name string, gOpt operator.Options, restoreLeader
 		f(b, metadata)
 	}
 
+	if gOpt.WaitHealthy {
+		b.Func("WaitClusterHealthy", func(ctx context.Context) error {
+			m.logger.Infof("Waiting for cluster %s to be healthy...", name)
+			_, err := waitClusterHealthy(ctx, topo, gOpt, tlsCfg, time.Duration(gOpt.OptTimeout)*time.Second)
+			return err
+		})
+	}
+
+	if gOpt.PostStartVerify != nil {
+		b.Func("VerifyCluster", func(ctx context.Context) error {
+			if err := gOpt.PostStartVerify(ctx, topo); err != nil {
+				return perrs.Annotatef(err, "post-start verification of cluster `%s` failed", name)
+			}
+			return nil
+		})
+	}
+
 	t := b.Build()
 
+	// the deadline is checked by the waits of instances, so they stop
+	// promptly instead of running out their own timeouts
+	opCtx := context.Background()
+	if gOpt.OperationTimeout > 0 {
+		var cancel context.CancelFunc
+		opCtx, cancel = context.WithTimeout(opCtx, time.Duration(gOpt.OperationTimeout)*time.Second)
+		defer cancel()
+	}
 	ctx := ctxt.New(
-		context.Background(),
+		opCtx,
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "start"),
 	)
-	if err := t.Execute(ctx); err != nil {
+	if m.dryRun("start", t, gOpt) {
+		return operator.Start(ctx, topo, gOpt, restoreLeader, tlsCfg)
+	}
+	err = t.Execute(ctx)
+	saveResult()
+	if err != nil {
+		if errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+			return perrs.Errorf("starting cluster `%s` is aborted as it does not finish within the operation timeout of %ds: %s",
+				name, gOpt.OperationTimeout, err)
+		}
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
 			return err
@@ -145,7 +264,7 @@ func (m *Manager) StartCluster(name string, gOpt operator.Options, restoreLeader
 	return nil
 }
 
-// StopCluster stop the cluster.
+// StopCluster stop the cluster, the outcome of instances is collected to gOpt.Result if it's set.
 func (m *Manager) StopCluster(
 	name string,
 	gOpt operator.Options,
@@ -170,23 +289,68 @@ func (m *Manager) StopCluster(
 		return err
 	}
 
+	if err := m.checkProtected(name, "stop", base, gOpt); err != nil {
+		return err
+	}
+	if err := m.checkMaintenanceWindow(name, "stop", base, gOpt); err != nil {
+		return err
+	}
+	if err := m.applyOnlyFailed(name, "stop", &gOpt); err != nil {
+		return err
+	}
+	if err := m.checkLastPD(topo, gOpt, tlsCfg); err != nil {
+		return err
+	}
+	stopOrder, uncovered, err := operator.StopOrder(
+		operator.FilterComponent(topo.ComponentsByStopOrder(), set.NewStringSet(gOpt.Roles...)),
+		gOpt.StopOrder,
+	)
+	if err != nil {
+		return err
+	}
+	if len(gOpt.StopOrder) > 0 && len(uncovered) > 0 {
+		m.logger.Warnf("Components not in the stop order are stopped in the default order after them: %s", strings.Join(uncovered, ", "))
+	}
+
+	excludeHint, excludedAll := exclusionHint(topo, gOpt)
+	if excludedAll {
+		m.logger.Infof("All the selected instances of cluster %s are excluded, nothing to stop", name)
+		return nil
+	}
+	labelHint, err := labelSelectionHint(topo, gOpt)
+	if err != nil {
+		return err
+	}
+	labelHint += excludeHint
+
+	m.showBanner(base)
 	if !skipConfirm {
 		if err := tui.PromptForConfirmOrAbortError(
-			fmt.Sprintf("Will stop the cluster %s with nodes: %s, roles: %s.\nDo you want to continue? [y/N]:",
+			fmt.Sprintf("Will stop the cluster %s with nodes: %s, roles: %s, in order: %s.%s\nDo you want to continue? [y/N]:",
 				color.HiYellowString(name),
 				color.HiRedString(strings.Join(gOpt.Nodes, ",")),
 				color.HiRedString(strings.Join(gOpt.Roles, ",")),
+				color.HiYellowString(strings.Join(operator.ComponentNames(stopOrder), ",")),
+				labelHint,
 			),
 		); err != nil {
 			return err
 		}
 	}
 
+	saveResult := m.trackResult(name, "stop", topo, &gOpt)
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
 		return err
 	}
 
+	if gOpt.SilenceAlerts {
+		b.Func("SilenceAlerts", func(ctx context.Context) error {
+			// the instances stay stopped, so the silence is kept until it expires
+			_, err := m.silenceAlerts(name, topo, gOpt, fmt.Sprintf("%s stop %s", tui.OsArgs0(), name))
+			return err
+		})
+	}
 	t := b.
 		Func("StopCluster", func(ctx context.Context) error {
 			return operator.Stop(ctx, topo, gOpt, evictLeader, tlsCfg)
@@ -196,9 +360,14 @@ func (m *Manager) StopCluster(
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "stop"),
 	)
-	if err := t.Execute(ctx); err != nil {
+	if m.dryRun("stop", t, gOpt) {
+		return operator.Stop(ctx, topo, gOpt, evictLeader, tlsCfg)
+	}
+	err = t.Execute(ctx)
+	saveResult()
+	if err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
 			return err
@@ -210,7 +379,7 @@ func (m *Manager) StopCluster(
 	return nil
 }
 
-// RestartCluster restart the cluster.
+// RestartCluster restart the cluster, the outcome of instances is collected to gOpt.Result if it's set.
 func (m *Manager) RestartCluster(name string, gOpt operator.Options, skipConfirm bool) error {
 	// check locked
 	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
@@ -230,22 +399,95 @@ func (m *Manager) RestartCluster(name string, gOpt operator.Options, skipConfirm
 		return err
 	}
 
+	if len(gOpt.ConfigOverrides) > 0 {
+		if err := applyConfigOverrides(topo, gOpt.ConfigOverrides); err != nil {
+			return err
+		}
+	}
+
+	if err := m.checkProtected(name, "restart", base, gOpt); err != nil {
+		return err
+	}
+	if err := m.checkMaintenanceWindow(name, "restart", base, gOpt); err != nil {
+		return err
+	}
+	if err := m.applyOnlyFailed(name, "restart", &gOpt); err != nil {
+		return err
+	}
+	excludeHint, excludedAll := exclusionHint(topo, gOpt)
+	if excludedAll {
+		m.logger.Infof("All the selected instances of cluster %s are excluded, nothing to restart", name)
+		return nil
+	}
+	labelHint, err := labelSelectionHint(topo, gOpt)
+	if err != nil {
+		return err
+	}
+	labelHint += excludeHint
+
+	m.showBanner(base)
 	if !skipConfirm {
 		if err := tui.PromptForConfirmOrAbortError(
-			fmt.Sprintf("Will restart the cluster %s with nodes: %s roles: %s.\nCluster will be unavailable\nDo you want to continue? [y/N]:",
+			fmt.Sprintf("Will restart the cluster %s with nodes: %s roles: %s.%s\nCluster will be unavailable\nDo you want to continue? [y/N]:",
 				color.HiYellowString(name),
 				color.HiYellowString(strings.Join(gOpt.Nodes, ",")),
 				color.HiYellowString(strings.Join(gOpt.Roles, ",")),
+				labelHint,
 			),
 		); err != nil {
 			return err
 		}
 	}
 
+	if gOpt.RestartBatch > 0 || len(gOpt.RestartBatchRoles) > 0 {
+		gOpt.RestartBatchGate = func(ctx context.Context, topo spec.Topology, nodes []string) error {
+			batchOpt := gOpt
+			batchOpt.Roles = nil
+			batchOpt.Nodes = nodes
+			_, err := waitClusterHealthy(ctx, topo, batchOpt, tlsCfg, time.Duration(gOpt.OptTimeout)*time.Second)
+			return err
+		}
+	}
+
+	saveResult := m.trackResult(name, "restart", topo, &gOpt)
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
 		return err
 	}
+	if gOpt.VerifyBinary {
+		b.Func("VerifyBinaryChecksums", func(ctx context.Context) error {
+			return verifyBinaryChecksums(ctx, m.logger, base, selectedInstances(topo, gOpt))
+		})
+	}
+	liftSilence := func() error { return nil }
+	if gOpt.SilenceAlerts {
+		b.Func("SilenceAlerts", func(ctx context.Context) error {
+			lift, err := m.silenceAlerts(name, topo, gOpt, fmt.Sprintf("%s restart %s", tui.OsArgs0(), name))
+			if err == nil {
+				liftSilence = lift
+			}
+			return err
+		})
+	}
+	if len(gOpt.ConfigOverrides) > 0 {
+		// only the restarted instances get the overrides, the others keep the
+		// config they are running with
+		var refreshConfigTasks []*task.StepDisplay
+		hasImported := false
+		for _, inst := range selectedInstances(topo, gOpt) {
+			tb, imported := buildInitConfigTask(m, name, inst, base, gOpt)
+			hasImported = hasImported || imported
+			refreshConfigTasks = append(refreshConfigTasks,
+				tb.BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", inst.ComponentName(), inst.ID())))
+		}
+		// handle dir scheme changes
+		if hasImported {
+			if err := spec.HandleImportPathMigration(name); err != nil {
+				return err
+			}
+		}
+		b.ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...)
+	}
 	t := b.
 		Func("RestartCluster", func(ctx context.Context) error {
 			return operator.Restart(ctx, topo, gOpt, tlsCfg)
@@ -255,15 +497,24 @@ func (m *Manager) RestartCluster(name string, gOpt operator.Options, skipConfirm
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "restart"),
 	)
-	if err := t.Execute(ctx); err != nil {
+	if m.dryRun("restart", t, gOpt) {
+		return operator.Restart(ctx, topo, gOpt, tlsCfg)
+	}
+	err = t.Execute(ctx)
+	saveResult()
+	if err != nil {
+		// keep the silence until it expires, some instances may be still down
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
 			return err
 		}
 		return perrs.Trace(err)
 	}
+	if err := liftSilence(); err != nil {
+		m.logger.Warnf("%s", err)
+	}
 
 	m.logger.Infof("Restarted cluster `%s` successfully", name)
 	return nil
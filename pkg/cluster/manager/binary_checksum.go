@@ -0,0 +1,161 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"golang.org/x/sync/errgroup"
+)
+
+// instanceBinaries are the paths of the binaries of the components relative to
+// the bin dir, as run by the scripts. The other files there, e.g. the libs or
+// the leftovers of old versions, are not hashed, and the components not listed
+// are not verified.
+var instanceBinaries = map[string]string{
+	spec.ComponentPD:           "pd-server",
+	spec.ComponentTiKV:         "tikv-server",
+	spec.ComponentTiDB:         "tidb-server",
+	spec.ComponentTiFlash:      "tiflash/tiflash",
+	spec.ComponentTiProxy:      "tiproxy",
+	spec.ComponentDashboard:    "tidb-dashboard",
+	spec.ComponentPump:         "pump",
+	spec.ComponentDrainer:      "drainer",
+	spec.ComponentCDC:          "cdc",
+	spec.ComponentTiKVCDC:      "tikv-cdc",
+	spec.ComponentPrometheus:   "prometheus/prometheus",
+	spec.ComponentGrafana:      "bin/grafana-server",
+	spec.ComponentAlertmanager: "alertmanager/alertmanager",
+	spec.ComponentDMMaster:     "dm-master/dm-master",
+	spec.ComponentDMWorker:     "dm-worker/dm-worker",
+}
+
+// binaryChecksumCommand hashes the binary of an instance
+func binaryChecksumCommand(deployDir, binary string) string {
+	return fmt.Sprintf("sha256sum %s | cut -d ' ' -f 1", shellQuote(filepath.Join(deployDir, "bin", binary)))
+}
+
+// shellQuote quotes s as a single argument of shell commands
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// binaryChecksums gets the checksums of the binaries of the instances in parallel
+func binaryChecksums(ctx context.Context, insts []spec.Instance, user string) (map[string]string, error) {
+	var mu sync.Mutex
+	sums := make(map[string]string, len(insts))
+	errg, _ := errgroup.WithContext(ctx)
+	for _, inst := range insts {
+		inst := inst
+		binary, ok := instanceBinaries[inst.ComponentName()]
+		if !ok {
+			continue
+		}
+		errg.Go(func() error {
+			e, found := ctxt.GetInner(ctx).GetExecutor(inst.GetManageHost())
+			if !found {
+				return perrs.Errorf("no executor for %s", inst.GetManageHost())
+			}
+			stdout, stderr, err := e.Execute(ctx, binaryChecksumCommand(spec.Abs(user, inst.DeployDir()), binary), false)
+			if err != nil {
+				return perrs.Annotatef(err, "failed to get the binary checksum of %s: %s", inst.ID(), stderr)
+			}
+			mu.Lock()
+			sums[inst.ID()] = strings.TrimSpace(string(stdout))
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := errg.Wait(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// recordBinaryChecksums saves the checksums of the binaries of the instances
+// to the metadata, so they could be verified before starting later. It's
+// best-effort, the instances failed are left unrecorded with a warning.
+func recordBinaryChecksums(ctx context.Context, logger *logprinter.Logger, base *spec.BaseMeta, insts []spec.Instance) {
+	if base.BinaryChecksums == nil || len(insts) == 0 {
+		return
+	}
+	if *base.BinaryChecksums == nil {
+		*base.BinaryChecksums = make(map[string]string)
+	}
+	for _, inst := range insts {
+		delete(*base.BinaryChecksums, inst.ID())
+	}
+	sums, err := binaryChecksums(ctx, insts, base.User)
+	if err != nil {
+		logger.Warnf("Failed to record the binary checksums, they won't be verified: %s", err)
+		return
+	}
+	for id, sum := range sums {
+		(*base.BinaryChecksums)[id] = sum
+	}
+}
+
+// verifyBinaryChecksums checks the binaries of the instances against the
+// checksums recorded, the instances without checksums recorded are skipped
+func verifyBinaryChecksums(ctx context.Context, logger *logprinter.Logger, base *spec.BaseMeta, insts []spec.Instance) error {
+	var expected map[string]string
+	if base.BinaryChecksums != nil {
+		expected = *base.BinaryChecksums
+	}
+
+	var toVerify []spec.Instance
+	for _, inst := range insts {
+		if _, ok := expected[inst.ID()]; !ok {
+			logger.Warnf("No binary checksum of %s is recorded, skip verifying it", inst.ID())
+			continue
+		}
+		toVerify = append(toVerify, inst)
+	}
+	if len(toVerify) == 0 {
+		return nil
+	}
+
+	logger.Infof("Verifying the binaries of %d instance(s)...", len(toVerify))
+	actual, err := binaryChecksums(ctx, toVerify, base.User)
+	if err != nil {
+		return err
+	}
+	if mismatched := mismatchedBinaryChecksums(expected, actual); len(mismatched) > 0 {
+		return perrs.Errorf("the binaries of %s do not match the checksums recorded when deployed, they may be corrupted, please patch them with the original packages",
+			strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// mismatchedBinaryChecksums returns the sorted IDs of the instances whose
+// actual checksums differ from the expected ones
+func mismatchedBinaryChecksums(expected, actual map[string]string) []string {
+	var mismatched []string
+	for id, sum := range actual {
+		if expected[id] != sum {
+			mismatched = append(mismatched, id)
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched
+}
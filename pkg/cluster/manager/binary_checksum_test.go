@@ -0,0 +1,97 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryChecksums(t *testing.T) {
+	assert := require.New(t)
+
+	expected := map[string]string{
+		"172.16.5.1:4000":  "aaa",
+		"172.16.5.2:20160": "bbb",
+		"172.16.5.3:20160": "ccc",
+	}
+	assert.Empty(mismatchedBinaryChecksums(expected, map[string]string{
+		"172.16.5.1:4000":  "aaa",
+		"172.16.5.2:20160": "bbb",
+	}))
+	assert.Equal([]string{"172.16.5.1:4000", "172.16.5.3:20160"}, mismatchedBinaryChecksums(expected, map[string]string{
+		"172.16.5.3:20160": "xxx",
+		"172.16.5.2:20160": "bbb",
+		"172.16.5.1:4000":  "",
+	}))
+
+	// the instances without checksums recorded are skipped without connecting to them
+	meta := &spec.ClusterMeta{User: "tidb", Topology: &spec.Specification{
+		TiDBServers: []*spec.TiDBSpec{{Host: "172.16.5.1", Port: 4000}},
+	}}
+	insts := selectedInstances(meta.Topology, operator.Options{})
+	assert.Len(insts, 1)
+	assert.Nil(verifyBinaryChecksums(context.Background(), logprinter.NewLogger(""), meta.GetBaseMeta(), insts))
+	recordBinaryChecksums(context.Background(), logprinter.NewLogger(""), meta.GetBaseMeta(), nil)
+	assert.Nil(meta.BinaryChecksums)
+}
+
+func TestBinaryChecksumCommand(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(`sha256sum '/home/tidb/deploy/tidb-4000/bin/tidb-server' | cut -d ' ' -f 1`,
+		binaryChecksumCommand("/home/tidb/deploy/tidb-4000", instanceBinaries[spec.ComponentTiDB]))
+	assert.Equal(`sha256sum '/data/it'\''s dir/bin/tiflash/tiflash' | cut -d ' ' -f 1`,
+		binaryChecksumCommand("/data/it's dir", instanceBinaries[spec.ComponentTiFlash]))
+}
+
+// shellExecutor runs the commands with the local shell
+type shellExecutor struct{}
+
+func (shellExecutor) Execute(ctx context.Context, cmd string, _ bool, _ ...time.Duration) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err := c.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+func (shellExecutor) Transfer(context.Context, string, string, bool, int, bool) error {
+	return nil
+}
+
+func TestDeployDirCommandsQuoted(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(`cat '/data/it'\''s dir'/conf/* '/data/it'\''s dir'/scripts/* 2>/dev/null | sha256sum`,
+		configChecksumCommand("/data/it's dir"))
+
+	dir := filepath.Join(t.TempDir(), "it's dir")
+	assert.Nil(os.MkdirAll(filepath.Join(dir, "scripts"), 0755))
+	assert.Nil(os.WriteFile(filepath.Join(dir, "scripts", "run_tidb.sh"), []byte("--port 4000\n"), 0644))
+	exists, mentioned, err := inspectDeployDir(context.Background(), shellExecutor{}, dir, []int{4000, 10080})
+	assert.Nil(err)
+	assert.True(exists)
+	assert.Equal([]string{"4000"}, mentioned.Slice())
+}
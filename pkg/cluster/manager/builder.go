@@ -337,7 +337,11 @@ func buildScaleOutTask(
 			ParallelStep("+ Deploy TiDB instance", gOpt.Force, deployCompTasks...).
 			ParallelStep("+ Copy certificate to remote host", gOpt.Force, certificateTasks...).
 			ParallelStep("+ Generate scale-out config", gOpt.Force, scaleOutConfigTasks...).
-			ParallelStep("+ Init monitor config", gOpt.Force, monitorConfigTasks...)
+			ParallelStep("+ Init monitor config", gOpt.Force, monitorConfigTasks...).
+			Func("RecordBinaryChecksums", func(ctx context.Context) error {
+				recordBinaryChecksums(ctx, m.logger, base, selectedInstances(newPart, operator.Options{}))
+				return nil
+			})
 	}
 
 	if afterDeploy != nil {
@@ -353,7 +357,7 @@ func buildScaleOutTask(
 	if opt.Stage1 {
 		// save scale out file lock
 		builder.Func("Create scale-out file lock", func(_ context.Context) error {
-			return m.specManager.NewScaleOutLock(name, newPart)
+			return m.specManager.NewScaleOutLock(name, newPart, m.clock.Now())
 		})
 	} else {
 		builder.Func("Start new instances", func(ctx context.Context) error {
@@ -641,55 +645,69 @@ func buildInitConfigTasks(
 		if deletedNodes.Exist(instance.ID()) {
 			return
 		}
-		compName := instance.ComponentName()
-		deployDir := spec.Abs(base.User, instance.DeployDir())
-		// data dir would be empty for components which don't need it
-		dataDirs := spec.MultiDirAbs(base.User, instance.DataDir())
-		// log dir will always be with values, but might not used by the component
-		logDir := spec.Abs(base.User, instance.LogDir())
-
-		// Download and copy the latest component to remote if the cluster is imported from Ansible
-		tb := task.NewBuilder(m.logger)
-		if instance.IsImported() {
-			version := instance.CalculateVersion(base.Version)
-			switch compName {
-			case spec.ComponentGrafana, spec.ComponentPrometheus, spec.ComponentAlertmanager:
-				tb.Download(compName, instance.OS(), instance.Arch(), version).
-					CopyComponent(
-						compName,
-						instance.OS(),
-						instance.Arch(),
-						version,
-						"", // use default srcPath
-						instance.GetManageHost(),
-						deployDir,
-					)
-			}
-			hasImported = true
-		}
-
-		t := tb.
-			InitConfig(
-				name,
-				base.Version,
-				m.specManager,
-				instance,
-				base.User,
-				gOpt.IgnoreConfigCheck,
-				meta.DirPaths{
-					Deploy: deployDir,
-					Data:   dataDirs,
-					Log:    logDir,
-					Cache:  m.specManager.Path(name, spec.TempConfigPath),
-				},
-			).
-			BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", compName, instance.ID()))
+		tb, imported := buildInitConfigTask(m, name, instance, base, gOpt)
+		hasImported = hasImported || imported
+		t := tb.BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", instance.ComponentName(), instance.ID()))
 		tasks = append(tasks, t)
 	})
 
 	return tasks, hasImported
 }
 
+// buildInitConfigTask builds the task generating and pushing the config of
+// the instance, it also tells whether the instance is imported from Ansible
+func buildInitConfigTask(
+	m *Manager,
+	name string,
+	instance spec.Instance,
+	base *spec.BaseMeta,
+	gOpt operator.Options,
+) (*task.Builder, bool) {
+	hasImported := false
+	compName := instance.ComponentName()
+	deployDir := spec.Abs(base.User, instance.DeployDir())
+	// data dir would be empty for components which don't need it
+	dataDirs := spec.MultiDirAbs(base.User, instance.DataDir())
+	// log dir will always be with values, but might not used by the component
+	logDir := spec.Abs(base.User, instance.LogDir())
+
+	// Download and copy the latest component to remote if the cluster is imported from Ansible
+	tb := task.NewBuilder(m.logger)
+	if instance.IsImported() {
+		version := instance.CalculateVersion(base.Version)
+		switch compName {
+		case spec.ComponentGrafana, spec.ComponentPrometheus, spec.ComponentAlertmanager:
+			tb.Download(compName, instance.OS(), instance.Arch(), version).
+				CopyComponent(
+					compName,
+					instance.OS(),
+					instance.Arch(),
+					version,
+					"", // use default srcPath
+					instance.GetManageHost(),
+					deployDir,
+				)
+		}
+		hasImported = true
+	}
+
+	tb.InitConfig(
+		name,
+		base.Version,
+		m.specManager,
+		instance,
+		base.User,
+		gOpt.IgnoreConfigCheck,
+		meta.DirPaths{
+			Deploy: deployDir,
+			Data:   dataDirs,
+			Log:    logDir,
+			Cache:  m.specManager.Path(name, spec.TempConfigPath),
+		},
+	)
+	return tb, hasImported
+}
+
 // buildDownloadCompTasks build download component tasks
 func buildDownloadCompTasks(
 	clusterVersion string,
@@ -746,7 +764,8 @@ func buildTLSTask(
 	base := metadata.GetBaseMeta()
 
 	//  load certificate file
-	if topo.BaseTopo().GlobalOptions.TLSEnabled {
+	// the certificates are not generated in dry run mode
+	if topo.BaseTopo().GlobalOptions.TLSEnabled && !gOpt.DryRun {
 		tlsDir := m.specManager.Path(name, spec.TLSCertKeyDir)
 		m.logger.Infof("Generate certificate: %s", color.YellowString(tlsDir))
 		if err := m.loadCertificate(name, topo.BaseTopo().GlobalOptions, reloadCertificate); err != nil {
@@ -815,7 +834,7 @@ func buildTLSTask(
 	// cleanup tls files only in tls disable
 	if !topo.BaseTopo().GlobalOptions.TLSEnabled {
 		builder.Func("Cleanup TLS files", func(ctx context.Context) error {
-			return operator.CleanupComponent(ctx, delFileMap, topo.BaseTopo().GlobalOptions.SystemdMode != spec.UserMode)
+			return operator.CleanupComponent(ctx, delFileMap, topo.BaseTopo().GlobalOptions.SystemdMode != spec.UserMode, operator.CleanupFilter{})
 		})
 	}
 
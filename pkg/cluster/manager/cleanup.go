@@ -15,11 +15,18 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/docker/go-units"
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
 	perrs "github.com/pingcap/errors"
@@ -30,8 +37,13 @@ import (
 	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
 	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/sync/errgroup"
 )
 
+// cleanupEstimateTimeout is the max time to wait for `du` on a host
+const cleanupEstimateTimeout = 30 * time.Second
+
 // CleanCluster cleans the cluster without destroying it
 func (m *Manager) CleanCluster(name string, gOpt operator.Options, cleanOpt operator.Options, skipConfirm bool) error {
 	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
@@ -51,16 +63,77 @@ func (m *Manager) CleanCluster(name string, gOpt operator.Options, cleanOpt oper
 	topo := metadata.GetTopology()
 	base := metadata.GetBaseMeta()
 
+	if !cleanOpt.DryRun {
+		if err := m.checkProtected(name, "clean", base, gOpt); err != nil {
+			return err
+		}
+		confirmed, err := m.checkConfirmToken(name, "clean", gOpt)
+		if err != nil {
+			return err
+		}
+		skipConfirm = skipConfirm || confirmed
+	}
+
 	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
 	if err != nil {
 		return err
 	}
 	// calculate file paths to be deleted before the prompt
-	delFileMap := getCleanupFiles(topo,
-		cleanOpt.CleanupData, cleanOpt.CleanupLog, false, cleanOpt.CleanupAuditLog, cleanOpt.RetainDataRoles, cleanOpt.RetainDataNodes)
+	if err := checkDataOnlyDirs(topo, cleanOpt); err != nil {
+		return err
+	}
+	delFileMap := getCleanupFiles(topo, cleanupFiles{
+		cleanupData:     cleanOpt.CleanupData,
+		cleanupLog:      cleanOpt.CleanupLog,
+		cleanupAuditLog: cleanOpt.CleanupAuditLog,
+		cleanupCrashes:  cleanOpt.CleanupCrashes,
+		extraPaths:      cleanOpt.CleanupPaths,
+		retainDataRoles: cleanOpt.RetainDataRoles,
+		retainDataNodes: cleanOpt.RetainDataNodes,
+		dataOnlyRoles:   cleanOpt.DataOnlyRoles,
+		dataOnlyNodes:   cleanOpt.DataOnlyNodes,
+	})
+
+	// the data dirs are cleaned by globbing their content, which would wipe the
+	// shared storage if the dir is a symlink to it
+	var symlinks map[string]map[string]string
+	if cleanOpt.CleanupData {
+		symlinks, err = m.symlinkedCleanupDirs(name, topo, base.User, gOpt, delFileMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	var sizes map[string]int64
+	if cleanOpt.EstimateSize {
+		sizes = m.estimateCleanupSize(name, topo, base.User, gOpt, delFileMap, cleanOpt.CleanupFilter())
+	}
+	var ages map[string]cleanupAges
+	if cleanOpt.AgeReport {
+		ages = m.scanCleanupAges(name, topo, base.User, gOpt, delFileMap, cleanOpt.CleanupFilter())
+	}
+
+	if cleanOpt.PlanFile != "" {
+		if err := writeCleanupPlan(cleanOpt.PlanFile, name, cleanOpt, delFileMap); err != nil {
+			return err
+		}
+		m.logger.Infof("The files to be deleted are written to %s", cleanOpt.PlanFile)
+	}
+
+	if cleanOpt.DryRun {
+		m.logger.Infof("%s", cleanupPlan(name, cleanOpt, delFileMap, symlinks, sizes, ages))
+		return nil
+	}
+
+	if len(symlinks) > 0 && !cleanOpt.FollowSymlinks {
+		return errorCleanupSymlinked.
+			New("Data directories to be cleaned up are symlinks:%s", formatSymlinks(symlinks)).
+			WithProperty(tui.SuggestionFromString(
+				"The content of the link targets will be deleted, please make sure they are not shared with others and add `--follow-symlinks` to continue"))
+	}
 
 	if !skipConfirm {
-		if err := cleanupConfirm(m.logger, name, m.sysName, base.Version, cleanOpt, delFileMap); err != nil {
+		if err := cleanupConfirm(m.logger, name, m.sysName, base.Version, cleanOpt, delFileMap, symlinks, sizes, ages); err != nil {
 			return err
 		}
 	}
@@ -86,14 +159,14 @@ func (m *Manager) CleanCluster(name string, gOpt operator.Options, cleanOpt oper
 			)
 		}).
 		Func("CleanupCluster", func(ctx context.Context) error {
-			return operator.CleanupComponent(ctx, delFileMap, sudo)
+			return operator.CleanupComponent(ctx, delFileMap, sudo, cleanOpt.CleanupFilter())
 		}).
 		Build()
 
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "clean"),
 	)
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
@@ -108,32 +181,487 @@ func (m *Manager) CleanCluster(name string, gOpt operator.Options, cleanOpt oper
 }
 
 // checkConfirm
-func cleanupConfirm(logger *logprinter.Logger, clusterName, sysName, version string, cleanOpt operator.Options, delFileMap map[string]set.StringSet) error {
+func cleanupConfirm(
+	logger *logprinter.Logger,
+	clusterName, sysName, version string,
+	cleanOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+	symlinks map[string]map[string]string,
+	sizes map[string]int64,
+	ages map[string]cleanupAges,
+) error {
 	logger.Warnf("The clean operation will %s %s %s cluster `%s`",
 		color.HiYellowString("stop"), sysName, version, color.HiYellowString(clusterName))
 	if err := tui.PromptForConfirmOrAbortError("Do you want to continue? [y/N]:"); err != nil {
 		return err
 	}
 
-	// build file list string
-	delFileList := ""
+	logger.Warnf("%s", cleanupPlan(clusterName, cleanOpt, delFileMap, symlinks, sizes, ages))
+	return tui.PromptForConfirmOrAbortError("Do you want to continue? [y/N]:")
+}
+
+// cleanupPlan describes what will be deleted by the clean operation
+func cleanupPlan(
+	clusterName string,
+	cleanOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+	symlinks map[string]map[string]string,
+	sizes map[string]int64,
+	ages map[string]cleanupAges,
+) string {
+	plan := fmt.Sprintf("Clean the clutser %s's%s.\nNodes will be ignored: %s\nRoles will be ignored: %s\nFiles to be deleted are: %s",
+		color.HiYellowString(clusterName), cleanTarget(cleanOpt), cleanOpt.RetainDataNodes,
+		cleanOpt.RetainDataRoles,
+		formatCleanupFiles(delFileMap, symlinks, sizes))
+	if len(cleanOpt.DataOnlyRoles) > 0 || len(cleanOpt.DataOnlyNodes) > 0 {
+		plan = fmt.Sprintf("%s\nOnly data will be cleaned and deploy dirs kept for roles: %s, nodes: %s",
+			plan, cleanOpt.DataOnlyRoles, cleanOpt.DataOnlyNodes)
+	}
+	if sizes != nil {
+		plan += "\n" + formatCleanupSize(sizes)
+	}
+	if ages != nil {
+		plan += "\n" + formatCleanupAges(ages)
+	}
+	return plan
+}
+
+// hostCleanupFiles is the sorted list of files to be deleted on a host
+type hostCleanupFiles struct {
+	Host  string   `json:"host"`
+	Paths []string `json:"paths"`
+}
+
+// cleanupPlanFile is the cleanup plan written for review, the paths are
+// deleted as filtered by the age and exclusions if set
+type cleanupPlanFile struct {
+	Cluster   string             `json:"cluster"`
+	OlderThan string             `json:"older_than,omitempty"`
+	Exclude   []string           `json:"exclude,omitempty"`
+	Hosts     []hostCleanupFiles `json:"hosts"`
+}
+
+// writeCleanupPlan writes the files to be deleted to the path, as JSON if the
+// path ends with .json, or as lines of host and path separated by a tab
+func writeCleanupPlan(path, clusterName string, cleanOpt operator.Options, delFileMap map[string]set.StringSet) error {
+	plan := cleanupPlanFile{
+		Cluster: clusterName,
+		Exclude: cleanOpt.ExcludeData,
+		Hosts:   sortedCleanupFiles(delFileMap),
+	}
+	if cleanOpt.OlderThan > 0 {
+		plan.OlderThan = cleanOpt.OlderThan.String()
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(plan, "", "  "); err != nil {
+			return perrs.Trace(err)
+		}
+		data = append(data, '\n')
+	} else {
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "# cluster: %s\n", plan.Cluster)
+		if plan.OlderThan != "" {
+			fmt.Fprintf(&buf, "# older than: %s\n", plan.OlderThan)
+		}
+		if len(plan.Exclude) > 0 {
+			fmt.Fprintf(&buf, "# exclude: %s\n", strings.Join(plan.Exclude, ","))
+		}
+		for _, hf := range plan.Hosts {
+			for _, p := range hf.Paths {
+				fmt.Fprintf(&buf, "%s\t%s\n", hf.Host, p)
+			}
+		}
+		data = []byte(buf.String())
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return perrs.Annotatef(err, "failed to write the cleanup plan to %s", path)
+	}
+	return nil
+}
+
+// sortedCleanupFiles returns the cleanup plan sorted by host and path, so that
+// it is presented the same way between runs, hosts without files are omitted
+func sortedCleanupFiles(delFileMap map[string]set.StringSet) []hostCleanupFiles {
+	plan := make([]hostCleanupFiles, 0, len(delFileMap))
 	for host, fileList := range delFileMap {
 		// target host has no files to delete
 		if len(fileList) == 0 {
 			continue
 		}
+		paths := fileList.Slice()
+		sort.Strings(paths)
+		plan = append(plan, hostCleanupFiles{Host: host, Paths: paths})
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		return plan[i].Host < plan[j].Host
+	})
+	return plan
+}
 
-		delFileList += fmt.Sprintf("\n%s:", color.CyanString(host))
-		for _, dfp := range fileList.Slice() {
+// formatCleanupFiles builds the file list string of the cleanup plan, paths
+// inside symlinked dirs are marked with the link target, and hosts with the
+// estimated size if known
+func formatCleanupFiles(delFileMap map[string]set.StringSet, symlinks map[string]map[string]string, sizes map[string]int64) string {
+	delFileList := ""
+	for _, hf := range sortedCleanupFiles(delFileMap) {
+		delFileList += fmt.Sprintf("\n%s:", color.CyanString(hf.Host))
+		if size, ok := sizes[hf.Host]; ok {
+			delFileList += fmt.Sprintf(" (~%s)", units.BytesSize(float64(size)))
+		}
+		for _, dfp := range hf.Paths {
+			if target, ok := symlinks[hf.Host][dfp]; ok {
+				delFileList += fmt.Sprintf("\n %s %s", dfp, color.HiRedString("(symlink to %s)", target))
+				continue
+			}
 			delFileList += fmt.Sprintf("\n %s", dfp)
 		}
 	}
+	return delFileList
+}
 
-	logger.Warnf("Clean the clutser %s's%s.\nNodes will be ignored: %s\nRoles will be ignored: %s\nFiles to be deleted are: %s",
-		color.HiYellowString(clusterName), cleanTarget(cleanOpt), cleanOpt.RetainDataNodes,
-		cleanOpt.RetainDataRoles,
-		delFileList)
-	return tui.PromptForConfirmOrAbortError("Do you want to continue? [y/N]:")
+// formatCleanupSize summarizes the estimated space to be freed
+func formatCleanupSize(sizes map[string]int64) string {
+	total := int64(0)
+	for _, size := range sizes {
+		total += size
+	}
+	return fmt.Sprintf("This will free ~%s across %d host(s)", color.HiYellowString(units.BytesSize(float64(total))), len(sizes))
+}
+
+// estimateCleanupSize runs `du` on the paths to be deleted of each host in parallel,
+// it is best-effort that hosts failed or timed out are left out of the result
+func (m *Manager) estimateCleanupSize(
+	name string,
+	topo spec.Topology,
+	user string,
+	gOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+	filter operator.CleanupFilter,
+) map[string]int64 {
+	sizes := make(map[string]int64)
+	m.scanCleanupFiles(name, topo, user, gOpt, delFileMap, "estimate the size of files to be deleted",
+		func(paths []string) string {
+			// errors of missing paths are ignored, the total is always the last line
+			if filter.IsZero() {
+				return fmt.Sprintf("du -sck %s 2>/dev/null | tail -n 1", strings.Join(paths, " "))
+			}
+			return fmt.Sprintf("{ %s; } 2>/dev/null | awk '{s += $1} END {print s + 0}'",
+				operator.FindCommand(paths, filter, "-exec du -sk {} +"))
+		},
+		func(host, stdout string) {
+			if size, ok := parseDuTotal(stdout); ok {
+				sizes[host] = size
+			}
+		})
+	return sizes
+}
+
+// cleanupAgeBuckets are the upper bounds of the buckets of the file age histogram,
+// the files older than the last one are counted in an extra bucket
+var cleanupAgeBuckets = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// cleanupAges is the age distribution of the files to be deleted on a host
+type cleanupAges struct {
+	Files   int64
+	Oldest  time.Duration
+	Newest  time.Duration
+	Buckets []int64 // count of files in each of cleanupAgeBuckets and older
+}
+
+// scanCleanupAges finds the modification time of the files to be deleted of each
+// host in parallel, it is best-effort as estimateCleanupSize
+func (m *Manager) scanCleanupAges(
+	name string,
+	topo spec.Topology,
+	user string,
+	gOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+	filter operator.CleanupFilter,
+) map[string]cleanupAges {
+	ages := make(map[string]cleanupAges)
+	m.scanCleanupFiles(name, topo, user, gOpt, delFileMap, "scan the age of files to be deleted",
+		func(paths []string) string {
+			return cleanupAgeCommand(paths, filter)
+		},
+		func(host, stdout string) {
+			if a, ok := parseCleanupAges(stdout); ok {
+				ages[host] = a
+			}
+		})
+	return ages
+}
+
+// cleanupAgeCommand lists the modification time of the files under the paths
+// to be deleted and summarizes their ages by the clock of the host, the output
+// is the count of files, the age of the oldest and newest ones in seconds, and
+// the counts of the buckets
+func cleanupAgeCommand(paths []string, filter operator.CleanupFilter) string {
+	bounds := make([]string, 0, len(cleanupAgeBuckets))
+	for _, b := range cleanupAgeBuckets {
+		bounds = append(bounds, strconv.FormatInt(int64(b/time.Second), 10))
+	}
+	awk := fmt.Sprintf(`awk -v now="$(date +%%s)" -v bounds=%s `+
+		`'BEGIN {k = split(bounds, b, ",")} `+
+		`{a = now - int($1); if (a < 0) a = 0; n++; if (n == 1 || a < min) min = a; if (a > max) max = a; `+
+		`for (i = 1; i <= k && a >= b[i]; i++); c[i]++} `+
+		`END {printf "%%d %%d %%d", n, max, min; for (i = 1; i <= k + 1; i++) printf " %%d", c[i]; print ""}'`,
+		strings.Join(bounds, ","))
+	return fmt.Sprintf("{ %s; } 2>/dev/null | %s",
+		operator.FindCommand(paths, filter, `-exec find {} -type f -printf '%T@\n' ';'`), awk)
+}
+
+// parseCleanupAges parses the output of cleanupAgeCommand
+func parseCleanupAges(output string) (cleanupAges, bool) {
+	fields := strings.Fields(output)
+	if len(fields) != 3+len(cleanupAgeBuckets)+1 {
+		return cleanupAges{}, false
+	}
+	nums := make([]int64, len(fields))
+	for i, f := range fields {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return cleanupAges{}, false
+		}
+		nums[i] = n
+	}
+	return cleanupAges{
+		Files:   nums[0],
+		Oldest:  time.Duration(nums[1]) * time.Second,
+		Newest:  time.Duration(nums[2]) * time.Second,
+		Buckets: nums[3:],
+	}, true
+}
+
+// formatCleanupAges reports the age distribution of the files to be deleted
+// of each host
+func formatCleanupAges(ages map[string]cleanupAges) string {
+	hosts := make([]string, 0, len(ages))
+	for host := range ages {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	labels := make([]string, 0, len(cleanupAgeBuckets)+1)
+	lower := "0"
+	for _, b := range cleanupAgeBuckets {
+		upper := operator.FormatFileAge(b)
+		labels = append(labels, fmt.Sprintf("%s-%s", lower, upper))
+		lower = upper
+	}
+	labels = append(labels, ">"+lower)
+
+	report := "Age of files to be deleted:"
+	for _, host := range hosts {
+		a := ages[host]
+		if a.Files == 0 {
+			report += fmt.Sprintf("\n%s: no files", color.CyanString(host))
+			continue
+		}
+		counts := make([]string, 0, len(a.Buckets))
+		for i, c := range a.Buckets {
+			counts = append(counts, fmt.Sprintf("%s: %d", labels[i], c))
+		}
+		report += fmt.Sprintf("\n%s: %d files, oldest %s, newest %s (%s)", color.CyanString(host), a.Files,
+			color.HiYellowString(formatCleanupAge(a.Oldest)), color.HiYellowString(formatCleanupAge(a.Newest)),
+			strings.Join(counts, ", "))
+	}
+	return report
+}
+
+// formatCleanupAge formats the age of a file in minutes
+func formatCleanupAge(age time.Duration) string {
+	if age < time.Minute {
+		return "<1m"
+	}
+	return formatInstanceSince(age.Truncate(time.Minute))
+}
+
+// scanCleanupFiles runs the command built from the paths to be deleted on each
+// host in parallel and handles its output, it is best-effort that hosts failed
+// or timed out are skipped
+func (m *Manager) scanCleanupFiles(
+	name string,
+	topo spec.Topology,
+	user string,
+	gOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+	what string,
+	command func(paths []string) string,
+	handle func(host, stdout string),
+) {
+	plan := sortedCleanupFiles(delFileMap)
+	if len(plan) == 0 {
+		return
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, user, gOpt)
+	if err != nil {
+		m.logger.Warnf("Failed to %s: %s", what, err)
+		return
+	}
+	sudo := topo.BaseTopo().GlobalOptions.SystemdMode != spec.UserMode
+
+	var mu sync.Mutex
+	t := b.
+		Func("ScanCleanupFiles", func(ctx context.Context) error {
+			errg, _ := errgroup.WithContext(ctx)
+			errg.SetLimit(gOpt.Concurrency)
+			for _, hf := range plan {
+				hf := hf
+				errg.Go(func() error {
+					e, found := ctxt.GetInner(ctx).GetExecutor(hf.Host)
+					if !found {
+						return nil
+					}
+					stdout, _, err := e.Execute(ctx, command(hf.Paths), sudo, cleanupEstimateTimeout)
+					if err != nil {
+						m.logger.Debugf("Failed to %s on %s: %s", what, hf.Host, err)
+						return nil
+					}
+					mu.Lock()
+					handle(hf.Host, string(stdout))
+					mu.Unlock()
+					return nil
+				})
+			}
+			return errg.Wait()
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		m.logger.Warnf("Failed to %s: %s", what, err)
+	}
+}
+
+// parseDuTotal parses the total line of `du -c` output in KiB
+func parseDuTotal(output string) (int64, bool) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return kb * 1024, true
+}
+
+// formatSymlinks lists the symlinked dirs and their targets
+func formatSymlinks(symlinks map[string]map[string]string) string {
+	hosts := make([]string, 0, len(symlinks))
+	for host := range symlinks {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	result := ""
+	for _, host := range hosts {
+		paths := make([]string, 0, len(symlinks[host]))
+		for p := range symlinks[host] {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			result += fmt.Sprintf("\n %s:%s -> %s", host, path.Dir(p), symlinks[host][p])
+		}
+	}
+	return result
+}
+
+// symlinkedCleanupDirs finds the dirs whose content is going to be deleted and
+// are symlinks on the remote hosts, the result is host -> glob path -> link target
+func (m *Manager) symlinkedCleanupDirs(
+	name string,
+	topo spec.Topology,
+	user string,
+	gOpt operator.Options,
+	delFileMap map[string]set.StringSet,
+) (map[string]map[string]string, error) {
+	checkDirs := make(map[string][]string)
+	for _, hf := range sortedCleanupFiles(delFileMap) {
+		for _, p := range hf.Paths {
+			// only the dirs cleaned by globbing all their content are affected,
+			// deleting a symlink itself does not touch the target
+			if strings.HasSuffix(p, "/*") {
+				checkDirs[hf.Host] = append(checkDirs[hf.Host], strings.TrimSuffix(p, "/*"))
+			}
+		}
+	}
+	if len(checkDirs) == 0 {
+		return nil, nil
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, user, gOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	symlinks := make(map[string]map[string]string)
+	t := b.
+		Func("CheckSymlinks", func(ctx context.Context) error {
+			for host, dirs := range checkDirs {
+				e, found := ctxt.GetInner(ctx).GetExecutor(host)
+				if !found {
+					return perrs.Errorf("no executor for host %s", host)
+				}
+				stdout, stderr, err := e.Execute(ctx, symlinkCheckCommand(dirs), false)
+				if err != nil {
+					return perrs.Annotatef(err, "failed to check symlinks on %s: %s", host, stderr)
+				}
+				for dir, target := range parseSymlinks(string(stdout)) {
+					if symlinks[host] == nil {
+						symlinks[host] = make(map[string]string)
+					}
+					symlinks[host][path.Join(dir, "*")] = target
+				}
+			}
+			return nil
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			return nil, err
+		}
+		return nil, perrs.Trace(err)
+	}
+	return symlinks, nil
+}
+
+// symlinkCheckCommand prints the symlinks in dirs and their targets, separated by a tab
+func symlinkCheckCommand(dirs []string) string {
+	quoted := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		quoted = append(quoted, shellQuote(dir))
+	}
+	return fmt.Sprintf(`for d in %s; do if [ -L "$d" ]; then printf '%%s\t%%s\n' "$d" "$(readlink -f "$d")"; fi; done`,
+		strings.Join(quoted, " "))
+}
+
+// parseSymlinks parses the output of symlinkCheckCommand
+func parseSymlinks(output string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		dir, target, found := strings.Cut(line, "\t")
+		if !found || dir == "" {
+			continue
+		}
+		result[dir] = target
+	}
+	return result
 }
 
 func cleanTarget(cleanOpt operator.Options) string {
@@ -151,6 +679,22 @@ func cleanTarget(cleanOpt operator.Options) string {
 		target += (" audit-log")
 	}
 
+	if cleanOpt.CleanupCrashes {
+		target += (" crashes")
+	}
+
+	if len(cleanOpt.CleanupPaths) > 0 {
+		target += fmt.Sprintf(" paths (%s)", strings.Join(cleanOpt.CleanupPaths, ", "))
+	}
+
+	if cleanOpt.OlderThan > 0 {
+		target += fmt.Sprintf(" (only files older than %s)", operator.FormatFileAge(cleanOpt.OlderThan))
+	}
+
+	if len(cleanOpt.ExcludeData) > 0 {
+		target += fmt.Sprintf(" (keeping %s)", strings.Join(cleanOpt.ExcludeData, ", "))
+	}
+
 	return color.HiYellowString(target)
 }
 
@@ -159,25 +703,21 @@ type cleanupFiles struct {
 	cleanupData     bool     // whether to clean up the data
 	cleanupLog      bool     // whether to clean up the log
 	cleanupTLS      bool     // whether to clean up the tls files
+	forceTLS        bool     // clean up the tls files even if tls is enabled
 	cleanupAuditLog bool     // whether to clean up the tidb server audit log
+	cleanupCrashes  bool     // whether to clean up the core dumps
+	extraPaths      []string // paths relative to the deploy dirs to clean up
 	retainDataRoles []string // roles that don't clean up
-	retainDataNodes []string // roles that don't clean up
+	retainDataNodes []string // nodes that don't clean up
+	dataOnlyRoles   []string // roles that only clean up the data
+	dataOnlyNodes   []string // nodes that only clean up the data
 	ansibleImport   bool     // cluster is ansible deploy
 	delFileMap      map[string]set.StringSet
 }
 
-// getCleanupFiles  get the files that need to be deleted
-func getCleanupFiles(topo spec.Topology,
-	cleanupData, cleanupLog, cleanupTLS, cleanupAuditLog bool, retainDataRoles, retainDataNodes []string) map[string]set.StringSet {
-	c := &cleanupFiles{
-		cleanupData:     cleanupData,
-		cleanupLog:      cleanupLog,
-		cleanupTLS:      cleanupTLS,
-		cleanupAuditLog: cleanupAuditLog,
-		retainDataRoles: retainDataRoles,
-		retainDataNodes: retainDataNodes,
-		delFileMap:      make(map[string]set.StringSet),
-	}
+// getCleanupFiles get the files that need to be deleted with the options in c
+func getCleanupFiles(topo spec.Topology, c cleanupFiles) map[string]set.StringSet {
+	c.delFileMap = make(map[string]set.StringSet)
 
 	// calculate file paths to be deleted before the prompt
 	c.instanceCleanupFiles(topo)
@@ -188,10 +728,15 @@ func getCleanupFiles(topo spec.Topology,
 
 // instanceCleanupFiles get the files that need to be deleted in the component
 func (c *cleanupFiles) instanceCleanupFiles(topo spec.Topology) {
+	// the dirs are resolved the same way as deploy creates them, relative
+	// ones are under the home of the deploy user rather than the SSH one
+	user := topo.BaseTopo().GlobalOptions.User
 	for _, com := range topo.ComponentsByStopOrder() {
 		instances := com.Instances()
 		retainDataRoles := set.NewStringSet(c.retainDataRoles...)
 		retainDataNodes := set.NewStringSet(c.retainDataNodes...)
+		dataOnlyRoles := set.NewStringSet(c.dataOnlyRoles...)
+		dataOnlyNodes := set.NewStringSet(c.dataOnlyNodes...)
 
 		for _, ins := range instances {
 			// not cleaning files of monitor agents if the instance does not have one
@@ -212,19 +757,30 @@ func (c *cleanupFiles) instanceCleanupFiles(topo spec.Topology) {
 				continue
 			}
 
+			// only the data of some instances is wiped, the rest of the
+			// categories don't apply to them
+			if dataOnlyRoles.Exist(ins.ComponentName()) ||
+				dataOnlyNodes.Exist(ins.ID()) || dataOnlyNodes.Exist(ins.GetHost()) {
+				if c.delFileMap[ins.GetManageHost()] == nil {
+					c.delFileMap[ins.GetManageHost()] = set.NewStringSet()
+				}
+				c.delFileMap[ins.GetManageHost()].Join(dataOnlyCleanupPaths(user, ins))
+				continue
+			}
+
 			// prevent duplicate directories
 			dataPaths := set.NewStringSet()
 			logPaths := set.NewStringSet()
 			tlsPath := set.NewStringSet()
 
 			if c.cleanupData && len(ins.DataDir()) > 0 {
-				for _, dataDir := range strings.Split(ins.DataDir(), ",") {
+				for _, dataDir := range spec.MultiDirAbs(user, ins.DataDir()) {
 					dataPaths.Insert(path.Join(dataDir, "*"))
 				}
 			}
 
 			if c.cleanupLog && len(ins.LogDir()) > 0 {
-				for _, logDir := range strings.Split(ins.LogDir(), ",") {
+				for _, logDir := range spec.MultiDirAbs(user, ins.LogDir()) {
 					// need to judge the audit log of tidb server
 					if ins.ComponentName() == spec.ComponentTiDB {
 						logPaths.Insert(path.Join(logDir, "tidb?[!audit]*.log"))
@@ -236,14 +792,32 @@ func (c *cleanupFiles) instanceCleanupFiles(topo spec.Topology) {
 			}
 
 			if c.cleanupAuditLog && ins.ComponentName() == spec.ComponentTiDB {
-				for _, logDir := range strings.Split(ins.LogDir(), ",") {
+				for _, logDir := range spec.MultiDirAbs(user, ins.LogDir()) {
 					logPaths.Insert(path.Join(logDir, "tidb-audit*.log"))
 				}
 			}
 
+			// the components run in their deploy dirs, where the core dumps are
+			// written to by default
+			crashPaths := set.NewStringSet()
+			if c.cleanupCrashes {
+				addCoreDumpPaths(crashPaths, spec.Abs(user, ins.DeployDir()))
+				if len(ins.DataDir()) > 0 {
+					for _, dataDir := range spec.MultiDirAbs(user, ins.DataDir()) {
+						addCoreDumpPaths(crashPaths, dataDir)
+					}
+				}
+			}
+
+			// residue in the deploy dir that the categories above miss
+			extraPaths := set.NewStringSet()
+			for _, p := range c.extraPaths {
+				extraPaths.Insert(filepath.Join(spec.Abs(user, ins.DeployDir()), p))
+			}
+
 			// clean tls data
-			if c.cleanupTLS && !topo.BaseTopo().GlobalOptions.TLSEnabled {
-				deployDir := spec.Abs(topo.BaseTopo().GlobalOptions.User, ins.DeployDir())
+			if c.cleanupTLS && (c.forceTLS || !topo.BaseTopo().GlobalOptions.TLSEnabled) {
+				deployDir := spec.Abs(user, ins.DeployDir())
 				tlsDir := filepath.Join(deployDir, spec.TLSCertKeyDir)
 				tlsPath.Insert(tlsDir)
 
@@ -258,7 +832,7 @@ func (c *cleanupFiles) instanceCleanupFiles(topo spec.Topology) {
 			if c.delFileMap[ins.GetManageHost()] == nil {
 				c.delFileMap[ins.GetManageHost()] = set.NewStringSet()
 			}
-			c.delFileMap[ins.GetManageHost()].Join(logPaths).Join(dataPaths).Join(tlsPath)
+			c.delFileMap[ins.GetManageHost()].Join(logPaths).Join(dataPaths).Join(crashPaths).Join(tlsPath).Join(extraPaths)
 		}
 	}
 }
@@ -274,6 +848,7 @@ func (c *cleanupFiles) monitorCleanupFiles(topo spec.Topology) {
 	// get the host with monitor installed
 	uniqueHosts, noAgentHosts := getMonitorHosts(topo)
 	retainDataNodes := set.NewStringSet(c.retainDataNodes...)
+	dataOnlyNodes := set.NewStringSet(c.dataOnlyNodes...)
 
 	// monitoring agents
 	for host := range uniqueHosts {
@@ -292,12 +867,22 @@ func (c *cleanupFiles) monitorCleanupFiles(topo spec.Topology) {
 
 		// data dir would be empty for components which don't need it
 		dataDir := monitoredOptions.DataDir
-		if c.cleanupData && len(dataDir) > 0 {
+		dataOnly := dataOnlyNodes.Exist(host)
+		if (c.cleanupData || dataOnly) && len(dataDir) > 0 {
 			// the default data_dir is relative to deploy_dir
 			if !strings.HasPrefix(dataDir, "/") {
 				dataDir = filepath.Join(deployDir, dataDir)
 			}
-			dataPaths.Insert(path.Join(dataDir, "*"))
+			if !dataOnly || !utils.IsSubDir(dataDir, deployDir) {
+				dataPaths.Insert(path.Join(dataDir, "*"))
+			}
+		}
+		if dataOnly {
+			if c.delFileMap[host] == nil {
+				c.delFileMap[host] = set.NewStringSet()
+			}
+			c.delFileMap[host].Join(dataPaths)
+			continue
 		}
 
 		// log dir will always be with values, but might not used by the component
@@ -306,8 +891,17 @@ func (c *cleanupFiles) monitorCleanupFiles(topo spec.Topology) {
 			logPaths.Insert(path.Join(logDir, "*.log"))
 		}
 
+		crashPaths := set.NewStringSet()
+		if c.cleanupCrashes {
+			addCoreDumpPaths(crashPaths, deployDir)
+		}
+		extraPaths := set.NewStringSet()
+		for _, p := range c.extraPaths {
+			extraPaths.Insert(filepath.Join(deployDir, p))
+		}
+
 		// clean tls data
-		if c.cleanupTLS && !topo.BaseTopo().GlobalOptions.TLSEnabled {
+		if c.cleanupTLS && (c.forceTLS || !topo.BaseTopo().GlobalOptions.TLSEnabled) {
 			tlsDir := filepath.Join(deployDir, spec.TLSCertKeyDir)
 			tlsPath.Insert(tlsDir)
 			// ansible deploy
@@ -320,6 +914,59 @@ func (c *cleanupFiles) monitorCleanupFiles(topo spec.Topology) {
 		if c.delFileMap[host] == nil {
 			c.delFileMap[host] = set.NewStringSet()
 		}
-		c.delFileMap[host].Join(logPaths).Join(dataPaths).Join(tlsPath)
+		c.delFileMap[host].Join(logPaths).Join(dataPaths).Join(crashPaths).Join(tlsPath).Join(extraPaths)
+	}
+}
+
+// dataOnlyCleanupPaths returns the paths to wipe the data of the instance
+// only, the data dirs containing the deploy dir are left out to keep it
+func dataOnlyCleanupPaths(user string, ins spec.Instance) set.StringSet {
+	paths := set.NewStringSet()
+	if len(ins.DataDir()) == 0 {
+		return paths
+	}
+	deployDir := spec.Abs(user, ins.DeployDir())
+	for _, dataDir := range spec.MultiDirAbs(user, ins.DataDir()) {
+		if utils.IsSubDir(dataDir, deployDir) {
+			continue
+		}
+		paths.Insert(path.Join(dataDir, "*"))
+	}
+	return paths
+}
+
+// checkDataOnlyDirs checks that the data of the instances to clean the data
+// only could be wiped without touching their deploy dirs
+func checkDataOnlyDirs(topo spec.Topology, cleanOpt operator.Options) error {
+	if len(cleanOpt.DataOnlyRoles) == 0 && len(cleanOpt.DataOnlyNodes) == 0 {
+		return nil
+	}
+	user := topo.BaseTopo().GlobalOptions.User
+	roles := set.NewStringSet(cleanOpt.DataOnlyRoles...)
+	nodes := set.NewStringSet(cleanOpt.DataOnlyNodes...)
+	var conflicts []string
+	topo.IterInstance(func(ins spec.Instance) {
+		if len(ins.DataDir()) == 0 ||
+			(!roles.Exist(ins.ComponentName()) && !nodes.Exist(ins.ID()) && !nodes.Exist(ins.GetHost())) {
+			return
+		}
+		deployDir := spec.Abs(user, ins.DeployDir())
+		for _, dataDir := range spec.MultiDirAbs(user, ins.DataDir()) {
+			if utils.IsSubDir(dataDir, deployDir) {
+				conflicts = append(conflicts, fmt.Sprintf("%s (data dir %s, deploy dir %s)", ins.ID(), dataDir, deployDir))
+			}
+		}
+	})
+	if len(conflicts) > 0 {
+		return perrs.Errorf("the data of the instances can't be cleaned without the deploy dirs as they are inside the data dirs: %s",
+			strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// addCoreDumpPaths adds the patterns of core dumps in the dir to the paths
+func addCoreDumpPaths(paths set.StringSet, dir string) {
+	for _, pattern := range operator.CoreDumpPatterns {
+		paths.Insert(path.Join(dir, pattern))
 	}
 }
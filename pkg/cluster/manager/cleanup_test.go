@@ -0,0 +1,307 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSortedCleanupFiles(t *testing.T) {
+	assert := require.New(t)
+
+	delFileMap := map[string]set.StringSet{
+		"172.16.5.3": set.NewStringSet("/data/tikv", "/data/pd", "/log/tikv"),
+		"172.16.5.1": set.NewStringSet("/log/tidb", "/data/tidb"),
+		"172.16.5.2": set.NewStringSet(),
+	}
+	expected := []hostCleanupFiles{
+		{Host: "172.16.5.1", Paths: []string{"/data/tidb", "/log/tidb"}},
+		{Host: "172.16.5.3", Paths: []string{"/data/pd", "/data/tikv", "/log/tikv"}},
+	}
+
+	// the plan must be the same no matter how the map is iterated
+	for i := 0; i < 20; i++ {
+		assert.Equal(expected, sortedCleanupFiles(delFileMap))
+	}
+	assert.Equal(formatCleanupFiles(delFileMap, nil, nil), formatCleanupFiles(delFileMap, nil, nil))
+}
+
+func TestWriteCleanupPlan(t *testing.T) {
+	assert := require.New(t)
+
+	delFileMap := map[string]set.StringSet{
+		"172.16.5.2": set.NewStringSet("/log/tikv", "/data/tikv"),
+		"172.16.5.1": set.NewStringSet("/data/tidb"),
+	}
+	cleanOpt := operator.Options{OlderThan: 24 * time.Hour, ExcludeData: []string{"backup"}}
+
+	jsonFile := filepath.Join(t.TempDir(), "plan.json")
+	assert.Nil(writeCleanupPlan(jsonFile, "test", cleanOpt, delFileMap))
+	data, err := os.ReadFile(jsonFile)
+	assert.Nil(err)
+	var plan cleanupPlanFile
+	assert.Nil(json.Unmarshal(data, &plan))
+	assert.Equal(cleanupPlanFile{
+		Cluster:   "test",
+		OlderThan: "24h0m0s",
+		Exclude:   []string{"backup"},
+		Hosts:     sortedCleanupFiles(delFileMap),
+	}, plan)
+
+	textFile := filepath.Join(t.TempDir(), "plan.txt")
+	assert.Nil(writeCleanupPlan(textFile, "test", operator.Options{}, delFileMap))
+	data, err = os.ReadFile(textFile)
+	assert.Nil(err)
+	assert.Equal("# cluster: test\n172.16.5.1\t/data/tidb\n172.16.5.2\t/data/tikv\n172.16.5.2\t/log/tikv\n", string(data))
+}
+
+func TestCleanupSymlinks(t *testing.T) {
+	assert := require.New(t)
+
+	symlinks := parseSymlinks("/data/tikv\t/mnt/shared/tikv\n\nbroken line\n")
+	assert.Equal(map[string]string{"/data/tikv": "/mnt/shared/tikv"}, symlinks)
+
+	delFileMap := map[string]set.StringSet{
+		"172.16.5.1": set.NewStringSet("/data/tikv/*", "/log/tikv/*.log"),
+	}
+	plan := formatCleanupFiles(delFileMap, map[string]map[string]string{
+		"172.16.5.1": {"/data/tikv/*": "/mnt/shared/tikv"},
+	}, nil)
+	assert.Contains(plan, "/data/tikv/*")
+	assert.Contains(plan, "symlink to /mnt/shared/tikv")
+	assert.NotContains(formatCleanupFiles(delFileMap, nil, nil), "symlink")
+
+	assert.Equal(`for d in '/data/tikv' '/data/pd'; do if [ -L "$d" ]; then printf '%s\t%s\n' "$d" "$(readlink -f "$d")"; fi; done`,
+		symlinkCheckCommand([]string{"/data/tikv", "/data/pd"}))
+	assert.Equal(`for d in '/data/it'\''s dir'; do if [ -L "$d" ]; then printf '%s\t%s\n' "$d" "$(readlink -f "$d")"; fi; done`,
+		symlinkCheckCommand([]string{"/data/it's dir"}))
+}
+
+func TestCleanupSize(t *testing.T) {
+	assert := require.New(t)
+
+	size, ok := parseDuTotal("2048\ttotal\n")
+	assert.True(ok)
+	assert.Equal(int64(2048*1024), size)
+	_, ok = parseDuTotal("")
+	assert.False(ok)
+
+	delFileMap := map[string]set.StringSet{
+		"172.16.5.1": set.NewStringSet("/data/tikv/*"),
+		"172.16.5.2": set.NewStringSet("/data/pd/*"),
+	}
+	sizes := map[string]int64{"172.16.5.1": 3 * 1024 * 1024 * 1024}
+	plan := formatCleanupFiles(delFileMap, nil, sizes)
+	assert.Contains(plan, "(~3GiB)")
+	assert.Equal(1, strings.Count(plan, "(~"))
+	assert.Contains(formatCleanupSize(map[string]int64{"a": 1024, "b": 1024}), "across 2 host(s)")
+}
+
+func TestCleanupAges(t *testing.T) {
+	assert := require.New(t)
+
+	ages, ok := parseCleanupAges("5 3456009 9 3 1 0 1\n")
+	assert.True(ok)
+	assert.Equal(int64(5), ages.Files)
+	assert.Equal(40*24*time.Hour+9*time.Second, ages.Oldest)
+	assert.Equal(9*time.Second, ages.Newest)
+	assert.Equal([]int64{3, 1, 0, 1}, ages.Buckets)
+	_, ok = parseCleanupAges("")
+	assert.False(ok)
+	_, ok = parseCleanupAges("5 3456009 9")
+	assert.False(ok)
+
+	report := formatCleanupAges(map[string]cleanupAges{
+		"172.16.5.1": ages,
+		"172.16.5.2": {Buckets: make([]int64, 4)},
+	})
+	assert.Contains(report, "5 files, oldest")
+	assert.Contains(report, "40d")
+	assert.Contains(report, "<1m")
+	assert.Contains(report, "0-1d: 3, 1d-7d: 1, 7d-30d: 0, >30d: 1")
+	assert.Contains(report, "no files")
+
+	assert.Contains(cleanupAgeCommand([]string{"/log/*.log"}, operator.CleanupFilter{}), "-name '*.log'")
+}
+
+func TestCleanupTLSFiles(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "tidb"
+  deploy_dir: "/tidb-deploy"
+  enable_tls: true
+pd_servers:
+  - host: 172.16.5.53
+`), &topo)
+	assert.Nil(err)
+
+	// the certificates of a TLS enabled cluster are only removed if forced
+	delFileMap := getCleanupFiles(&topo, cleanupFiles{cleanupTLS: true})
+	assert.False(delFileMap["172.16.5.53"].Exist("/tidb-deploy/pd-2379/tls"))
+	delFileMap = getCleanupFiles(&topo, cleanupFiles{cleanupTLS: true, forceTLS: true})
+	assert.True(delFileMap["172.16.5.53"].Exist("/tidb-deploy/pd-2379/tls"))
+}
+
+func TestCleanupFilesRelativeDirs(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "tidb"
+  deploy_dir: "tidb-deploy"
+  data_dir: "tidb-data"
+tikv_servers:
+  - host: 172.16.5.53
+    data_dir: "data1, /ssd/tikv-20160"
+    log_dir: "logs/../log"
+tidb_servers:
+  - host: 172.16.5.54
+    deploy_dir: "/tidb-deploy/tidb-4000/"
+`), &topo)
+	assert.Nil(err)
+
+	// the dirs must be the same as the ones created by deploy
+	delFileMap := getCleanupFiles(&topo, cleanupFiles{cleanupData: true, cleanupLog: true, cleanupTLS: true, cleanupAuditLog: true})
+	assert.ElementsMatch([]string{
+		"/home/tidb/tidb-deploy/tikv-20160/data1/*",
+		"/ssd/tikv-20160/*",
+		"/home/tidb/tidb-deploy/tikv-20160/log/*.log",
+		"/home/tidb/tidb-deploy/tikv-20160/tls",
+		// the relative data dir of monitors is under their deploy dir
+		"/home/tidb/tidb-deploy/monitor-9100/tidb-data/monitor-9100/*",
+		"/home/tidb/tidb-deploy/monitor-9100/log/*.log",
+		"/home/tidb/tidb-deploy/monitor-9100/tls",
+	}, delFileMap["172.16.5.53"].Slice())
+	assert.ElementsMatch([]string{
+		"/tidb-deploy/tidb-4000/log/tidb?[!audit]*.log",
+		"/tidb-deploy/tidb-4000/log/tidb.log",
+		"/tidb-deploy/tidb-4000/log/tidb-audit*.log",
+		"/tidb-deploy/tidb-4000/tls",
+		"/home/tidb/tidb-deploy/monitor-9100/tidb-data/monitor-9100/*",
+		"/home/tidb/tidb-deploy/monitor-9100/log/*.log",
+		"/home/tidb/tidb-deploy/monitor-9100/tls",
+	}, delFileMap["172.16.5.54"].Slice())
+}
+
+func TestCleanupFilesCrashes(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "tidb"
+  deploy_dir: "/tidb-deploy"
+  data_dir: "/tidb-data"
+tikv_servers:
+  - host: 172.16.5.53
+  - host: 172.16.5.54
+`), &topo)
+	assert.Nil(err)
+
+	delFileMap := getCleanupFiles(&topo, cleanupFiles{cleanupCrashes: true, retainDataNodes: []string{"172.16.5.54"}})
+	assert.ElementsMatch([]string{
+		"/tidb-deploy/tikv-20160/core",
+		"/tidb-deploy/tikv-20160/core.*",
+		"/tidb-deploy/tikv-20160/*.core",
+		"/tidb-data/tikv-20160/core",
+		"/tidb-data/tikv-20160/core.*",
+		"/tidb-data/tikv-20160/*.core",
+		"/tidb-deploy/monitor-9100/core",
+		"/tidb-deploy/monitor-9100/core.*",
+		"/tidb-deploy/monitor-9100/*.core",
+	}, delFileMap["172.16.5.53"].Slice())
+	// the retained nodes are not touched
+	assert.Empty(delFileMap["172.16.5.54"].Slice())
+}
+
+func TestCleanupFilesExtraPaths(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "tidb"
+  deploy_dir: "/tidb-deploy"
+tikv_servers:
+  - host: 172.16.5.53
+  - host: 172.16.5.54
+`), &topo)
+	assert.Nil(err)
+
+	delFileMap := getCleanupFiles(&topo, cleanupFiles{extraPaths: []string{"bin/old-version"}, retainDataNodes: []string{"172.16.5.54"}})
+	assert.ElementsMatch([]string{
+		"/tidb-deploy/tikv-20160/bin/old-version",
+		"/tidb-deploy/monitor-9100/bin/old-version",
+	}, delFileMap["172.16.5.53"].Slice())
+	// the retained nodes are not touched
+	assert.Empty(delFileMap["172.16.5.54"].Slice())
+
+	assert.Nil(operator.ValidateCleanupPaths([]string{"bin/old-version", "scripts/*.bak"}))
+	for _, p := range []string{"/bin", "..", "bin/../../x", "bin; rm -rf /", ""} {
+		assert.NotNil(operator.ValidateCleanupPaths([]string{p}), p)
+	}
+}
+
+func TestCleanupFilesDataOnly(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "tidb"
+  deploy_dir: "/tidb-deploy"
+  data_dir: "/tidb-data"
+tikv_servers:
+  - host: 172.16.5.53
+  - host: 172.16.5.54
+`), &topo)
+	assert.Nil(err)
+
+	delFileMap := getCleanupFiles(&topo, cleanupFiles{cleanupData: true, cleanupLog: true, cleanupCrashes: true, extraPaths: []string{"bin/old-version"}, dataOnlyNodes: []string{"172.16.5.54"}})
+	assert.Contains(delFileMap["172.16.5.53"].Slice(), "/tidb-deploy/tikv-20160/bin/old-version")
+	assert.Contains(delFileMap["172.16.5.53"].Slice(), "/tidb-deploy/tikv-20160/log/*.log")
+	// nothing in the deploy dirs is touched on the data only nodes
+	assert.ElementsMatch([]string{
+		"/tidb-data/tikv-20160/*",
+		"/tidb-data/monitor-9100/*",
+	}, delFileMap["172.16.5.54"].Slice())
+
+	// the data is cleaned even without --data, the monitor agents are not of the role
+	delFileMap = getCleanupFiles(&topo, cleanupFiles{cleanupLog: true, dataOnlyRoles: []string{"tikv"}})
+	assert.ElementsMatch([]string{
+		"/tidb-data/tikv-20160/*",
+		"/tidb-deploy/monitor-9100/log/*.log",
+	}, delFileMap["172.16.5.54"].Slice())
+
+	cleanOpt := operator.Options{DataOnlyNodes: []string{"172.16.5.54"}}
+	assert.Nil(checkDataOnlyDirs(&topo, cleanOpt))
+	topo.TiKVServers[1].DataDir = "/tidb-deploy"
+	assert.NotNil(checkDataOnlyDirs(&topo, cleanOpt))
+	assert.Empty(dataOnlyCleanupPaths("tidb", selectedInstances(&topo, operator.Options{Nodes: []string{"172.16.5.54:20160"}})[0]).Slice())
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// ResolveConcurrency sizes gOpt.Concurrency if it is set to auto, by the
+// unique hosts of the clusters or topology files among args, which are the
+// args of the command being run. Args that are neither are ignored.
+func (m *Manager) ResolveConcurrency(gOpt *operator.Options, args []string) {
+	if gOpt.Concurrency != operator.ConcurrencyAuto {
+		return
+	}
+
+	hosts := set.NewStringSet()
+	for _, arg := range args {
+		var topo spec.Topology
+		if exist, err := m.specManager.Exist(arg); err == nil && exist {
+			metadata, err := m.meta(arg)
+			if err != nil {
+				continue
+			}
+			topo = metadata.GetTopology()
+		} else if arg == spec.TopologyFromStdin || utils.IsExist(arg) {
+			topo = m.specManager.NewMetadata().GetTopology()
+			if err := spec.ParseTopologyYaml(arg, topo, true); err != nil {
+				continue
+			}
+		} else {
+			continue
+		}
+		topo.IterInstance(func(inst spec.Instance) {
+			hosts.Insert(inst.GetManageHost())
+		})
+	}
+
+	gOpt.Concurrency = operator.AutoConcurrency(len(hosts), gOpt.ConcurrencyCap)
+	m.logger.Debugf("Concurrency is sized to %d for %d host(s)", gOpt.Concurrency, len(hosts))
+}
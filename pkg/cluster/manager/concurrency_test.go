@@ -0,0 +1,64 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConcurrency(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	m := NewManager("tidb", spec.NewSpec(dir, func() spec.Metadata {
+		return &spec.ClusterMeta{Topology: new(spec.Specification)}
+	}), logprinter.NewLogger(""))
+
+	topoFile := filepath.Join(dir, "topology.yaml")
+	topo := "tikv_servers:\n"
+	for i := 1; i <= 8; i++ {
+		host := fmt.Sprintf("172.16.5.%d", i)
+		for _, port := range []int{20160, 20161} {
+			topo += fmt.Sprintf("  - host: %s\n    port: %d\n    status_port: %d\n", host, port, port+20)
+		}
+	}
+	assert.Nil(os.WriteFile(topoFile, []byte(topo), 0644))
+
+	// fixed concurrency is kept
+	gOpt := operator.Options{Concurrency: 3}
+	m.ResolveConcurrency(&gOpt, []string{topoFile})
+	assert.Equal(3, gOpt.Concurrency)
+
+	// one task per unique host
+	gOpt = operator.Options{Concurrency: operator.ConcurrencyAuto}
+	m.ResolveConcurrency(&gOpt, []string{topoFile, "not-exist"})
+	assert.Equal(8, gOpt.Concurrency)
+
+	// small clusters use the default
+	gOpt = operator.Options{Concurrency: operator.ConcurrencyAuto}
+	m.ResolveConcurrency(&gOpt, []string{"not-exist"})
+	assert.Equal(operator.DefaultConcurrency, gOpt.Concurrency)
+
+	assert.Equal(operator.DefaultConcurrencyCap, operator.AutoConcurrency(100, 0))
+	assert.Equal(10, operator.AutoConcurrency(100, 10))
+	assert.Equal(20, operator.AutoConcurrency(20, 0))
+}
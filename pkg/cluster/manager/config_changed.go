@@ -0,0 +1,94 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"golang.org/x/sync/errgroup"
+)
+
+// configChecksumCommand prints a single checksum of the config files and the
+// start script deployed in the dir
+func configChecksumCommand(deployDir string) string {
+	return fmt.Sprintf("cat %[1]s/conf/* %[1]s/scripts/* 2>/dev/null | sha256sum", shellQuote(deployDir))
+}
+
+// configChecksums gets the checksum of the deployed config of the selected instances,
+// an instance whose checksum can not be read is left out and so always treated as changed
+func configChecksums(ctx context.Context, topo spec.Topology, deployUser string, gOpt operator.Options) map[string]string {
+	var mu sync.Mutex
+	result := make(map[string]string)
+	errg, _ := errgroup.WithContext(ctx)
+	errg.SetLimit(gOpt.Concurrency)
+	for _, inst := range selectedInstances(topo, gOpt) {
+		inst := inst
+		errg.Go(func() error {
+			e, found := ctxt.GetInner(ctx).GetExecutor(inst.GetManageHost())
+			if !found {
+				return nil
+			}
+			cmd := configChecksumCommand(spec.Abs(deployUser, inst.DeployDir()))
+			stdout, _, err := e.Execute(ctx, cmd, false)
+			if err != nil {
+				return nil
+			}
+			fields := strings.Fields(string(stdout))
+			if len(fields) == 0 {
+				return nil
+			}
+			mu.Lock()
+			result[inst.ID()] = fields[0]
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = errg.Wait()
+	return result
+}
+
+// changedConfigFilter returns the instance filter that keeps only the instances whose
+// checksum differs between the two snapshots or is missing in any of them
+func changedConfigFilter(before, after map[string]string) func(spec.Instance) bool {
+	return func(inst spec.Instance) bool {
+		prev, ok1 := before[inst.ID()]
+		curr, ok2 := after[inst.ID()]
+		return !ok1 || !ok2 || prev != curr
+	}
+}
+
+// selectedInstances returns the instances matching the role and node selection
+func selectedInstances(topo spec.Topology, gOpt operator.Options) []spec.Instance {
+	roleFilter := set.NewStringSet(gOpt.Roles...)
+	nodeFilter := set.NewStringSet(gOpt.Nodes...)
+
+	var insts []spec.Instance
+	topo.IterInstance(func(inst spec.Instance) {
+		if len(gOpt.Roles) > 0 && !roleFilter.Exist(inst.Role()) {
+			return
+		}
+		if len(gOpt.Nodes) > 0 && !nodeFilter.Exist(inst.ID()) {
+			return
+		}
+		insts = append(insts, inst)
+	})
+	return operator.FilterInstanceBy(insts, gOpt.InstanceFilter())
+}
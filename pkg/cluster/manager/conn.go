@@ -0,0 +1,175 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// reasons of a failed connectivity check
+const (
+	ConnFailAuth    = "auth"
+	ConnFailTimeout = "timeout"
+	ConnFailSudo    = "sudo"
+	ConnFailOther   = "error"
+)
+
+// ConnStatus is the connectivity check result of a host
+type ConnStatus struct {
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+	Sudo      bool   `json:"sudo"`
+	Reason    string `json:"reason,omitempty"` // one of auth, timeout, sudo and error
+	Message   string `json:"message,omitempty"`
+}
+
+// CheckConn checks the SSH connectivity and privilege escalation of all hosts in the cluster
+func (m *Manager) CheckConn(name string, gOpt operator.Options) ([]ConnStatus, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// sudo is not needed for clusters running in systemd user mode
+	checkSudo := metadata.GetTopology().BaseTopo().GlobalOptions.SystemdMode != spec.UserMode
+
+	var mu sync.Mutex
+	visited := set.NewStringSet()
+	var result []ConnStatus
+	err = m.ForEachInstance(name, gOpt, func(ctx context.Context, inst spec.Instance, e ctxt.Executor) error {
+		// the hosts with multiple instances are checked only once
+		mu.Lock()
+		host := inst.GetManageHost()
+		if visited.Exist(host) {
+			mu.Unlock()
+			return nil
+		}
+		visited.Insert(host)
+		mu.Unlock()
+
+		status := checkHostConn(ctx, e, host, checkSudo)
+		mu.Lock()
+		result = append(result, status)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Host < result[j].Host
+	})
+
+	failed := []string{}
+	for _, s := range result {
+		if s.Reason != "" {
+			failed = append(failed, s.Host)
+		}
+	}
+	if len(failed) > 0 {
+		return result, perrs.Errorf("connectivity check failed on host(s): %s", strings.Join(failed, ","))
+	}
+	return result, nil
+}
+
+// checkHostConn runs a trivial command and a sudo test on the host
+func checkHostConn(ctx context.Context, e ctxt.Executor, host string, checkSudo bool) ConnStatus {
+	status := ConnStatus{Host: host}
+
+	if _, stderr, err := e.Execute(ctx, "true", false); err != nil {
+		status.Reason, status.Message = classifyConnError(err, stderr)
+		return status
+	}
+	status.Reachable = true
+
+	if !checkSudo {
+		return status
+	}
+	// use non-interactive mode to avoid hanging on a password prompt
+	if _, stderr, err := e.Execute(ctx, "sudo -n true", false); err != nil {
+		status.Reason = ConnFailSudo
+		status.Message = strings.TrimSpace(string(stderr))
+		if status.Message == "" {
+			status.Message = err.Error()
+		}
+		return status
+	}
+	status.Sudo = true
+	return status
+}
+
+// classifyConnError tells whether a failed connection is caused by authentication or timeout
+func classifyConnError(err error, stderr []byte) (reason, msg string) {
+	msg = err.Error()
+	if cause := errorx.Cast(err); cause != nil && cause.Cause() != nil {
+		msg = cause.Cause().Error()
+	}
+	if len(stderr) > 0 {
+		msg = strings.TrimSpace(string(stderr))
+	}
+
+	lower := strings.ToLower(msg)
+	switch {
+	case errorx.IsOfType(err, executor.ErrSSHExecuteTimedout),
+		strings.Contains(lower, "timeout"),
+		strings.Contains(lower, "timed out"):
+		return ConnFailTimeout, msg
+	case strings.Contains(lower, "unable to authenticate"),
+		strings.Contains(lower, "permission denied"),
+		strings.Contains(lower, "no supported methods remain"):
+		return ConnFailAuth, msg
+	}
+	return ConnFailOther, msg
+}
+
+// PrintConnStatus prints the result of connectivity check
+func PrintConnStatus(result []ConnStatus) {
+	table := [][]string{{"Host", "Reachable", "Sudo", "Reason", "Message"}}
+	for _, s := range result {
+		reachable := color.GreenString("yes")
+		if !s.Reachable {
+			reachable = color.RedString("no")
+		}
+		sudo := color.GreenString("yes")
+		switch {
+		case !s.Reachable:
+			sudo = "-"
+		case !s.Sudo && s.Reason == "":
+			sudo = "-" // not checked
+		case !s.Sudo:
+			sudo = color.RedString("no")
+		}
+		table = append(table, []string{s.Host, reachable, sudo, s.Reason, s.Message})
+	}
+	tui.PrintTable(table, true)
+}
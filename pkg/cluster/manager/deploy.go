@@ -66,6 +66,9 @@ func (m *Manager) Deploy(
 	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
 		return err
 	}
+	if err := checkStdinTopology(topoFile, skipConfirm); err != nil {
+		return err
+	}
 
 	exist, err := m.specManager.Exist(name)
 	if err != nil {
@@ -108,6 +111,7 @@ func (m *Manager) Deploy(
 	}
 
 	spec.ExpandRelativeDir(topo)
+	recordTopologyHash(topo)
 
 	base := topo.BaseTopo()
 	if sshType := gOpt.SSHType; sshType != "" {
@@ -176,10 +180,13 @@ func (m *Manager) Deploy(
 		}
 	}
 
-	if err := utils.MkdirAll(m.specManager.Path(name), 0755); err != nil {
-		return errorx.InitializationFailed.
-			Wrap(err, "Failed to create cluster metadata directory '%s'", m.specManager.Path(name)).
-			WithProperty(tui.SuggestionFromString("Please check file system permissions and try again."))
+	// nothing is saved locally in dry run mode
+	if !gOpt.DryRun {
+		if err := utils.MkdirAll(m.specManager.Path(name), 0755); err != nil {
+			return errorx.InitializationFailed.
+				Wrap(err, "Failed to create cluster metadata directory '%s'", m.specManager.Path(name)).
+				WithProperty(tui.SuggestionFromString("Please check file system permissions and try again."))
+		}
 	}
 
 	var (
@@ -211,9 +218,10 @@ func (m *Manager) Deploy(
 	})
 
 	// generate CA and client cert for TLS enabled cluster
-	_, err = m.genAndSaveCertificate(name, globalOptions)
-	if err != nil {
-		return err
+	if !gOpt.DryRun {
+		if _, err := m.genAndSaveCertificate(name, globalOptions); err != nil {
+			return err
+		}
 	}
 
 	uniqueHosts, noAgentHosts := getMonitorHosts(topo)
@@ -390,7 +398,11 @@ func (m *Manager) Deploy(
 		ParallelStep("+ Deploy TiDB instance", false, deployCompTasks...).
 		ParallelStep("+ Copy certificate to remote host", gOpt.Force, certificateTasks...).
 		ParallelStep("+ Init instance configs", gOpt.Force, refreshConfigTasks...).
-		ParallelStep("+ Init monitor configs", gOpt.Force, monitorConfigTasks...)
+		ParallelStep("+ Init monitor configs", gOpt.Force, monitorConfigTasks...).
+		Func("RecordBinaryChecksums", func(ctx context.Context) error {
+			recordBinaryChecksums(ctx, m.logger, metadata.GetBaseMeta(), selectedInstances(topo, operator.Options{}))
+			return nil
+		})
 
 	if afterDeploy != nil {
 		afterDeploy(builder, topo, gOpt)
@@ -401,8 +413,11 @@ func (m *Manager) Deploy(
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "deploy"),
 	)
+	if m.dryRun("deploy", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -426,3 +441,12 @@ func (m *Manager) Deploy(
 	m.logger.Infof("Cluster `%s` deployed successfully, you can start it with command: `%s`", name, hint)
 	return nil
 }
+
+// checkStdinTopology makes sure no confirmation is needed when the topology is
+// read from stdin, as the prompts could not get any answer from it
+func checkStdinTopology(topoFile string, skipConfirm bool) error {
+	if topoFile == spec.TopologyFromStdin && !skipConfirm {
+		return perrs.Errorf("the topology is read from stdin, please add --yes to skip the confirmations")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"gopkg.in/yaml.v2"
+)
+
+// describeOmitFields are the fields of instances recording the state of the
+// deployed cluster rather than its layout, e.g. an `imported` instance is
+// rejected by deploy, so they are omitted from the regenerated topology
+var describeOmitFields = map[string]struct{}{
+	"imported": {},
+	"patched":  {},
+}
+
+// Describe returns the stored topology of the cluster in the format of the
+// topology file, including the global and monitored options, so that it
+// could be regenerated when the original file is lost. The fields filled
+// with defaults on deploy, e.g. ports and directories, are written out
+// explicitly, which makes deploying the output reproduce the same layout
+// even if the defaults change. The version of the cluster is written in a
+// comment as it's not part of the topology.
+//
+// If roles or nodes are set in gOpt, only the instances matched by them, i.e.
+// the ones a scoped operation acts upon, are kept in the topology.
+func (m *Manager) Describe(name string, gOpt operator.Options) ([]byte, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	topo := metadata.GetTopology()
+	scoped := len(gOpt.Roles) > 0 || len(gOpt.Nodes) > 0
+	if scoped {
+		if topo, err = scopeTopology(topo, gOpt); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := yaml.Marshal(topo)
+	if err != nil {
+		return nil, perrs.AddStack(err)
+	}
+	var fields yaml.MapSlice
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, perrs.AddStack(err)
+	}
+	data, err = yaml.Marshal(omitStateFields(fields))
+	if err != nil {
+		return nil, perrs.AddStack(err)
+	}
+
+	header := fmt.Sprintf("# Topology of %s cluster `%s`, version %s\n", m.sysName, name, metadata.GetBaseMeta().Version)
+	if scoped {
+		header += fmt.Sprintf("# Scoped to roles [%s] and nodes [%s]\n", strings.Join(gOpt.Roles, ", "), strings.Join(gOpt.Nodes, ", "))
+	}
+	return append([]byte(header), data...), nil
+}
+
+// omitStateFields removes describeOmitFields from the instances of every component
+func omitStateFields(topo yaml.MapSlice) yaml.MapSlice {
+	for i, comp := range topo {
+		insts, ok := comp.Value.([]any)
+		if !ok {
+			continue
+		}
+		for j, inst := range insts {
+			fields, ok := inst.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+			kept := fields[:0]
+			for _, f := range fields {
+				if _, omit := describeOmitFields[fmt.Sprint(f.Key)]; !omit {
+					kept = append(kept, f)
+				}
+			}
+			insts[j] = kept
+		}
+		topo[i].Value = insts
+	}
+	return topo
+}
+
+// scopeTopology returns a copy of the topology with only the instances matched
+// by the roles and nodes in gOpt, the same way as the operations select them
+func scopeTopology(topo spec.Topology, gOpt operator.Options) (spec.Topology, error) {
+	roleFilter := set.NewStringSet(gOpt.Roles...)
+	nodeFilter := set.NewStringSet(gOpt.Nodes...)
+	matched := make(map[any]struct{})
+	for _, comp := range operator.FilterComponent(topo.ComponentsByStartOrder(), roleFilter) {
+		for _, inst := range operator.FilterInstance(comp.Instances(), nodeFilter) {
+			// the spec is embedded in the BaseInstance of every instance
+			if v := reflect.Indirect(reflect.ValueOf(inst)).FieldByName("InstanceSpec"); v.IsValid() {
+				matched[v.Interface()] = struct{}{}
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return nil, perrs.Errorf("no instance matches the roles [%s] and nodes [%s]",
+			strings.Join(gOpt.Roles, ", "), strings.Join(gOpt.Nodes, ", "))
+	}
+
+	// the instances refer to the specs in the topology, keep the matched ones
+	// in a shallow copy of it
+	v := reflect.ValueOf(topo)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, perrs.Errorf("unsupported topology type %T", topo)
+	}
+	scoped := reflect.New(v.Elem().Type())
+	scoped.Elem().Set(v.Elem())
+	for i := 0; i < scoped.Elem().NumField(); i++ {
+		field := scoped.Elem().Field(i)
+		if field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Pointer || !field.CanSet() {
+			continue
+		}
+		kept := reflect.MakeSlice(field.Type(), 0, field.Len())
+		for j := 0; j < field.Len(); j++ {
+			if _, ok := matched[field.Index(j).Interface()]; ok {
+				kept = reflect.Append(kept, field.Index(j))
+			}
+		}
+		field.Set(kept)
+	}
+	return scoped.Interface().(spec.Topology), nil
+}
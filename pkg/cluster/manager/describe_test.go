@@ -0,0 +1,92 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	assert := require.New(t)
+	specManager := spec.NewSpec(t.TempDir(), func() spec.Metadata {
+		return &spec.ClusterMeta{}
+	})
+	m := NewManager("tidb", specManager, logprinter.NewLogger(""))
+
+	topoFile := filepath.Join(t.TempDir(), "topology.yaml")
+	assert.Nil(os.WriteFile(topoFile, []byte(`
+global:
+  user: tidb
+  deploy_dir: deploy
+monitored:
+  node_exporter_port: 9101
+pd_servers:
+  - host: 172.16.5.1
+tikv_servers:
+  - host: 172.16.5.1
+  - host: 172.16.5.2
+    port: 20161
+tidb_servers:
+  - host: 172.16.5.3
+`), 0644))
+	topo := &spec.Specification{}
+	assert.Nil(spec.ParseTopologyYaml(topoFile, topo))
+	spec.ExpandRelativeDir(topo)
+	topo.TiKVServers[1].Patched = true
+	assert.Nil(specManager.SaveMeta("test", &spec.ClusterMeta{
+		User:     "tidb",
+		Version:  "v7.1.0",
+		Topology: topo,
+	}))
+
+	data, err := m.Describe("test", operator.Options{})
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(string(data), "# Topology of tidb cluster `test`, version v7.1.0\n"))
+	assert.NotContains(string(data), "patched")
+
+	// deploying the output reproduces the same layout
+	assert.Nil(os.WriteFile(topoFile, data, 0644))
+	described := &spec.Specification{}
+	assert.Nil(spec.ParseTopologyYaml(topoFile, described))
+	spec.ExpandRelativeDir(described)
+	topo.TiKVServers[1].Patched = false
+	assert.Equal(topo.GlobalOptions, described.GlobalOptions)
+	assert.Equal(topo.MonitoredOptions, described.MonitoredOptions)
+	assert.Equal(topo.PDServers, described.PDServers)
+	assert.Equal(topo.TiKVServers, described.TiKVServers)
+	assert.Equal(topo.TiDBServers, described.TiDBServers)
+
+	// only the matched instances are kept in the scoped topology
+	data, err = m.Describe("test", operator.Options{Roles: []string{"tikv"}, Nodes: []string{"172.16.5.2:20161"}})
+	assert.Nil(err)
+	assert.Nil(os.WriteFile(topoFile, data, 0644))
+	scoped := &spec.Specification{}
+	assert.Nil(spec.ParseTopologyYaml(topoFile, scoped))
+	assert.Empty(scoped.PDServers)
+	assert.Empty(scoped.TiDBServers)
+	assert.Len(scoped.TiKVServers, 1)
+	assert.Equal(20161, scoped.TiKVServers[0].Port)
+	assert.Equal(topo.GlobalOptions.User, scoped.GlobalOptions.User)
+
+	_, err = m.Describe("test", operator.Options{Roles: []string{"tiflash"}})
+	assert.NotNil(err)
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
@@ -26,7 +27,9 @@ import (
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/meta"
+	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // DestroyCluster destroy the cluster.
@@ -51,15 +54,29 @@ func (m *Manager) DestroyCluster(name string, gOpt operator.Options, destroyOpt
 		return err
 	}
 
+	if err := m.checkProtected(name, "destroy", base, gOpt); err != nil {
+		return err
+	}
+	confirmed, err := m.checkConfirmToken(name, "destroy", gOpt)
+	if err != nil {
+		return err
+	}
+	skipConfirm = skipConfirm || confirmed
+	m.showBanner(base)
 	if !skipConfirm {
 		m.logger.Warnf(color.HiRedString(tui.ASCIIArtWarning))
+		extra := ""
+		if len(destroyOpt.CleanupPaths) > 0 {
+			extra = fmt.Sprintf("\nThe paths in the kept deploy directories to be deleted as well: %s",
+				color.HiYellowString(strings.Join(destroyOpt.CleanupPaths, ", ")))
+		}
 		if err := tui.PromptForAnswerOrAbortError(
 			"Yes, I know my cluster and data will be deleted.",
 			fmt.Sprintf("This operation will destroy %s %s cluster %s and its data.",
 				m.sysName,
 				color.HiYellowString(base.Version),
 				color.HiYellowString(name),
-			)+"\nAre you sure to continue?",
+			)+extra+"\nAre you sure to continue?",
 		); err != nil {
 			return err
 		}
@@ -70,7 +87,7 @@ func (m *Manager) DestroyCluster(name string, gOpt operator.Options, destroyOpt
 	if err != nil {
 		return err
 	}
-	t := b.
+	b = b.
 		Func("StopCluster", func(ctx context.Context) error {
 			return operator.Stop(
 				ctx,
@@ -79,7 +96,16 @@ func (m *Manager) DestroyCluster(name string, gOpt operator.Options, destroyOpt
 				false, /* eviceLeader */
 				tlsCfg,
 			)
-		}).
+		})
+	if destroyOpt.CleanupTLS {
+		// removed before the instances as the deploy dirs of retained or
+		// imported instances are kept by destroy
+		tlsFileMap := getCleanupFiles(topo, cleanupFiles{cleanupTLS: true, forceTLS: true})
+		b = b.Func("CleanupTLS", func(ctx context.Context) error {
+			return operator.CleanupComponent(ctx, tlsFileMap, topo.BaseTopo().GlobalOptions.SystemdMode != spec.UserMode, operator.CleanupFilter{})
+		})
+	}
+	t := b.
 		Func("DestroyCluster", func(ctx context.Context) error {
 			return operator.Destroy(ctx, topo, destroyOpt)
 		}).
@@ -88,8 +114,11 @@ func (m *Manager) DestroyCluster(name string, gOpt operator.Options, destroyOpt
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "destroy"),
 	)
+	if m.dryRun("destroy", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -112,12 +141,26 @@ func (m *Manager) DestroyTombstone(
 	gOpt operator.Options,
 	skipConfirm bool,
 ) error {
+	_, err := m.PruneCluster(name, gOpt, skipConfirm)
+	return err
+}
+
+// PruneCluster destroys the instances which are fully decommissioned after
+// scale-in, i.e. the stores and binlog nodes in tombstone state, and removes
+// them from the topology. The offline instances still being decommissioned
+// are kept. It returns the IDs of the pruned instances, or the ones to be
+// pruned in dry run mode.
+func (m *Manager) PruneCluster(
+	name string,
+	gOpt operator.Options,
+	skipConfirm bool,
+) ([]string, error) {
 	metadata, err := m.meta(name)
 	// allow specific validation errors so that user can recover a broken
 	// cluster if it is somehow in a bad state.
 	if err != nil &&
 		!errors.Is(perrs.Cause(err), spec.ErrNoTiSparkMaster) {
-		return err
+		return nil, err
 	}
 
 	topo := metadata.GetTopology()
@@ -127,27 +170,35 @@ func (m *Manager) DestroyTombstone(
 	cluster := clusterMeta.Topology
 
 	if !operator.NeedCheckTombstone(cluster) {
-		return nil
+		m.logger.Infof("There is no instance to prune in cluster `%s`", name)
+		return nil, nil
 	}
 
 	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "prune"),
 	)
 	nodes, err := operator.DestroyTombstone(ctx, cluster, true /* returnNodesOnly */, gOpt, tlsCfg)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if pending := pendingOffline(cluster, nodes); len(pending) > 0 {
+		m.logger.Infof("These instances are still being decommissioned and will not be pruned: %s", strings.Join(pending, ", "))
+	}
+	if len(nodes) == 0 {
+		m.logger.Infof("There is no tombstone instance to prune in cluster `%s`", name)
+		return nil, nil
 	}
 
 	// Destroy ignore error and force exec
@@ -177,15 +228,42 @@ func (m *Manager) DestroyTombstone(
 			buildReloadPromAndGrafanaTasks(metadata.GetTopology(), m.logger, gOpt)...).
 		Build()
 
+	if m.dryRun("prune", t, gOpt) {
+		return nodes, nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
-			return err
+			return nil, err
 		}
-		return perrs.Trace(err)
+		return nil, perrs.Trace(err)
 	}
 
 	m.logger.Infof("Destroy success")
 
-	return nil
+	return nodes, nil
+}
+
+// pendingOffline returns the offline instances not in tombstone state yet
+func pendingOffline(cluster *spec.Specification, tombstones []string) []string {
+	done := set.NewStringSet(tombstones...)
+	pending := []string{}
+	add := func(offline bool, host string, port int) {
+		if id := utils.JoinHostPort(host, port); offline && !done.Exist(id) {
+			pending = append(pending, id)
+		}
+	}
+	for _, s := range cluster.TiKVServers {
+		add(s.Offline, s.Host, s.Port)
+	}
+	for _, s := range cluster.TiFlashServers {
+		add(s.Offline, s.Host, s.FlashServicePort)
+	}
+	for _, s := range cluster.PumpServers {
+		add(s.Offline, s.Host, s.Port)
+	}
+	for _, s := range cluster.Drainers {
+		add(s.Offline, s.Host, s.Port)
+	}
+	return pending
 }
@@ -0,0 +1,248 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"golang.org/x/sync/errgroup"
+)
+
+// listenerScript prints the listening ports and the working dir of the process listening
+// on each of them, the start scripts cd into the deploy dir so it tells the owner instance
+const listenerScript = `export PATH=$PATH:/usr/sbin:/sbin; ` +
+	`ss -ltnp 2>/dev/null | tail -n +2 | while read -r _ _ _ addr _ users; do ` +
+	`pid=$(echo "$users" | sed -n 's/.*pid=\([0-9]*\).*/\1/p'); ` +
+	`[ -n "$pid" ] || continue; ` +
+	`printf '%s\t%s\n' "${addr##*:}" "$(readlink /proc/$pid/cwd)"; ` +
+	`done`
+
+// TopologyDrift is a discrepancy between the stored topology and a live host
+type TopologyDrift struct {
+	Instance string `json:"instance"` // the instance ID, or the host if the host could not be inspected
+	Message  string `json:"message"`
+}
+
+// DiffTopology compares the listening ports, deploy dirs and deployed config of every
+// instance against the stored topology, and returns the discrepancies found
+func (m *Manager) DiffTopology(name string, gOpt operator.Options) ([]TopologyDrift, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	hosts := make(map[string][]spec.Instance)
+	for _, inst := range selectedInstances(topo, gOpt) {
+		hosts[inst.GetManageHost()] = append(hosts[inst.GetManageHost()], inst)
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	result := make([]TopologyDrift, 0)
+	t := b.
+		Func("DiffTopology", func(ctx context.Context) error {
+			errg, _ := errgroup.WithContext(ctx)
+			errg.SetLimit(gOpt.Concurrency)
+			for host, insts := range hosts {
+				host, insts := host, insts
+				errg.Go(func() error {
+					drifts := diffHostTopology(ctx, host, base.User, insts)
+					mu.Lock()
+					result = append(result, drifts...)
+					mu.Unlock()
+					return nil
+				})
+			}
+			return errg.Wait()
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return nil, err
+		}
+		return nil, perrs.Trace(err)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Instance < result[j].Instance
+	})
+	return result, nil
+}
+
+// diffHostTopology inspects the instances deployed on the host
+func diffHostTopology(ctx context.Context, host, deployUser string, insts []spec.Instance) []TopologyDrift {
+	e, found := ctxt.GetInner(ctx).GetExecutor(host)
+	if !found {
+		return []TopologyDrift{{Instance: host, Message: "no executor"}}
+	}
+
+	// sudo is needed to see the processes of other users
+	cmd := fmt.Sprintf("echo %s | base64 -d | bash", base64.StdEncoding.EncodeToString([]byte(listenerScript)))
+	stdout, stderr, err := e.Execute(ctx, cmd, true)
+	if err != nil {
+		_, msg := classifyConnError(err, stderr)
+		return []TopologyDrift{{Instance: host, Message: msg}}
+	}
+	listeners := parseListeners(string(stdout))
+
+	var drifts []TopologyDrift
+	for _, inst := range insts {
+		deployDir := spec.Abs(deployUser, inst.DeployDir())
+		ports := inst.UsedPorts()
+		dirExists, mentioned, err := inspectDeployDir(ctx, e, deployDir, ports)
+		if err != nil {
+			drifts = append(drifts, TopologyDrift{Instance: inst.ID(), Message: err.Error()})
+			continue
+		}
+		for _, msg := range instanceDrift(deployDir, ports, dirExists, mentioned, listeners) {
+			drifts = append(drifts, TopologyDrift{Instance: inst.ID(), Message: msg})
+		}
+	}
+	return drifts
+}
+
+// inspectDeployDir checks whether the deploy dir exists and which of the ports
+// are mentioned in the deployed config files and start scripts
+func inspectDeployDir(ctx context.Context, e ctxt.Executor, deployDir string, ports []int) (bool, set.StringSet, error) {
+	patterns := make([]string, 0, len(ports))
+	for _, p := range ports {
+		patterns = append(patterns, "-e "+strconv.Itoa(p))
+	}
+	cmd := fmt.Sprintf("[ -d %[1]s ] && echo dir; cat %[1]s/scripts/* %[1]s/conf/* 2>/dev/null | grep -ow %[2]s | sort -u; true",
+		shellQuote(deployDir), strings.Join(patterns, " "))
+	stdout, stderr, err := e.Execute(ctx, cmd, false)
+	if err != nil {
+		return false, nil, perrs.Annotatef(err, "failed to inspect %s, stderr: %s", deployDir, string(stderr))
+	}
+
+	dirExists := false
+	mentioned := set.NewStringSet()
+	for _, line := range strings.Fields(string(stdout)) {
+		if line == "dir" {
+			dirExists = true
+			continue
+		}
+		mentioned.Insert(line)
+	}
+	return dirExists, mentioned, nil
+}
+
+// parseListeners parses the output of listenerScript into a map of port to the working dir
+func parseListeners(output string) map[int]string {
+	listeners := make(map[int]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		port, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		listeners[port] = strings.TrimSpace(fields[1])
+	}
+	return listeners
+}
+
+// instanceDrift compares what the topology says about an instance with what is
+// found on the host, and describes every discrepancy
+func instanceDrift(deployDir string, ports []int, dirExists bool, mentioned set.StringSet, listeners map[int]string) []string {
+	var msgs []string
+	if !dirExists {
+		msgs = append(msgs, fmt.Sprintf("deploy dir %s does not exist", deployDir))
+	}
+
+	expected := make(map[int]bool)
+	var missing []int
+	for _, p := range ports {
+		expected[p] = true
+		if dirExists && !mentioned.Exist(strconv.Itoa(p)) {
+			msgs = append(msgs, fmt.Sprintf("config says port %d, but the deployed config and start script do not use it", p))
+		}
+		switch dir, ok := listeners[p]; {
+		case !ok:
+			missing = append(missing, p)
+		case dir != deployDir:
+			msgs = append(msgs, fmt.Sprintf("config says port %d, but it is listened by the process in %s", p, dir))
+		}
+	}
+
+	var extra []int
+	for p, dir := range listeners {
+		if dir == deployDir && !expected[p] {
+			extra = append(extra, p)
+		}
+	}
+	sort.Ints(extra)
+
+	if len(missing) == len(ports) && len(extra) == 0 {
+		if len(ports) > 0 {
+			msgs = append(msgs, "no process of the deploy dir is listening, the instance may be down")
+		}
+		return msgs
+	}
+	for i, p := range missing {
+		if i < len(extra) {
+			msgs = append(msgs, fmt.Sprintf("config says port %d, process listening on %d", p, extra[i]))
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("config says port %d, but nothing is listening on it", p))
+	}
+	for i := len(missing); i < len(extra); i++ {
+		msgs = append(msgs, fmt.Sprintf("process listening on port %d which is not in the topology", extra[i]))
+	}
+	return msgs
+}
+
+// PrintTopologyDrift prints the discrepancies found by DiffTopology
+func PrintTopologyDrift(name string, drifts []TopologyDrift) {
+	if len(drifts) == 0 {
+		fmt.Printf("No drift found between the topology of cluster %s and the hosts\n", name)
+		return
+	}
+	for _, d := range drifts {
+		fmt.Printf("instance %s: %s\n", d.Instance, d.Message)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListeners(t *testing.T) {
+	output := "4000\t/home/tidb/deploy/tidb-4000\n10080\t/home/tidb/deploy/tidb-4000\n22\t/\nmalformed\n"
+	require.Equal(t, map[int]string{
+		4000:  "/home/tidb/deploy/tidb-4000",
+		10080: "/home/tidb/deploy/tidb-4000",
+		22:    "/",
+	}, parseListeners(output))
+}
+
+func TestInstanceDrift(t *testing.T) {
+	dir := "/home/tidb/deploy/tidb-4000"
+	mentioned := set.NewStringSet("4000", "10080")
+
+	// everything matches
+	listeners := map[int]string{4000: dir, 10080: dir}
+	require.Empty(t, instanceDrift(dir, []int{4000, 10080}, true, mentioned, listeners))
+
+	// the port is changed manually
+	listeners = map[int]string{4001: dir, 10080: dir}
+	require.Equal(t, []string{
+		"config says port 4000, process listening on 4001",
+	}, instanceDrift(dir, []int{4000, 10080}, true, mentioned, listeners))
+
+	// the port is taken by another process
+	listeners = map[int]string{4000: "/opt/other", 10080: dir}
+	require.Equal(t, []string{
+		"config says port 4000, but it is listened by the process in /opt/other",
+	}, instanceDrift(dir, []int{4000, 10080}, true, mentioned, listeners))
+
+	// the instance is down and the dir is moved
+	require.Equal(t, []string{
+		"deploy dir " + dir + " does not exist",
+		"no process of the deploy dir is listening, the instance may be down",
+	}, instanceDrift(dir, []int{4000, 10080}, false, set.NewStringSet(), map[int]string{}))
+
+	// the deployed config does not match the topology
+	listeners = map[int]string{4000: dir, 10080: dir}
+	require.Equal(t, []string{
+		"config says port 10080, but the deployed config and start script do not use it",
+	}, instanceDrift(dir, []int{4000, 10080}, true, set.NewStringSet("4000"), listeners))
+}
@@ -72,6 +72,9 @@ type InstInfo struct {
 	NumaNode    string `json:"numa_node"`
 	NumaCores   string `json:"numa_cores"`
 	Version     string `json:"version"`
+	// RunningVersion is the version of the binary the running process is
+	// started from, it's only queried with ShowVersions
+	RunningVersion string `json:"running_version,omitempty"`
 
 	ComponentName string
 	Port          int
@@ -208,13 +211,14 @@ func (m *Manager) Display(dopt DisplayOption, opt operator.Options) error {
 		rowHead = append(rowHead, "Numa Node", "Numa Cores")
 	}
 	if dopt.ShowVersions {
-		rowHead = append(rowHead, "Version")
+		rowHead = append(rowHead, "Version", "Running Version")
 	}
 
 	rowHead = append(rowHead, "Data Dir", "Deploy Dir")
 	clusterTable = append(clusterTable, rowHead)
 
 	masterActive := make([]string, 0)
+	diverged := make([]string, 0)
 	for _, v := range clusterInstInfos {
 		row := []string{
 			color.CyanString(v.ID),
@@ -241,7 +245,12 @@ func (m *Manager) Display(dopt DisplayOption, opt operator.Options) error {
 			row = append(row, v.NumaNode, v.NumaCores)
 		}
 		if dopt.ShowVersions {
-			row = append(row, v.Version)
+			running := utils.Ternary(v.RunningVersion == "", "-", v.RunningVersion).(string)
+			if versionDiverged(v) {
+				running = color.RedString(running)
+				diverged = append(diverged, v.ID)
+			}
+			row = append(row, v.Version, running)
 		}
 
 		row = append(row, v.DataDir, v.DeployDir)
@@ -306,6 +315,10 @@ func (m *Manager) Display(dopt DisplayOption, opt operator.Options) error {
 
 	tui.PrintTable(clusterTable, true)
 	fmt.Printf("Total nodes: %d\n", len(clusterTable)-1)
+	if len(diverged) > 0 {
+		color.Yellow("\nWARN: %d instance(s) are running a version other than the intended one, they may not be restarted after an upgrade: %s",
+			len(diverged), strings.Join(diverged, ", "))
+	}
 
 	if t, ok := topo.(*spec.Specification); ok {
 		// Check if TiKV's label set correctly
@@ -644,6 +657,13 @@ func (m *Manager) GetClusterTopology(dopt DisplayOption, opt operator.Options) (
 			}
 		}
 
+		runningVersion := ""
+		if dopt.ShowVersions {
+			if e, found := ctxt.GetInner(ctx).GetExecutor(ins.GetManageHost()); found {
+				runningVersion = operator.GetRunningVersion(checkpoint.NewContext(ctx), e, ins, systemdMode)
+			}
+		}
+
 		// check if the role is patched
 		roleName := ins.Role()
 		// get extended name for TiFlash to distinguish disaggregated mode.
@@ -676,6 +696,8 @@ func (m *Manager) GetClusterTopology(dopt DisplayOption, opt operator.Options) (
 			NumaNode:      utils.Ternary(ins.GetNumaNode() == "", "-", ins.GetNumaNode()).(string),
 			NumaCores:     utils.Ternary(ins.GetNumaCores() == "", "-", ins.GetNumaCores()).(string),
 			Version:       ins.CalculateVersion(base.Version),
+
+			RunningVersion: runningVersion,
 		})
 		mu.Unlock()
 	}, opt.Concurrency)
@@ -695,6 +717,16 @@ func (m *Manager) GetClusterTopology(dopt DisplayOption, opt operator.Options) (
 	return clusterInstInfos, nil
 }
 
+// versionDiverged tells whether the instance is running a version other than
+// the intended one, e.g. it's not restarted in an upgrade, the nightly builds
+// are not compared as their versions are not the same as the intended one
+func versionDiverged(inst InstInfo) bool {
+	if inst.RunningVersion == "" || inst.Version == "" || inst.Version == utils.NightlyVersionAlias {
+		return false
+	}
+	return inst.RunningVersion != inst.Version
+}
+
 func formatInstanceStatus(status string) string {
 	lowercaseStatus := strings.ToLower(status)
 
@@ -64,3 +64,11 @@ func TestGetGrafanaURLStr(t *testing.T) {
 	})
 	assert.Equal(t, exist, false)
 }
+
+func TestVersionDiverged(t *testing.T) {
+	assert.False(t, versionDiverged(InstInfo{Version: "v7.1.0", RunningVersion: "v7.1.0"}))
+	assert.True(t, versionDiverged(InstInfo{Version: "v7.1.0", RunningVersion: "v6.5.0"}))
+	// unknown running version or nightly builds are not compared
+	assert.False(t, versionDiverged(InstInfo{Version: "v7.1.0"}))
+	assert.False(t, versionDiverged(InstInfo{Version: "nightly", RunningVersion: "v7.2.0-alpha-12-gabcdef"}))
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+)
+
+// dryRun prints the tasks of the operation instead of executing them if it's
+// running with --dry-run, the caller must return without side effects if it
+// returns true
+func (m *Manager) dryRun(op string, t task.Task, gOpt operator.Options) bool {
+	if !gOpt.DryRun {
+		return false
+	}
+	m.logger.Warnf("%s", color.YellowString("DRY RUN: %s is not executed, nothing is changed", op))
+	m.logger.Infof("Tasks to be executed:")
+	for _, line := range strings.Split(t.String(), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m.logger.Infof("  - %s", line)
+	}
+	return true
+}
+
+// dryRunChange prints the change of the operation instead of making it if it's
+// running with --dry-run, it's for the operations changing the local state of
+// the cluster directly instead of running tasks. The caller must return without
+// side effects if it returns true.
+func (m *Manager) dryRunChange(op, change string, gOpt operator.Options) bool {
+	if !gOpt.DryRun {
+		return false
+	}
+	m.logger.Warnf("%s", color.YellowString("DRY RUN: %s is not executed, nothing is changed", op))
+	m.logger.Infof("Changes to be made:")
+	m.logger.Infof("  - %s", change)
+	return true
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/fatih/color"
 	perrs "github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/meta"
 	"github.com/pingcap/tiup/pkg/tui"
@@ -36,7 +37,7 @@ type EditConfigOptions struct {
 }
 
 // EditConfig lets the user edit the cluster's config.
-func (m *Manager) EditConfig(name string, opt EditConfigOptions, skipConfirm bool) error {
+func (m *Manager) EditConfig(name string, opt EditConfigOptions, gOpt operator.Options, skipConfirm bool) error {
 	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
 		return err
 	}
@@ -62,6 +63,10 @@ func (m *Manager) EditConfig(name string, opt EditConfigOptions, skipConfirm boo
 		return nil
 	}
 
+	if m.dryRunChange("edit-config", fmt.Sprintf("save the new topology of cluster `%s`", name), gOpt) {
+		return nil
+	}
+
 	m.logger.Infof("Applying changes...")
 	metadata.SetTopology(newTopo)
 	err = m.specManager.SaveMeta(name, metadata)
@@ -100,8 +100,11 @@ func (m *Manager) Exec(name string, opt ExecOptions, gOpt operator.Options) erro
 	execCtx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "exec"),
 	)
+	if m.dryRun("exec", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(execCtx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
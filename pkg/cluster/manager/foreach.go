@@ -0,0 +1,86 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/checkpoint"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"golang.org/x/sync/errgroup"
+)
+
+// InstanceVisitor is the function called for each instance by ForEachInstance
+type InstanceVisitor func(ctx context.Context, inst spec.Instance, e ctxt.Executor) error
+
+// ForEachInstance builds SSH connections to the cluster and calls fn for every
+// instance selected by gOpt, i.e. matching the roles, nodes, labels and filter
+// and not excluded, at most gOpt.Concurrency instances are visited at the same time.
+func (m *Manager) ForEachInstance(name string, gOpt operator.Options, fn InstanceVisitor) error {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+
+	t := b.
+		Func("ForEachInstance", func(ctx context.Context) error {
+			errg, _ := errgroup.WithContext(ctx)
+			errg.SetLimit(ctxt.GetInner(ctx).Concurrency)
+			for _, inst := range selectedInstances(topo, gOpt) {
+				inst := inst
+				// the checkpoint part of context can't be shared between goroutines
+				nctx := checkpoint.NewContext(ctx)
+				errg.Go(func() error {
+					e, found := ctxt.GetInner(nctx).GetExecutor(inst.GetManageHost())
+					if !found {
+						return perrs.Errorf("no executor found for %s", inst.ID())
+					}
+					return fn(nctx, inst, e)
+				})
+			}
+			return errg.Wait()
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return perrs.Trace(err)
+	}
+	return nil
+}
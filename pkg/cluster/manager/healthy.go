@@ -0,0 +1,162 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// healthPollInterval is the interval between two rounds of health checks
+const healthPollInterval = 2 * time.Second
+
+// InstanceHealth is the health of an instance reported by its status API
+type InstanceHealth struct {
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Status  string `json:"status"`
+	Healthy bool   `json:"healthy"`
+}
+
+// ClusterHealth is the health report of a cluster
+type ClusterHealth struct {
+	Healthy   bool             `json:"healthy"`
+	Instances []InstanceHealth `json:"instances"`
+}
+
+// Unhealthy returns the instances not reported healthy
+func (h *ClusterHealth) Unhealthy() []InstanceHealth {
+	var res []InstanceHealth
+	for _, inst := range h.Instances {
+		if !inst.Healthy {
+			res = append(res, inst)
+		}
+	}
+	return res
+}
+
+// WaitClusterHealthy polls the status APIs of the instances matching the roles
+// and nodes in gOpt, e.g. the health of PD members and stores and the /status
+// of TiDB, until all of them report healthy or the timeout elapses. The last
+// health report is returned in both cases.
+func (m *Manager) WaitClusterHealthy(name string, gOpt operator.Options, timeout time.Duration) (*ClusterHealth, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+	topo := metadata.GetTopology()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return nil, err
+	}
+	return waitClusterHealthy(context.Background(), topo, gOpt, tlsCfg, timeout)
+}
+
+// waitClusterHealthy polls the health of the cluster until it's healthy
+func waitClusterHealthy(ctx context.Context, topo spec.Topology, gOpt operator.Options, tlsCfg *tls.Config, timeout time.Duration) (*ClusterHealth, error) {
+	var report *ClusterHealth
+	err := utils.RetryWithContext(ctx, func() error {
+		report = checkClusterHealth(ctx, topo, gOpt, tlsCfg)
+		if !report.Healthy {
+			return perrs.New("cluster is not healthy yet")
+		}
+		return nil
+	}, utils.RetryOption{
+		Delay:   healthPollInterval,
+		Timeout: timeout,
+	})
+	if err == nil {
+		return report, nil
+	}
+
+	unhealthy := []string{}
+	if report != nil {
+		for _, inst := range report.Unhealthy() {
+			unhealthy = append(unhealthy, inst.ID+" ("+inst.Status+")")
+		}
+	}
+	if ctx.Err() != nil {
+		return report, perrs.Annotatef(ctx.Err(), "stopped waiting for the cluster to be healthy, unhealthy instances: %s",
+			strings.Join(unhealthy, ", "))
+	}
+	return report, perrs.Errorf("cluster is not healthy after %s, unhealthy instances: %s",
+		timeout, strings.Join(unhealthy, ", "))
+}
+
+// checkClusterHealth queries the status of the instances once
+func checkClusterHealth(ctx context.Context, topo spec.Topology, gOpt operator.Options, tlsCfg *tls.Config) *ClusterHealth {
+	statusTimeout := time.Duration(gOpt.APITimeout) * time.Second
+	masterList := topo.BaseTopo().MasterList
+
+	// stores are queried from the PD members that are up
+	masterActive := make([]string, 0)
+	var mu sync.Mutex
+	topo.IterInstance(func(ins spec.Instance) {
+		if ins.ComponentName() != spec.ComponentPD {
+			return
+		}
+		if healthyStatus(ins.Status(ctx, statusTimeout, tlsCfg, masterList...)) {
+			mu.Lock()
+			masterActive = append(masterActive, utils.JoinHostPort(ins.GetManageHost(), ins.GetPort()))
+			mu.Unlock()
+		}
+	}, gOpt.Concurrency)
+
+	report := &ClusterHealth{Healthy: true}
+	for _, ins := range selectedInstances(topo, gOpt) {
+		status := ins.Status(ctx, statusTimeout, tlsCfg, masterActive...)
+		healthy := healthyStatus(status)
+		report.Instances = append(report.Instances, InstanceHealth{
+			ID:      ins.ID(),
+			Role:    ins.Role(),
+			Status:  status,
+			Healthy: healthy,
+		})
+		report.Healthy = report.Healthy && healthy
+	}
+	sort.Slice(report.Instances, func(i, j int) bool {
+		return report.Instances[i].ID < report.Instances[j].ID
+	})
+	return report
+}
+
+// healthyStatus tells whether the status reported by an instance is healthy
+func healthyStatus(status string) bool {
+	switch {
+	case strings.HasPrefix(status, "Up"), strings.HasPrefix(status, "Healthy"):
+		return true
+	case status == "-":
+		// the instance has no status API
+		return true
+	case status == "Tombstone", strings.Contains(status, "Offline"):
+		// the store is being scaled in, its health is not expected
+		return true
+	}
+	return false
+}
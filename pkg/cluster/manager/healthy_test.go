@@ -0,0 +1,37 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthyStatus(t *testing.T) {
+	assert := require.New(t)
+
+	for _, status := range []string{"Up", "Up|L", "Up|L|UI", "Healthy", "-", "Tombstone", "Pending Offline"} {
+		assert.True(healthyStatus(status), status)
+	}
+	for _, status := range []string{"Down", "N/A", "ERR", "Disconnected", "inactive"} {
+		assert.False(healthyStatus(status), status)
+	}
+
+	report := &ClusterHealth{Instances: []InstanceHealth{
+		{ID: "a", Healthy: true},
+		{ID: "b", Status: "Down"},
+	}}
+	assert.Equal([]InstanceHealth{{ID: "b", Status: "Down"}}, report.Unhealthy())
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// ConfigFile is a config file deployed on the host
+type ConfigFile struct {
+	Path    string
+	Content []byte
+}
+
+// FetchInstanceConfig reads the config files deployed in the conf dir of the instance
+func (m *Manager) FetchInstanceConfig(name, id string, gOpt operator.Options) (spec.Instance, []ConfigFile, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, nil, err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	var inst spec.Instance
+	topo.IterInstance(func(i spec.Instance) {
+		if i.ID() == id {
+			inst = i
+		}
+	})
+	if inst == nil {
+		return nil, nil, perrs.Errorf("instance %s not found in cluster %s", id, name)
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []ConfigFile
+	confDir := path.Join(spec.Abs(base.User, inst.DeployDir()), "conf")
+	t := b.
+		Func("FetchInstanceConfig", func(ctx context.Context) error {
+			e, found := ctxt.GetInner(ctx).GetExecutor(inst.GetManageHost())
+			if !found {
+				return task.ErrNoExecutor
+			}
+			// encode the content so each file takes exactly one line
+			cmd := fmt.Sprintf(`for f in %s/*; do [ -f "$f" ] && echo "$f $(base64 -w 0 "$f")"; done; true`, confDir)
+			stdout, stderr, err := e.Execute(ctx, cmd, false)
+			if err != nil {
+				return perrs.Annotatef(err, "failed to read %s on %s, stderr: %s", confDir, inst.GetManageHost(), string(stderr))
+			}
+			files, err = parseConfigFiles(string(stdout))
+			return err
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return nil, nil, err
+		}
+		return nil, nil, perrs.Trace(err)
+	}
+	return inst, files, nil
+}
+
+// ShowInstanceConfig prints the config files deployed for the instance, the main
+// config file is diffed against the config rendered from the topology if diff is set
+func (m *Manager) ShowInstanceConfig(name, id string, diff bool, gOpt operator.Options) error {
+	inst, files, err := m.FetchInstanceConfig(name, id, gOpt)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		m.logger.Warnf("No config file is found for instance %s", id)
+		return nil
+	}
+
+	var rendered []byte
+	if diff {
+		metadata, err := m.meta(name)
+		if err != nil {
+			return err
+		}
+		topo, ok := metadata.GetTopology().(*spec.Specification)
+		if !ok {
+			return perrs.Errorf("diffing config is not supported for %s topology", metadata.GetTopology().Type())
+		}
+		configs, err := spec.RenderInstanceConfigs(topo)
+		if err != nil {
+			return err
+		}
+		for _, cfg := range configs {
+			if cfg.Instance.ID() == id {
+				rendered = cfg.Content
+			}
+		}
+	}
+
+	mainFile := inst.ComponentName() + ".toml"
+	for _, f := range files {
+		fmt.Printf("%s %s\n", color.CyanString("#"), color.CyanString("%s:%s", inst.GetManageHost(), f.Path))
+		if diff && rendered != nil && path.Base(f.Path) == mainFile {
+			fmt.Println(color.CyanString("# diff against the config rendered from the topology"))
+			utils.ShowDiff(string(rendered), string(f.Content), os.Stdout)
+			fmt.Println()
+			continue
+		}
+		fmt.Println(strings.TrimRight(string(f.Content), "\n"))
+		fmt.Println()
+	}
+	return nil
+}
+
+// parseConfigFiles parses the lines of file path and base64 encoded content
+func parseConfigFiles(output string) ([]ConfigFile, error) {
+	var files []ConfigFile
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		f := ConfigFile{Path: fields[0]}
+		if len(fields) > 1 {
+			content, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, perrs.Annotatef(err, "failed to decode %s", fields[0])
+			}
+			f.Content = content
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
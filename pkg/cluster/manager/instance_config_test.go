@@ -0,0 +1,39 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigFiles(t *testing.T) {
+	enc := base64.StdEncoding.EncodeToString
+	output := "/deploy/conf/tidb.toml " + enc([]byte("[log]\nlevel = \"info\"\n")) + "\n" +
+		"/deploy/conf/empty.toml\n" +
+		"/deploy/conf/a.toml " + enc([]byte("a = 1")) + "\n"
+
+	files, err := parseConfigFiles(output)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigFile{
+		{Path: "/deploy/conf/a.toml", Content: []byte("a = 1")},
+		{Path: "/deploy/conf/empty.toml"},
+		{Path: "/deploy/conf/tidb.toml", Content: []byte("[log]\nlevel = \"info\"\n")},
+	}, files)
+
+	_, err = parseConfigFiles("/deploy/conf/bad.toml !!!")
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	perrs "github.com/pingcap/errors"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+// labelSelectionHint lists the instances selected by the labels in gOpt for
+// the confirm prompt, it's empty if no label is specified. An error is
+// returned if no instance matches.
+func labelSelectionHint(topo spec.Topology, gOpt operator.Options) (string, error) {
+	if len(gOpt.Labels) == 0 {
+		return "", nil
+	}
+
+	labels := make([]string, 0, len(gOpt.Labels))
+	for k, v := range gOpt.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+
+	insts := selectedInstances(topo, gOpt)
+	if len(insts) == 0 {
+		return "", perrs.Errorf("no instance matches the labels %s", strings.Join(labels, ","))
+	}
+	ids := make([]string, 0, len(insts))
+	for _, inst := range insts {
+		ids = append(ids, inst.ID())
+	}
+	return fmt.Sprintf("\nInstances matching labels %s: %s",
+		strings.Join(labels, ","), color.HiYellowString(strings.Join(ids, ","))), nil
+}
+
+// exclusionHint lists the excluded nodes and roles in gOpt for the confirm
+// prompt, it's empty if nothing is excluded. It also tells whether all the
+// instances selected are excluded, which leaves nothing to do.
+func exclusionHint(topo spec.Topology, gOpt operator.Options) (string, bool) {
+	if len(gOpt.ExcludeNodes) == 0 && len(gOpt.ExcludeRoles) == 0 {
+		return "", false
+	}
+	if len(selectedInstances(topo, gOpt)) == 0 {
+		included := gOpt
+		included.ExcludeNodes, included.ExcludeRoles = nil, nil
+		if len(selectedInstances(topo, included)) > 0 {
+			return "", true
+		}
+	}
+	return fmt.Sprintf("\nExcluding nodes: %s, roles: %s",
+		color.HiYellowString(strings.Join(gOpt.ExcludeNodes, ",")),
+		color.HiYellowString(strings.Join(gOpt.ExcludeRoles, ","))), false
+}
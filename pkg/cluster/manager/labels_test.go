@@ -0,0 +1,95 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestLabelSelection(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+tidb_servers:
+  - host: 172.16.5.1
+    instance_labels:
+      az: a
+      canary: "true"
+  - host: 172.16.5.2
+    instance_labels:
+      az: b
+pd_servers:
+  - host: 172.16.5.1
+`), &topo)
+	assert.Nil(err)
+
+	hint, err := labelSelectionHint(&topo, operator.Options{})
+	assert.Nil(err)
+	assert.Empty(hint)
+
+	gOpt := operator.Options{Labels: map[string]string{"az": "a"}}
+	insts := selectedInstances(&topo, gOpt)
+	assert.Len(insts, 1)
+	assert.Equal("172.16.5.1:4000", insts[0].ID())
+	hint, err = labelSelectionHint(&topo, gOpt)
+	assert.Nil(err)
+	assert.Contains(hint, "az=a")
+
+	// all the labels must match
+	gOpt.Labels["canary"] = "false"
+	_, err = labelSelectionHint(&topo, gOpt)
+	assert.NotNil(err)
+}
+
+func TestExclusion(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+tidb_servers:
+  - host: 172.16.5.1
+    instance_labels:
+      az: a
+  - host: 172.16.5.2
+    instance_labels:
+      az: b
+pd_servers:
+  - host: 172.16.5.1
+`), &topo)
+	assert.Nil(err)
+
+	hint, all := exclusionHint(&topo, operator.Options{})
+	assert.Empty(hint)
+	assert.False(all)
+
+	gOpt := operator.Options{ExcludeNodes: []string{"172.16.5.2:4000"}, ExcludeRoles: []string{"pd"}}
+	insts := selectedInstances(&topo, gOpt)
+	assert.Len(insts, 1)
+	assert.Equal("172.16.5.1:4000", insts[0].ID())
+	hint, all = exclusionHint(&topo, gOpt)
+	assert.False(all)
+	assert.Contains(hint, "172.16.5.2:4000")
+
+	// the exclusions are applied after the include filters
+	gOpt = operator.Options{Roles: []string{"tidb"}, Labels: map[string]string{"az": "b"}, ExcludeNodes: []string{"172.16.5.2:4000"}}
+	assert.Empty(selectedInstances(&topo, gOpt))
+	_, all = exclusionHint(&topo, gOpt)
+	assert.True(all)
+}
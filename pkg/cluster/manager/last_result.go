@@ -0,0 +1,130 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// lastResult is the outcome of the last start, stop or restart of a cluster
+type lastResult struct {
+	Operation string                    `json:"operation"`
+	Time      time.Time                 `json:"time"`
+	Targets   []string                  `json:"targets"` // the instances selected by the operation
+	Result    *operator.OperationResult `json:"result"`
+}
+
+// failed returns the targets not succeeded in the final phase of the operation,
+// including those not tried at all as the operation was aborted before them
+func (r *lastResult) failed() []string {
+	phase := r.Operation
+	if phase == "restart" {
+		phase = "start"
+	}
+	succeeded := set.NewStringSet()
+	if r.Result != nil {
+		for _, ins := range r.Result.Instances {
+			if ins.Operation == phase && ins.Success {
+				succeeded.Insert(ins.ID)
+			}
+		}
+	}
+	failed := []string{}
+	for _, id := range r.Targets {
+		if !succeeded.Exist(id) {
+			failed = append(failed, id)
+		}
+	}
+	return failed
+}
+
+// trackResult makes sure the outcome of the operation is collected, it
+// returns the function to save it as the last result of the cluster
+func (m *Manager) trackResult(name, operation string, topo spec.Topology, gOpt *operator.Options) func() {
+	if gOpt.Result == nil {
+		gOpt.Result = &operator.OperationResult{}
+	}
+	targets := []string{}
+	for _, inst := range selectedInstances(topo, *gOpt) {
+		targets = append(targets, inst.ID())
+	}
+	begin := m.clock.Now()
+	result := gOpt.Result
+
+	return func() {
+		data, err := json.MarshalIndent(&lastResult{
+			Operation: operation,
+			Time:      begin,
+			Targets:   targets,
+			Result:    result,
+		}, "", "  ")
+		if err == nil {
+			err = utils.WriteFile(m.specManager.Path(name, spec.LastResultName), data, 0644)
+		}
+		if err != nil {
+			m.logger.Warnf("Failed to save the result of %s: %s", operation, err)
+		}
+	}
+}
+
+// applyOnlyFailed selects the instances not succeeded in the last run of the
+// operation if it's required in gOpt
+func (m *Manager) applyOnlyFailed(name, operation string, gOpt *operator.Options) error {
+	if !gOpt.OnlyFailed {
+		return nil
+	}
+	if len(gOpt.Roles) > 0 || len(gOpt.Nodes) > 0 || len(gOpt.Labels) > 0 {
+		return perrs.New("--only-failed can't be used together with --role, --node or --label")
+	}
+
+	noFailed := func(format string, args ...any) error {
+		return errorNoFailedResult.New(format, args...).
+			WithProperty(tui.SuggestionFromFormat("Please run `%s %s %s` without --only-failed", tui.OsArgs0(), operation, name))
+	}
+
+	data, err := os.ReadFile(m.specManager.Path(name, spec.LastResultName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return noFailed("No previous %s of cluster `%s` is recorded", operation, name)
+		}
+		return perrs.AddStack(err)
+	}
+	last := &lastResult{}
+	if err := json.Unmarshal(data, last); err != nil {
+		return perrs.Annotatef(err, "failed to parse the last result of cluster `%s`", name)
+	}
+	if last.Operation != operation {
+		return noFailed("The last operation on cluster `%s` is %s instead of %s", name, last.Operation, operation)
+	}
+
+	failed := last.failed()
+	if len(failed) == 0 {
+		return noFailed("All the instances succeeded in the last %s of cluster `%s` at %s",
+			operation, name, last.Time.Format(time.RFC3339))
+	}
+	m.logger.Infof("Only %s the instances not succeeded in the last %s at %s: %s",
+		operation, operation, last.Time.Format(time.RFC3339), strings.Join(failed, ","))
+	gOpt.Nodes = failed
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joomcode/errorx"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnlyFailed(t *testing.T) {
+	assert := require.New(t)
+	specManager := spec.NewSpec(t.TempDir(), func() spec.Metadata {
+		return &spec.ClusterMeta{}
+	})
+	m := NewManager("tidb", specManager, logprinter.NewLogger(""))
+	assert.Nil(os.MkdirAll(specManager.Path("test"), 0755))
+
+	topo := &spec.Specification{
+		TiDBServers: []*spec.TiDBSpec{
+			{Host: "172.16.5.1", Port: 4000},
+			{Host: "172.16.5.2", Port: 4000},
+			{Host: "172.16.5.3", Port: 4000},
+		},
+	}
+
+	// not required
+	gOpt := operator.Options{}
+	assert.Nil(m.applyOnlyFailed("test", "start", &gOpt))
+	assert.Empty(gOpt.Nodes)
+
+	gOpt = operator.Options{OnlyFailed: true}
+	err := m.applyOnlyFailed("test", "start", &gOpt)
+	assert.True(errorx.IsOfType(err, errorNoFailedResult))
+
+	// the third instance is not tried at all
+	gOpt = operator.Options{Result: &operator.OperationResult{
+		Instances: []operator.InstanceResult{
+			{ID: "172.16.5.1:4000", Operation: "start", Success: true},
+			{ID: "172.16.5.2:4000", Operation: "start", Success: false},
+		},
+	}}
+	m.trackResult("test", "start", topo, &gOpt)()
+
+	gOpt = operator.Options{OnlyFailed: true}
+	assert.Nil(m.applyOnlyFailed("test", "start", &gOpt))
+	assert.Equal([]string{"172.16.5.2:4000", "172.16.5.3:4000"}, gOpt.Nodes)
+
+	// the last operation must be the same
+	gOpt = operator.Options{OnlyFailed: true}
+	err = m.applyOnlyFailed("test", "stop", &gOpt)
+	assert.True(errorx.IsOfType(err, errorNoFailedResult))
+
+	gOpt = operator.Options{OnlyFailed: true, Roles: []string{"tidb"}}
+	assert.NotNil(m.applyOnlyFailed("test", "start", &gOpt))
+
+	// the stopped instances don't count as started in a restart
+	gOpt = operator.Options{Result: &operator.OperationResult{}}
+	for _, inst := range topo.TiDBServers {
+		id := inst.Host + ":4000"
+		gOpt.Result.Instances = append(gOpt.Result.Instances,
+			operator.InstanceResult{ID: id, Operation: "stop", Success: true},
+			operator.InstanceResult{ID: id, Operation: "start", Success: true})
+	}
+	m.trackResult("test", "restart", topo, &gOpt)()
+	gOpt = operator.Options{OnlyFailed: true}
+	err = m.applyOnlyFailed("test", "restart", &gOpt)
+	assert.True(errorx.IsOfType(err, errorNoFailedResult))
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// SetMaintenanceWindows sets the maintenance windows of the cluster, stopping
+// and restarting the cluster are refused outside them. Empty windows remove
+// the restriction.
+func (m *Manager) SetMaintenanceWindows(name string, windows []string, gOpt operator.Options) error {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return err
+	}
+	if _, err := spec.ParseMaintenanceWindows(windows); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	base := metadata.GetBaseMeta()
+	if base.MaintenanceWindows == nil {
+		return errorProtectUnsupported.New("Cluster `%s` does not support maintenance windows", name)
+	}
+	if m.dryRunChange("maintenance-window", fmt.Sprintf("set maintenance windows of cluster `%s` to [%s]", name, strings.Join(windows, ", ")), gOpt) {
+		return nil
+	}
+	*base.MaintenanceWindows = windows
+	if err := m.specManager.SaveMeta(name, metadata); err != nil {
+		return err
+	}
+
+	if len(windows) == 0 {
+		m.logger.Infof("Maintenance windows of cluster `%s` are removed", name)
+	} else {
+		m.logger.Infof("Maintenance windows of cluster `%s` are set to %s", name, strings.Join(windows, ", "))
+	}
+	return nil
+}
+
+// checkMaintenanceWindow refuses the disruptive operation outside the
+// maintenance windows of the cluster unless it's forced in gOpt
+func (m *Manager) checkMaintenanceWindow(name, operation string, base *spec.BaseMeta, gOpt operator.Options) error {
+	if base == nil || base.MaintenanceWindows == nil || len(*base.MaintenanceWindows) == 0 {
+		return nil
+	}
+	windows, err := spec.ParseMaintenanceWindows(*base.MaintenanceWindows)
+	if err != nil {
+		return err
+	}
+
+	now := m.clock.Now()
+	var next time.Time
+	for _, w := range windows {
+		if w.Contains(now) {
+			return nil
+		}
+		if start := w.NextStart(now); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	if gOpt.Force {
+		m.logger.Warnf("Cluster `%s` is outside its maintenance windows, %s it anyway as required", name, operation)
+		return nil
+	}
+	return errorOutsideMaintenance.
+		New("Cluster `%s` is outside its maintenance windows %s, refuse to %s it, the next window starts at %s",
+			name, spec.FormatMaintenanceWindows(windows), operation, next.Format("2006-01-02 15:04 MST")).
+		WithProperty(tui.SuggestionFromFormat(
+			"Please retry in the maintenance window, or add `--force` if you really want to %s it now", operation))
+}
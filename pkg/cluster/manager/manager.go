@@ -15,12 +15,15 @@ package manager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
 	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/audit"
 	"github.com/pingcap/tiup/pkg/cluster/ctxt"
 	"github.com/pingcap/tiup/pkg/cluster/executor"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
@@ -30,6 +33,7 @@ import (
 	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/tui"
 	"github.com/pingcap/tiup/pkg/utils"
+	"gopkg.in/yaml.v2"
 )
 
 var (
@@ -39,13 +43,30 @@ var (
 	errNSRename              = errorx.NewNamespace("rename")
 	errorRenameNameNotExist  = errNSRename.NewType("name_not_exist", utils.ErrTraitPreCheck)
 	errorRenameNameDuplicate = errNSRename.NewType("name_dup", utils.ErrTraitPreCheck)
+
+	errNSProtect            = errorx.NewNamespace("protect")
+	errorClusterProtected   = errNSProtect.NewType("protected", utils.ErrTraitPreCheck)
+	errorProtectUnsupported = errNSProtect.NewType("unsupported", utils.ErrTraitPreCheck)
+	errorStopLastPD         = errNSProtect.NewType("last_pd", utils.ErrTraitPreCheck)
+	errorOutsideMaintenance = errNSProtect.NewType("outside_maintenance_window", utils.ErrTraitPreCheck)
+	errorConfirmToken       = errNSProtect.NewType("confirm_token_mismatch", utils.ErrTraitPreCheck)
+
+	errNSCleanup          = errorx.NewNamespace("cleanup")
+	errorCleanupSymlinked = errNSCleanup.NewType("symlinked_dir", utils.ErrTraitPreCheck)
+
+	errNSLastResult     = errorx.NewNamespace("last_result")
+	errorNoFailedResult = errNSLastResult.NewType("no_failed", utils.ErrTraitPreCheck)
 )
 
+// IgnoreProtectionFlag is the flag to run destructive operations on a protected cluster
+const IgnoreProtectionFlag = "i-know-what-im-doing"
+
 // Manager to deploy a cluster.
 type Manager struct {
 	sysName     string
 	specManager *spec.SpecManager
 	logger      *logprinter.Logger
+	clock       utils.Clock
 }
 
 // NewManager create a Manager.
@@ -58,9 +79,26 @@ func NewManager(
 		sysName:     sysName,
 		specManager: specManager,
 		logger:      logger,
+		clock:       utils.RealClock,
 	}
 }
 
+// SetClock sets the clock used by time based logic of the manager
+func (m *Manager) SetClock(c utils.Clock) {
+	m.clock = c
+}
+
+// Clock returns the clock of the manager
+func (m *Manager) Clock() utils.Clock {
+	return m.clock
+}
+
+// operationLogger returns the logger used by an operation on the cluster, the
+// cluster name and the operation are added to the structured log lines
+func (m *Manager) operationLogger(name, operation string) *logprinter.Logger {
+	return m.logger.With("cluster", name).With("operation", operation)
+}
+
 func (m *Manager) meta(name string) (metadata spec.Metadata, err error) {
 	exist, err := m.specManager.Exist(name)
 	if err != nil {
@@ -76,10 +114,32 @@ func (m *Manager) meta(name string) (metadata spec.Metadata, err error) {
 	if err != nil {
 		return metadata, err
 	}
+	recordTopologyHash(metadata.GetTopology())
 
 	return metadata, nil
 }
 
+// recordTopologyHash records the hash of the topology into the audit log of the
+// operation, so that the topology acted upon could be told by old records
+func recordTopologyHash(topo spec.Topology) {
+	data, err := yaml.Marshal(topo)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	audit.SetTopologyHash(hex.EncodeToString(sum[:8]))
+}
+
+// showBanner displays the environment banner of the cluster if it is set,
+// it's logged even if the confirmation is skipped
+func (m *Manager) showBanner(base *spec.BaseMeta) {
+	if base == nil || base.Banner == "" {
+		return
+	}
+	border := strings.Repeat("=", len(base.Banner)+4)
+	m.logger.Warnf("%s", color.HiRedString("%s\n  %s\n%s", border, base.Banner, border))
+}
+
 func (m *Manager) confirmTopology(name, version string, topo spec.Topology, patchedRoles set.StringSet) error {
 	m.logger.Infof("Please confirm your topology:")
 
@@ -149,6 +209,10 @@ You may read the OpenJDK doc for a reference: https://openjdk.java.net/install/
 }
 
 func (m *Manager) sshTaskBuilder(name string, topo spec.Topology, user string, gOpt operator.Options) (*task.Builder, error) {
+	if err := checkAllowedHosts(topo, gOpt); err != nil {
+		return nil, err
+	}
+
 	var p *tui.SSHConnectionProps = &tui.SSHConnectionProps{}
 	if gOpt.SSHType != executor.SSHTypeNone && len(gOpt.SSHProxyHost) != 0 {
 		var err error
@@ -179,6 +243,28 @@ func (m *Manager) sshTaskBuilder(name string, topo spec.Topology, user string, g
 		), nil
 }
 
+// checkAllowedHosts aborts the operation before connecting to any host if
+// some of the hosts are out of the allow-list. The monitoring agents are
+// deployed on the hosts of instances, so they are covered as well.
+func checkAllowedHosts(topo spec.Topology, gOpt operator.Options) error {
+	sshType := gOpt.SSHType
+	if sshType == "" {
+		sshType = topo.BaseTopo().GlobalOptions.SSHType
+	}
+	if sshType == executor.SSHTypeNone {
+		return nil
+	}
+	hosts := []string{gOpt.SSHProxyHost}
+	topo.IterInstance(func(inst spec.Instance) {
+		// no ssh connection is made to the control node itself
+		if sshType == executor.SSHTypeAuto && executor.IsLocalHost(inst.GetManageHost()) {
+			return
+		}
+		hosts = append(hosts, inst.GetManageHost())
+	})
+	return executor.CheckAllowedHosts(hosts...)
+}
+
 // fillHost full host cpu-arch and kernel-name
 func (m *Manager) fillHost(s, p *tui.SSHConnectionProps, topo spec.Topology, gOpt *operator.Options, user string, sudo bool) error {
 	if err := m.fillHostArchOrOS(s, p, topo, gOpt, user, spec.FullArchType, sudo); err != nil {
@@ -107,13 +107,20 @@ func (m *Manager) Patch(name string, packagePath string, opt operator.Options, o
 			// TBD: should patch be treated as an upgrade?
 			return operator.Upgrade(ctx, topo, opt, tlsCfg, base.Version, base.Version)
 		}).
+		Func("RecordBinaryChecksums", func(ctx context.Context) error {
+			recordBinaryChecksums(ctx, m.logger, base, insts)
+			return nil
+		}).
 		Build()
 
 	ctx := ctxt.New(
 		context.Background(),
 		opt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "patch"),
 	)
+	if m.dryRun("patch", t, opt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
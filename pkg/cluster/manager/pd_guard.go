@@ -0,0 +1,80 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// pdHealthTimeout is the timeout to check whether a PD out of the scope is running
+const pdHealthTimeout = 5 * time.Second
+
+// checkLastPD refuses to stop all the running PD instances while some other instances
+// are kept running, as the cluster loses its control plane and can not serve anymore.
+// Stopping the whole cluster is not affected as nothing is left to depend on PD.
+func (m *Manager) checkLastPD(topo spec.Topology, gOpt operator.Options, tlsCfg *tls.Config) error {
+	if gOpt.Force {
+		return nil
+	}
+
+	inScope := set.NewStringSet()
+	for _, inst := range selectedInstances(topo, gOpt) {
+		inScope.Insert(inst.ID())
+	}
+	total := 0
+	var stopping, others []spec.Instance
+	topo.IterInstance(func(inst spec.Instance) {
+		total++
+		if inst.ComponentName() != spec.ComponentPD {
+			return
+		}
+		if inScope.Exist(inst.ID()) {
+			stopping = append(stopping, inst)
+		} else {
+			others = append(others, inst)
+		}
+	})
+	if len(stopping) == 0 || len(inScope) == total {
+		return nil
+	}
+
+	ctx := ctxt.New(context.Background(), 0, m.logger)
+	for _, inst := range others {
+		addr := utils.JoinHostPort(inst.GetManageHost(), inst.GetPort())
+		if api.NewPDClient(ctx, []string{addr}, pdHealthTimeout, tlsCfg).CheckHealth() == nil {
+			return nil
+		}
+	}
+
+	ids := make([]string, 0, len(stopping))
+	for _, inst := range stopping {
+		ids = append(ids, inst.ID())
+	}
+	return errorStopLastPD.
+		New("Stopping %s would leave no PD running, the cluster would lose its control plane: "+
+			"TiKV and TiDB can not get timestamps or route requests and stop serving until a PD is started again",
+			strings.Join(ids, ",")).
+		WithProperty(tui.SuggestionFromString("Please make sure at least one PD keeps running, or add `--force` if this is intended"))
+}
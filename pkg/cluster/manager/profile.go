@@ -0,0 +1,51 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// maxProfileCommandLen is the max length of the slowest command shown in the profile
+const maxProfileCommandLen = 60
+
+// PrintSSHProfile prints the statistics of commands executed on hosts, see
+// executor.EnableMetrics for collecting them
+func PrintSSHProfile(metrics []executor.HostMetrics) {
+	if len(metrics) == 0 {
+		fmt.Println("No remote command is executed")
+		return
+	}
+	table := [][]string{{"Host", "Commands", "Total", "Average", "Slowest", "Slowest Command"}}
+	for _, m := range metrics {
+		cmd := strings.Join(strings.Fields(m.SlowestCommand), " ")
+		if len(cmd) > maxProfileCommandLen {
+			cmd = cmd[:maxProfileCommandLen-3] + "..."
+		}
+		table = append(table, []string{
+			m.Host,
+			fmt.Sprint(m.Commands),
+			m.Total.Round(time.Millisecond).String(),
+			(m.Total / time.Duration(m.Commands)).Round(time.Millisecond).String(),
+			m.Slowest.Round(time.Millisecond).String(),
+			cmd,
+		})
+	}
+	tui.PrintTable(table, true)
+}
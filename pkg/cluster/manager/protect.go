@@ -0,0 +1,89 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// SetProtected marks or unmarks the cluster as protected, destructive
+// operations are refused on a protected cluster unless explicitly overridden
+func (m *Manager) SetProtected(name string, protected bool, gOpt operator.Options) error {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	base := metadata.GetBaseMeta()
+	if base.Protected == nil {
+		return errorProtectUnsupported.New("Cluster `%s` does not support protection", name)
+	}
+	if m.dryRunChange("protect", fmt.Sprintf("set protected of cluster `%s` to %v", name, protected), gOpt) {
+		return nil
+	}
+	*base.Protected = protected
+	if err := m.specManager.SaveMeta(name, metadata); err != nil {
+		return err
+	}
+
+	if protected {
+		m.logger.Infof("Cluster `%s` is protected now", name)
+	} else {
+		m.logger.Infof("Cluster `%s` is not protected any more", name)
+	}
+	return nil
+}
+
+// checkProtected refuses the destructive operation on a protected cluster
+// unless the protection is ignored in gOpt
+func (m *Manager) checkProtected(name, operation string, base *spec.BaseMeta, gOpt operator.Options) error {
+	if base == nil || base.Protected == nil || !*base.Protected {
+		return nil
+	}
+	if gOpt.IgnoreProtection {
+		m.logger.Warnf("Cluster `%s` is protected, %s it anyway as required", name, operation)
+		return nil
+	}
+	return errorClusterProtected.
+		New("Cluster `%s` is protected, refuse to %s it", name, operation).
+		WithProperty(tui.SuggestionFromFormat(
+			"Please run `%[1]s unprotect %[2]s` first, or add `--%[3]s` if you really want to %[4]s it",
+			tui.OsArgs0(), name, IgnoreProtectionFlag, operation))
+}
+
+// checkConfirmToken checks the confirmation token in gOpt for scripted
+// destructive operations, which must be the name of the cluster. It tells
+// whether the operation is confirmed by the token, the interactive prompt
+// should be skipped then.
+func (m *Manager) checkConfirmToken(name, operation string, gOpt operator.Options) (bool, error) {
+	if gOpt.ConfirmToken == "" {
+		return false, nil
+	}
+	if gOpt.ConfirmToken != name {
+		return false, errorConfirmToken.
+			New("The confirmation token `%s` does not match the cluster name, refuse to %s cluster `%s`", gOpt.ConfirmToken, operation, name).
+			WithProperty(tui.SuggestionFromFormat("Please pass the name of the cluster to `--confirm-token` to confirm the %s", operation))
+	}
+	m.logger.Infof("The %s of cluster `%s` is confirmed by the token", operation, name)
+	return true, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joomcode/errorx"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckProtected(t *testing.T) {
+	assert := require.New(t)
+	m := NewManager("tidb", nil, logprinter.NewLogger(""))
+
+	cmeta := &spec.ClusterMeta{}
+	base := cmeta.GetBaseMeta()
+	assert.Nil(m.checkProtected("test", "stop", base, operator.Options{}))
+
+	*base.Protected = true
+	assert.True(cmeta.Protected)
+	err := m.checkProtected("test", "stop", base, operator.Options{})
+	assert.NotNil(err)
+	assert.True(errorx.IsOfType(err, errorClusterProtected))
+	assert.Nil(m.checkProtected("test", "stop", base, operator.Options{IgnoreProtection: true}))
+
+	// metadata without protection support is never protected
+	assert.Nil(m.checkProtected("test", "stop", &spec.BaseMeta{}, operator.Options{}))
+}
+
+func TestCheckMaintenanceWindow(t *testing.T) {
+	assert := require.New(t)
+	m := NewManager("tidb", nil, logprinter.NewLogger(""))
+
+	cmeta := &spec.ClusterMeta{}
+	base := cmeta.GetBaseMeta()
+	assert.Nil(m.checkMaintenanceWindow("test", "stop", base, operator.Options{}))
+
+	cmeta.MaintenanceWindows = []string{"Sat,Sun 02:00-06:00"}
+	// a Saturday in the window
+	m.SetClock(utils.FixedClock(time.Date(2023, 6, 3, 3, 0, 0, 0, time.Local)))
+	assert.Nil(m.checkMaintenanceWindow("test", "stop", base, operator.Options{}))
+
+	// a Friday outside the window
+	m.SetClock(utils.FixedClock(time.Date(2023, 6, 2, 3, 0, 0, 0, time.Local)))
+	err := m.checkMaintenanceWindow("test", "stop", base, operator.Options{})
+	assert.NotNil(err)
+	assert.True(errorx.IsOfType(err, errorOutsideMaintenance))
+	assert.Contains(err.Error(), "the next window starts at 2023-06-03 02:00")
+	assert.Nil(m.checkMaintenanceWindow("test", "stop", base, operator.Options{Force: true}))
+}
+
+func TestCheckConfirmToken(t *testing.T) {
+	assert := require.New(t)
+	m := NewManager("tidb", nil, logprinter.NewLogger(""))
+
+	confirmed, err := m.checkConfirmToken("test", "destroy", operator.Options{})
+	assert.Nil(err)
+	assert.False(confirmed)
+
+	confirmed, err = m.checkConfirmToken("test", "destroy", operator.Options{ConfirmToken: "test"})
+	assert.Nil(err)
+	assert.True(confirmed)
+
+	confirmed, err = m.checkConfirmToken("test", "destroy", operator.Options{ConfirmToken: "prod"})
+	assert.True(errorx.IsOfType(err, errorConfirmToken))
+	assert.False(confirmed)
+}
+
+func TestDryRunChange(t *testing.T) {
+	assert := require.New(t)
+	m := NewManager("tidb", spec.NewSpec(t.TempDir(), func() spec.Metadata {
+		return &spec.ClusterMeta{Topology: new(spec.Specification)}
+	}), logprinter.NewLogger(""))
+	assert.Nil(m.specManager.SaveMeta("test", &spec.ClusterMeta{Topology: new(spec.Specification)}))
+
+	dryRun := operator.Options{DryRun: true}
+	assert.Nil(m.SetProtected("test", true, dryRun))
+	metadata, err := m.meta("test")
+	assert.Nil(err)
+	assert.False(*metadata.GetBaseMeta().Protected)
+
+	assert.Nil(m.Rename("test", dryRun, "renamed", true))
+	assert.True(utils.IsExist(m.specManager.Path("test")))
+	assert.False(utils.IsExist(m.specManager.Path("renamed")))
+
+	assert.Nil(m.SetProtected("test", true, operator.Options{}))
+	metadata, err = m.meta("test")
+	assert.Nil(err)
+	assert.True(*metadata.GetBaseMeta().Protected)
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+)
+
+// ReconcileEnabled compares the enablement state of the instances matching the
+// roles and nodes in gOpt with the intended one, i.e. enabled unless marked
+// disabled_at_boot in the topology, and enables or disables the drifted ones,
+// the IDs of the instances changed are returned
+func (m *Manager) ReconcileEnabled(name string, gOpt operator.Options) ([]string, error) {
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+	systemdMode := string(topo.BaseTopo().GlobalOptions.SystemdMode)
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]string, 0)
+	t := b.Func("ReconcileEnabled", func(ctx context.Context) error {
+		for _, inst := range selectedInstances(topo, gOpt) {
+			intended := !inst.DisabledAtBoot()
+			state := operator.InstanceEnabledState(ctx, inst, systemdMode)
+			if (state == "enabled") == intended {
+				continue
+			}
+			m.logger.Infof("Instance %s is %s, expected to be %s", inst.ID(), state, enabledStateName(intended))
+
+			// only touch the drifted instance
+			if err := operator.EnableComponent(ctx, []spec.Instance{inst}, set.NewStringSet(), gOpt, intended, systemdMode); err != nil {
+				return err
+			}
+			changed = append(changed, inst.ID())
+		}
+		return nil
+	}).Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.operationLogger(name, "reconcile"),
+	)
+	if m.dryRun("reconcile", t, gOpt) {
+		return changed, nil
+	}
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			return nil, err
+		}
+		return nil, perrs.Trace(err)
+	}
+
+	if len(changed) == 0 {
+		m.logger.Infof("The enabled state of cluster `%s` matches the topology, nothing is changed", name)
+	} else {
+		m.logger.Infof("Reconciled the enabled state of %d instance(s) of cluster `%s`", len(changed), name)
+	}
+	return changed, nil
+}
+
+func enabledStateName(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
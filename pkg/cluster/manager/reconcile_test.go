@@ -0,0 +1,44 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDisabledAtBoot(t *testing.T) {
+	assert := require.New(t)
+
+	topo := spec.Specification{}
+	err := yaml.Unmarshal([]byte(`
+tidb_servers:
+  - host: 172.16.5.1
+  - host: 172.16.5.2
+    disabled_at_boot: true
+`), &topo)
+	assert.Nil(err)
+
+	disabled := map[string]bool{}
+	topo.IterInstance(func(inst spec.Instance) {
+		disabled[inst.ID()] = inst.DisabledAtBoot()
+	})
+	assert.Equal(map[string]bool{
+		"172.16.5.1:4000": false,
+		"172.16.5.2:4000": true,
+	}, disabled)
+}
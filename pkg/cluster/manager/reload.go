@@ -73,9 +73,20 @@ func (m *Manager) Reload(name string, gOpt operator.Options, skipRestart, skipCo
 	topo := metadata.GetTopology()
 	base := metadata.GetBaseMeta()
 
+	if len(gOpt.ConfigOverrides) > 0 {
+		if err := applyConfigOverrides(topo, gOpt.ConfigOverrides); err != nil {
+			return err
+		}
+	}
+
 	// monitor
 	uniqueHosts, noAgentHosts := getMonitorHosts(topo)
 
+	atomic := gOpt.AtomicReload && !skipRestart
+	if atomic && gOpt.IfChanged {
+		return perrs.New("--atomic can not be used together with --if-changed")
+	}
+
 	// init config
 	refreshConfigTasks, hasImported := buildInitConfigTasks(m, name, topo, base, gOpt, nil)
 
@@ -112,7 +123,18 @@ func (m *Manager) Reload(name string, gOpt operator.Options, skipRestart, skipCo
 			nil, /* deleteNodeIds */
 		)
 	}
-	b.ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...)
+	var checksums map[string]string
+	if gOpt.IfChanged && !skipRestart {
+		b.Func("Snapshot Config", func(ctx context.Context) error {
+			checksums = configChecksums(ctx, topo, base.User, gOpt)
+			return nil
+		})
+	}
+	// the configs are pushed along with the restarts of the instances instead,
+	// the ones not restarted keep the config they are running with
+	if !atomic {
+		b.ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...)
+	}
 
 	if len(monitorConfigTasks) > 0 {
 		b.ParallelStep("+ Refresh monitor configs", gOpt.Force, monitorConfigTasks...)
@@ -124,7 +146,33 @@ func (m *Manager) Reload(name string, gOpt operator.Options, skipRestart, skipCo
 			return err
 		}
 		b.Func("Upgrade Cluster", func(ctx context.Context) error {
-			return operator.Upgrade(ctx, topo, gOpt, tlsCfg, base.Version, base.Version)
+			upgOpt := gOpt
+			if gOpt.IfChanged {
+				filter := changedConfigFilter(checksums, configChecksums(ctx, topo, base.User, gOpt))
+				upgOpt.Filter = func(inst spec.Instance) bool {
+					return filter(inst) && (gOpt.Filter == nil || gOpt.Filter(inst))
+				}
+				changed := operator.FilterInstanceBy(selectedInstances(topo, gOpt), filter)
+				if len(changed) == 0 {
+					m.logger.Infof("No instance config changed, skip restarting")
+					return nil
+				}
+				ids := make([]string, 0, len(changed))
+				for _, inst := range changed {
+					ids = append(ids, inst.ID())
+				}
+				m.logger.Infof("Instances with changed config: %s", strings.Join(ids, ","))
+			}
+			if atomic {
+				upgOpt.BeforeRestartInstance = func(ctx context.Context, inst spec.Instance) error {
+					tb, _ := buildInitConfigTask(m, name, inst, base, gOpt)
+					if err := tb.Build().Execute(ctx); err != nil {
+						return perrs.Annotatef(err, "failed to refresh config of %s", inst.ID())
+					}
+					return nil
+				}
+			}
+			return operator.Upgrade(ctx, topo, upgOpt, tlsCfg, base.Version, base.Version)
 		})
 	}
 
@@ -133,8 +181,11 @@ func (m *Manager) Reload(name string, gOpt operator.Options, skipRestart, skipCo
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "reload"),
 	)
+	if m.dryRun("reload", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -147,3 +198,17 @@ func (m *Manager) Reload(name string, gOpt operator.Options, skipRestart, skipCo
 
 	return nil
 }
+
+// applyConfigOverrides merges the one-off overrides into the in-memory
+// topology, so they are rendered into config files without being saved
+func applyConfigOverrides(topo spec.Topology, overrides []string) error {
+	clusterSpec, ok := topo.(*spec.Specification)
+	if !ok {
+		return perrs.Errorf("config overrides are not supported for %s topology", topo.Type())
+	}
+	kvs, err := spec.ParseConfigOverrides(overrides)
+	if err != nil {
+		return err
+	}
+	return spec.ApplyConfigOverrides(clusterSpec, kvs)
+}
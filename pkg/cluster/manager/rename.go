@@ -18,6 +18,7 @@ import (
 	"os"
 
 	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
@@ -25,7 +26,9 @@ import (
 	"github.com/pingcap/tiup/pkg/utils"
 )
 
-// Rename the cluster
+// Rename the cluster, all name derived paths (meta, TLS certificates, SSH keys,
+// config caches) live in the cluster's directory and are moved by a single
+// atomic rename, then the monitoring configs referring to the name are reloaded.
 func (m *Manager) Rename(name string, opt operator.Options, newName string, skipConfirm bool) error {
 	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
 		return err
@@ -45,6 +48,11 @@ func (m *Manager) Rename(name string, opt operator.Options, newName string, skip
 			WithProperty(tui.SuggestionFromFormat("Please specify another cluster name"))
 	}
 
+	// the scale-out lock refers to the cluster by name
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
 	if !skipConfirm {
 		if err := tui.PromptForConfirmOrAbortError(
 			fmt.Sprintf("Will rename the cluster name from %s to %s.\nDo you confirm this action? [y/N]:", color.HiYellowString(name), color.HiYellowString(newName)),
@@ -58,6 +66,11 @@ func (m *Manager) Rename(name string, opt operator.Options, newName string, skip
 		return err
 	}
 
+	if m.dryRunChange("rename", fmt.Sprintf("move %s to %s, then reload %s and %s",
+		m.specManager.Path(name), m.specManager.Path(newName), spec.ComponentGrafana, spec.ComponentPrometheus), opt) {
+		return nil
+	}
+
 	if err := os.Rename(m.specManager.Path(name), m.specManager.Path(newName)); err != nil {
 		return err
 	}
@@ -65,5 +78,12 @@ func (m *Manager) Rename(name string, opt operator.Options, newName string, skip
 	m.logger.Infof("Rename cluster `%s` -> `%s` successfully", name, newName)
 
 	opt.Roles = []string{spec.ComponentGrafana, spec.ComponentPrometheus}
-	return m.Reload(newName, opt, false, skipConfirm)
+	if err := m.Reload(newName, opt, false, skipConfirm); err != nil {
+		// the cluster is already renamed, only the monitoring configs are stale
+		return errorx.Decorate(err, "cluster renamed but failed to reload the monitoring components").
+			WithProperty(tui.SuggestionFromFormat(
+				"Please run `%s reload %s -R %s,%s` to refresh them",
+				tui.OsArgs0(), newName, spec.ComponentGrafana, spec.ComponentPrometheus))
+	}
+	return nil
 }
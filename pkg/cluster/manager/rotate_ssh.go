@@ -85,9 +85,13 @@ func (m *Manager) RotateSSH(name string, gOpt operator.Options, skipConfirm bool
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "rotate-ssh"),
 	)
-	if err := builder.Build().Execute(ctx); err != nil {
+	t := builder.Build()
+	if m.dryRun("rotate-ssh", t, gOpt) {
+		return nil
+	}
+	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
 			return err
@@ -130,8 +130,11 @@ func (m *Manager) ScaleIn(
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "scale-in"),
 	)
+	if m.dryRun("scale-in", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -49,6 +49,9 @@ func (m *Manager) ScaleOut(
 	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
 		return err
 	}
+	if err := checkStdinTopology(topoFile, skipConfirm); err != nil {
+		return err
+	}
 
 	// check the scale out file lock is exist
 	err := checkScaleOutLock(m, name, opt, skipConfirm)
@@ -209,9 +212,12 @@ func (m *Manager) ScaleOut(
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "scale-out"),
 	)
 	ctx = context.WithValue(ctx, ctxt.CtxBaseTopo, topo)
+	if m.dryRun("scale-out", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
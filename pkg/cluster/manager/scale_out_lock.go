@@ -0,0 +1,101 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// ScaleOutLockInfo returns the scale-out lock of the cluster, nil if the
+// cluster is not locked
+func (m *Manager) ScaleOutLockInfo(name string) (*spec.ScaleOutLockInfo, error) {
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return nil, err
+	}
+	exist, err := m.specManager.Exist(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("cluster %s does not exist", name)
+	}
+	return m.specManager.ScaleOutLockInfo(name)
+}
+
+// ReleaseScaleOutLock force-releases the scale-out lock left by an aborted
+// scale-out, the instances added by it are kept in the topology without
+// being started.
+func (m *Manager) ReleaseScaleOutLock(name string, gOpt operator.Options, skipConfirm bool) error {
+	info, err := m.ScaleOutLockInfo(name)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return perrs.Errorf("cluster `%s` is not locked by scale-out", name)
+	}
+
+	if !skipConfirm {
+		m.logger.Warnf("%s", scaleOutLockSummary(info, m.clock.Now()))
+		m.logger.Warnf("Releasing the lock will leave the instances above %s, start them with `%s start %s -N %s` or scale them in afterwards.",
+			color.HiYellowString("deployed but not started"), tui.OsArgs0(), name, strings.Join(info.Instances, ","))
+		if err := tui.PromptForConfirmOrAbortError("Do you want to release the scale-out lock of cluster `%s`? [y/N]:", name); err != nil {
+			return err
+		}
+	}
+
+	if m.dryRunChange("scale-out-lock release", fmt.Sprintf("release the scale-out lock of cluster `%s`", name), gOpt) {
+		return nil
+	}
+	if err := m.specManager.ReleaseScaleOutLock(name); err != nil {
+		return perrs.Annotatef(err, "failed to release the scale-out lock of cluster `%s`", name)
+	}
+	m.logger.Infof("The scale-out lock of cluster `%s` is released", name)
+	return nil
+}
+
+// PrintScaleOutLock prints the scale-out lock of the cluster
+func (m *Manager) PrintScaleOutLock(name string, info *spec.ScaleOutLockInfo) {
+	if info == nil {
+		fmt.Printf("Cluster %s is not locked by scale-out\n", name)
+		return
+	}
+	fmt.Println(scaleOutLockSummary(info, m.clock.Now()))
+}
+
+// scaleOutLockSummary describes the lock, now is used to tell how long it's held
+func scaleOutLockSummary(info *spec.ScaleOutLockInfo, now time.Time) string {
+	orUnknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+	lines := []string{
+		fmt.Sprintf("Locked by:      %s", orUnknown(info.User)),
+		fmt.Sprintf("Locked at:      %s (%s ago)", info.Time.Format(time.RFC3339), now.Sub(info.Time).Round(time.Second)),
+		fmt.Sprintf("Operation:      %s", orUnknown(info.Operation)),
+		fmt.Sprintf("Correlation ID: %s", orUnknown(info.CorrelationID)),
+		fmt.Sprintf("Instances:      %s", strings.Join(info.Instances, ", ")),
+	}
+	return strings.Join(lines, "\n")
+}
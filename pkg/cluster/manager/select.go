@@ -0,0 +1,62 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/joomcode/errorx"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/tui"
+)
+
+// SelectNodes lists the instances of the cluster matching the roles in gOpt
+// with their status and lets the user pick some of them interactively, the
+// IDs of the picked ones are set to gOpt.Nodes to scope the operation to. It
+// fails if stdin is not a terminal, the nodes must be passed explicitly then.
+func (m *Manager) SelectNodes(name string, gOpt *operator.Options) error {
+	if len(gOpt.Nodes) > 0 {
+		return perrs.New("--select and --node can't be used together")
+	}
+	if err := clusterutil.ValidateClusterNameOrError(name); err != nil {
+		return err
+	}
+
+	insts, err := m.GetClusterTopology(DisplayOption{ClusterName: name}, *gOpt)
+	if err != nil {
+		return err
+	}
+	if len(insts) == 0 {
+		return perrs.Errorf("no instance of cluster `%s` matches the roles", name)
+	}
+
+	rows := make([][]string, 0, len(insts))
+	for _, inst := range insts {
+		rows = append(rows, []string{inst.ID, inst.Role, inst.Host, inst.Status})
+	}
+	picked, err := tui.PromptForSelection([]string{"ID", "Role", "Host", "Status"}, rows)
+	if err != nil {
+		if errorx.IsOfType(err, tui.ErrNotInteractive) {
+			return errorx.Cast(err).WithProperty(tui.SuggestionFromString(
+				"Please pass the instances to operate on with --node instead of --select"))
+		}
+		return err
+	}
+
+	gOpt.Nodes = make([]string, 0, len(picked))
+	for _, i := range picked {
+		gOpt.Nodes = append(gOpt.Nodes, insts[i].ID)
+	}
+	return nil
+}
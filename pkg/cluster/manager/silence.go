@@ -0,0 +1,134 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// alertSilenceTimeout is the timeout of each request to Alertmanager
+const alertSilenceTimeout = 10 * time.Second
+
+// alertMatcher matches the alerts by a label in the v2 API of Alertmanager
+type alertMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// alertSilence is a silence in the v2 API of Alertmanager
+type alertSilence struct {
+	Matchers  []alertMatcher `json:"matchers"`
+	StartsAt  time.Time      `json:"startsAt"`
+	EndsAt    time.Time      `json:"endsAt"`
+	CreatedBy string         `json:"createdBy"`
+	Comment   string         `json:"comment"`
+}
+
+// SilenceAlerts silences the alerts of the cluster in its Alertmanager for
+// gOpt.SilenceDuration, e.g. during maintenance, and returns the func to lift
+// the silence before it expires. The returned func is a no-op if the cluster
+// has no Alertmanager deployed or it's a dry run.
+func (m *Manager) SilenceAlerts(name string, gOpt operator.Options, comment string) (func() error, error) {
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.silenceAlerts(name, metadata.GetTopology(), gOpt, comment)
+}
+
+// silenceAlerts creates the silence of the alerts labeled with the cluster
+// name in the first Alertmanager of the topology accepting it, the silence is
+// shared with the others of the same cluster by themselves
+func (m *Manager) silenceAlerts(name string, topo spec.Topology, gOpt operator.Options, comment string) (func() error, error) {
+	var addrs []string
+	for _, am := range topo.BaseTopo().Alertmanagers {
+		addrs = append(addrs, utils.JoinHostPort(am.GetManageHost(), am.WebPort))
+	}
+	if len(addrs) == 0 {
+		m.logger.Warnf("No alertmanager is deployed in cluster %s, skip silencing the alerts", name)
+		return func() error { return nil }, nil
+	}
+
+	if m.dryRunChange("silence", fmt.Sprintf("silence the alerts of cluster %s for %s", name, gOpt.SilenceDuration), gOpt) {
+		return func() error { return nil }, nil
+	}
+
+	now := m.clock.Now()
+	silence := alertSilence{
+		Matchers:  []alertMatcher{{Name: "cluster", Value: name, IsEqual: true}},
+		StartsAt:  now,
+		EndsAt:    now.Add(gOpt.SilenceDuration),
+		CreatedBy: "tiup-" + m.sysName,
+		Comment:   comment,
+	}
+	addr, id, err := createAlertSilence(context.Background(), addrs, silence)
+	if err != nil {
+		return nil, perrs.Annotatef(err, "failed to silence the alerts of cluster %s", name)
+	}
+	m.logger.Infof("Silenced the alerts of cluster %s until %s, silence ID: %s",
+		name, silence.EndsAt.Format(time.RFC3339), id)
+
+	return func() error {
+		if err := expireAlertSilence(context.Background(), addr, id); err != nil {
+			return perrs.Annotatef(err, "failed to lift the silence %s of cluster %s, it expires at %s",
+				id, name, silence.EndsAt.Format(time.RFC3339))
+		}
+		m.logger.Infof("Lifted the silence of the alerts of cluster %s", name)
+		return nil
+	}, nil
+}
+
+// createAlertSilence posts the silence to the Alertmanagers in order until one
+// accepts it, and returns its address and the ID of the silence
+func createAlertSilence(ctx context.Context, addrs []string, silence alertSilence) (string, string, error) {
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", "", perrs.Trace(err)
+	}
+
+	client := utils.NewHTTPClient(alertSilenceTimeout, nil)
+	for _, addr := range addrs {
+		var data []byte
+		data, err = client.Post(ctx, fmt.Sprintf("http://%s/api/v2/silences", addr), bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+		var resp struct {
+			SilenceID string `json:"silenceID"`
+		}
+		if err = json.Unmarshal(data, &resp); err != nil {
+			continue
+		}
+		return addr, resp.SilenceID, nil
+	}
+	return "", "", err
+}
+
+// expireAlertSilence expires the silence in the Alertmanager
+func expireAlertSilence(ctx context.Context, addr, id string) error {
+	client := utils.NewHTTPClient(alertSilenceTimeout, nil)
+	_, _, err := client.Delete(ctx, fmt.Sprintf("http://%s/api/v2/silence/%s", addr, id), nil)
+	return err
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceAlerts(t *testing.T) {
+	assert := require.New(t)
+
+	var created alertSilence
+	expired := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+			assert.Nil(json.NewDecoder(r.Body).Decode(&created))
+			_, _ = w.Write([]byte(`{"silenceID":"abc"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v2/silence/abc":
+			expired = "abc"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	assert.Nil(err)
+	webPort, err := strconv.Atoi(port)
+	assert.Nil(err)
+
+	m := NewManager("tidb", nil, logprinter.NewLogger(""))
+
+	// the first alertmanager is down, the silence is created in the second one
+	// through its manage host
+	topo := &spec.Specification{Alertmanagers: []*spec.AlertmanagerSpec{
+		{Host: "127.0.0.1", WebPort: closedPort(t)},
+		{Host: "alertmanager.invalid", ManageHost: host, WebPort: webPort},
+	}}
+	lift, err := m.silenceAlerts("test", topo, operator.Options{SilenceDuration: time.Hour}, "restart")
+	assert.Nil(err)
+	assert.Equal([]alertMatcher{{Name: "cluster", Value: "test", IsEqual: true}}, created.Matchers)
+	assert.Equal(time.Hour, created.EndsAt.Sub(created.StartsAt))
+	assert.Equal("restart", created.Comment)
+	assert.Equal("", expired)
+	assert.Nil(lift())
+	assert.Equal("abc", expired)
+
+	_, err = m.silenceAlerts("test", &spec.Specification{Alertmanagers: topo.Alertmanagers[:1]}, operator.Options{SilenceDuration: time.Hour}, "restart")
+	assert.NotNil(err)
+
+	// nothing is silenced in a dry run
+	created = alertSilence{}
+	lift, err = m.silenceAlerts("test", topo, operator.Options{SilenceDuration: time.Hour, DryRun: true}, "restart")
+	assert.Nil(err)
+	assert.Nil(created.Matchers)
+	assert.Nil(lift())
+
+	// nothing to silence without alertmanagers
+	lift, err = m.silenceAlerts("test", &spec.Specification{}, operator.Options{SilenceDuration: time.Hour}, "restart")
+	assert.Nil(err)
+	assert.Nil(lift())
+
+	assert.NotNil(expireAlertSilence(context.Background(), server.Listener.Addr().String(), "unknown"))
+}
+
+// closedPort returns a local port nothing listens on
+func closedPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
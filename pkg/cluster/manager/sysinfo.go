@@ -0,0 +1,137 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/set"
+)
+
+// sysInfoScript only relies on POSIX tools and /proc, every line of its
+// output is a key followed by the values, sizes are in KiB
+const sysInfoScript = `echo "cores $(getconf _NPROCESSORS_ONLN 2>/dev/null || nproc)"; ` +
+	`echo "kernel $(uname -r)"; ` +
+	`awk '/^MemTotal:/ {print "mem", $2}' /proc/meminfo; ` +
+	`df -Pk 2>/dev/null | awk 'NR > 1 {m = $6; for (i = 7; i <= NF; i++) m = m " " $i; print "disk", $2, $4, m}'`
+
+// DiskInfo is the usage of a mounted filesystem
+type DiskInfo struct {
+	Mount string `json:"mount"`
+	Total uint64 `json:"total"` // in bytes
+	Free  uint64 `json:"free"`  // in bytes
+}
+
+// HostSysInfo is the inventory of a host
+type HostSysInfo struct {
+	Host   string     `json:"host"`
+	OS     string     `json:"os"`
+	Arch   string     `json:"arch"`
+	Cores  int        `json:"cores"`
+	Memory uint64     `json:"memory"` // in bytes
+	Kernel string     `json:"kernel"`
+	Disks  []DiskInfo `json:"disks"`
+	Error  string     `json:"error,omitempty"` // set when the host could not be inspected
+}
+
+// CollectSysInfo collects the CPU, memory, disk and kernel information of all hosts
+// in the cluster, hosts failed to be inspected are reported with the error message
+// instead of failing the whole collection
+func (m *Manager) CollectSysInfo(name string, gOpt operator.Options) ([]HostSysInfo, error) {
+	var mu sync.Mutex
+	visited := set.NewStringSet()
+	var result []HostSysInfo
+	err := m.ForEachInstance(name, gOpt, func(ctx context.Context, inst spec.Instance, e ctxt.Executor) error {
+		// the hosts with multiple instances are inspected only once
+		mu.Lock()
+		host := inst.GetManageHost()
+		if visited.Exist(host) {
+			mu.Unlock()
+			return nil
+		}
+		visited.Insert(host)
+		mu.Unlock()
+
+		hi := collectHostSysInfo(ctx, e, host)
+		hi.OS, hi.Arch = inst.OS(), inst.Arch()
+		mu.Lock()
+		result = append(result, hi)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Host < result[j].Host
+	})
+	return result, nil
+}
+
+// collectHostSysInfo runs the inventory script on the host
+func collectHostSysInfo(ctx context.Context, e ctxt.Executor, host string) HostSysInfo {
+	stdout, stderr, err := e.Execute(ctx, sysInfoScript, false)
+	if err != nil {
+		_, msg := classifyConnError(err, stderr)
+		return HostSysInfo{Host: host, Error: msg}
+	}
+	info := parseSysInfo(string(stdout))
+	info.Host = host
+	return info
+}
+
+// parseSysInfo parses the output of sysInfoScript, unknown or malformed lines are ignored
+func parseSysInfo(output string) HostSysInfo {
+	info := HostSysInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "cores":
+			info.Cores, _ = strconv.Atoi(fields[1])
+		case "kernel":
+			info.Kernel = fields[1]
+		case "mem":
+			if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				info.Memory = kb * 1024
+			}
+		case "disk":
+			if len(fields) < 4 {
+				continue
+			}
+			total, err1 := strconv.ParseUint(fields[1], 10, 64)
+			free, err2 := strconv.ParseUint(fields[2], 10, 64)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			info.Disks = append(info.Disks, DiskInfo{
+				// the mount point may contain spaces
+				Mount: strings.Join(fields[3:], " "),
+				Total: total * 1024,
+				Free:  free * 1024,
+			})
+		}
+	}
+	return info
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSysInfo(t *testing.T) {
+	assert := require.New(t)
+
+	output := `cores 16
+kernel 5.10.0-136.el8.x86_64
+mem 32768000
+disk 103081248 51540624 /
+disk 2048 1024 /mnt/my disk
+disk bad 1024 /broken
+garbage
+`
+	info := parseSysInfo(output)
+	assert.Equal(16, info.Cores)
+	assert.Equal("5.10.0-136.el8.x86_64", info.Kernel)
+	assert.Equal(uint64(32768000*1024), info.Memory)
+	assert.Equal([]DiskInfo{
+		{Mount: "/", Total: 103081248 * 1024, Free: 51540624 * 1024},
+		{Mount: "/mnt/my disk", Total: 2048 * 1024, Free: 1024 * 1024},
+	}, info.Disks)
+
+	assert.Equal(HostSysInfo{}, parseSysInfo(""))
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fatih/color"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/environment"
+	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// configCheckTimeout is the max time to run the config check of a component binary
+const configCheckTimeout = 30 * time.Second
+
+// checkers of the config test
+const (
+	ConfigCheckerSyntax = "syntax" // only the TOML syntax is checked
+	ConfigCheckerBinary = "binary" // checked by the component binary with `--config-check`
+)
+
+// ConfigCheckResult is the config test result of an instance
+type ConfigCheckResult struct {
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Passed  bool   `json:"passed"`
+	Checker string `json:"checker"`
+	Message string `json:"message,omitempty"`
+}
+
+// TestConfig renders the config files of instances in the topology file and
+// validates them locally without touching any host. The TOML syntax is always
+// checked, and if the binary of the component in version is installed locally
+// its own `--config-check` is run as well.
+func (m *Manager) TestConfig(topoFile, version string) ([]ConfigCheckResult, error) {
+	metadata := m.specManager.NewMetadata()
+	topo := metadata.GetTopology()
+	if err := spec.ParseTopologyYaml(topoFile, topo); err != nil {
+		return nil, err
+	}
+	specification, ok := topo.(*spec.Specification)
+	if !ok {
+		return nil, perrs.Errorf("config test is not supported for %s clusters", m.sysName)
+	}
+
+	configs, err := spec.RenderInstanceConfigs(specification)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "tiup-config-test-")
+	if err != nil {
+		return nil, perrs.Trace(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// the binaries are probed once for each component
+	binaries := make(map[string]configCheckBinary)
+	result := make([]ConfigCheckResult, 0, len(configs))
+	for _, cfg := range configs {
+		inst := cfg.Instance
+		r := ConfigCheckResult{
+			ID:      inst.ID(),
+			Role:    inst.Role(),
+			Checker: ConfigCheckerSyntax,
+		}
+		if err := checkConfigSyntax(cfg.Content); err != nil {
+			r.Message = err.Error()
+			result = append(result, r)
+			continue
+		}
+		r.Passed = true
+
+		binPath, reason := localConfigCheckBinary(inst, version, binaries)
+		if binPath == "" {
+			r.Message = reason
+			result = append(result, r)
+			continue
+		}
+		r.Checker = ConfigCheckerBinary
+		if err := runLocalConfigCheck(binPath, inst, cfg.Content, tmpDir); err != nil {
+			r.Passed = false
+			r.Message = err.Error()
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// checkConfigSyntax checks that the rendered config could be parsed back
+func checkConfigSyntax(content []byte) error {
+	var parsed map[string]any
+	if _, err := toml.Decode(string(content), &parsed); err != nil {
+		return perrs.Annotate(err, "invalid TOML")
+	}
+	return nil
+}
+
+// configCheckBinary is the result of probing the binary of a component, the
+// path is empty if it's not available for the reason
+type configCheckBinary struct {
+	path   string
+	reason string
+}
+
+// localConfigCheckBinary returns the locally installed binary of the instance
+// that supports `--config-check`, or the reason why it is not available. The
+// binary of each component is probed once and kept in probed.
+func localConfigCheckBinary(inst spec.Instance, version string, probed map[string]configCheckBinary) (string, string) {
+	if version == "" {
+		return "", "no version specified"
+	}
+	if inst.OS() != runtime.GOOS || inst.Arch() != runtime.GOARCH {
+		return "", fmt.Sprintf("binary for %s/%s can't run locally", inst.OS(), inst.Arch())
+	}
+	b, ok := probed[inst.ComponentSource()]
+	if !ok {
+		b = probeConfigCheckBinary(inst.ComponentSource(), version)
+		probed[inst.ComponentSource()] = b
+	}
+	return b.path, b.reason
+}
+
+// probeConfigCheckBinary finds the locally installed binary of the component
+// in version and checks that it supports `--config-check`
+func probeConfigCheckBinary(component, version string) configCheckBinary {
+	env := environment.GlobalEnv()
+	if env == nil {
+		return configCheckBinary{reason: "no TiUP environment"}
+	}
+	ver, err := env.SelectInstalledVersion(component, utils.Version(version))
+	if err != nil {
+		return configCheckBinary{reason: fmt.Sprintf("%s %s is not installed locally", component, version)}
+	}
+	binPath, err := env.BinaryPath(component, ver)
+	if err != nil {
+		return configCheckBinary{reason: err.Error()}
+	}
+
+	// old versions do not support config check
+	ctx, cancel := context.WithTimeout(context.Background(), configCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, binPath, "--help").CombinedOutput()
+	if bytes.Contains(out, []byte("config-check")) {
+		// the usage is printed with a non-zero exit code by some binaries
+		return configCheckBinary{path: binPath}
+	}
+	if err != nil {
+		return configCheckBinary{reason: fmt.Sprintf("failed to get the usage of %s %s: %s", component, ver, err)}
+	}
+	return configCheckBinary{reason: fmt.Sprintf("%s %s does not support config check", component, ver)}
+}
+
+// runLocalConfigCheck runs the config check of the binary on the config content
+func runLocalConfigCheck(binPath string, inst spec.Instance, content []byte, tmpDir string) error {
+	name := strings.ReplaceAll(inst.ID(), ":", "-")
+	configPath := filepath.Join(tmpDir, name+".toml")
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		return err
+	}
+	dataDir := filepath.Join(tmpDir, name+"-data")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, binPath, spec.ConfigCheckArgs(inst.ComponentName(), configPath, dataDir)...).CombinedOutput()
+	if err != nil {
+		return perrs.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PrintConfigCheckResult prints the result of config test
+func PrintConfigCheckResult(result []ConfigCheckResult) {
+	table := [][]string{{"ID", "Role", "Checker", "Result", "Message"}}
+	for _, r := range result {
+		status := color.GreenString("Pass")
+		if !r.Passed {
+			status = color.RedString("Fail")
+		}
+		table = append(table, []string{r.ID, r.Role, r.Checker, status, r.Message})
+	}
+	tui.PrintTable(table, true)
+}
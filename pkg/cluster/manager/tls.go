@@ -95,8 +95,11 @@ func (m *Manager) TLS(name string, gOpt operator.Options, enable, cleanCertifica
 	ctx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "tls"),
 	)
+	if m.dryRun("tls", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
@@ -160,15 +163,10 @@ func getTLSFileMap(m *Manager, clusterName string, topo spec.Topology,
 
 	if !enableTLS && cleanCertificate {
 		// get:  host: set(tlsdir)
-		delFileMap = getCleanupFiles(topo, false, false, cleanCertificate, false, []string{}, []string{})
+		delFileMap = getCleanupFiles(topo, cleanupFiles{cleanupTLS: cleanCertificate})
 		// build file list string
 		delFileList := fmt.Sprintf("\n%s:\n %s", color.CyanString("localhost"), m.specManager.Path(clusterName, spec.TLSCertKeyDir))
-		for host, fileList := range delFileMap {
-			delFileList += fmt.Sprintf("\n%s:", color.CyanString(host))
-			for _, dfp := range fileList.Slice() {
-				delFileList += fmt.Sprintf("\n %s", dfp)
-			}
-		}
+		delFileList += formatCleanupFiles(delFileMap, nil, nil)
 
 		m.logger.Warnf("The parameter `%s` will delete the following files: %s", color.YellowString("--clean-certificate"), delFileList)
 
@@ -115,8 +115,11 @@ func (m *Manager) Transfer(name string, opt TransferOptions, gOpt operator.Optio
 	execCtx := ctxt.New(
 		context.Background(),
 		gOpt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "transfer"),
 	)
+	if m.dryRun("transfer", t, gOpt) {
+		return nil
+	}
 	if err := t.Execute(execCtx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
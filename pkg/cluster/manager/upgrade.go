@@ -273,7 +273,7 @@ This operation will upgrade %s %s cluster %s to %s:%s`,
 	ctx := ctxt.New(
 		context.Background(),
 		opt.Concurrency,
-		m.logger,
+		m.operationLogger(name, "upgrade"),
 	)
 	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
 	if err != nil {
@@ -313,8 +313,15 @@ This operation will upgrade %s %s cluster %s to %s:%s`,
 			}
 			return operator.Upgrade(ctx, topo, opt, tlsCfg, base.Version, clusterVersion)
 		}).
+		Func("RecordBinaryChecksums", func(ctx context.Context) error {
+			recordBinaryChecksums(ctx, m.logger, base, selectedInstances(topo, operator.Options{}))
+			return nil
+		}).
 		Build()
 
+	if m.dryRun("upgrade", t, opt) {
+		return nil
+	}
 	if err := t.Execute(ctx); err != nil {
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
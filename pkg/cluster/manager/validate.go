@@ -0,0 +1,244 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/pingcap/tiup/pkg/tui"
+	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// HostValidation is the result of validating the connectivity of a host in
+// the topology, the errors are nil if the checks pass or are not done
+type HostValidation struct {
+	Host       string
+	Port       int
+	Addrs      []string // addresses the host resolves to
+	ResolveErr error
+	ConnectErr error
+	AuthErr    error
+	AuthTried  bool
+	Proxied    bool // the host is reached via the SSH proxy, so it's not resolved or connected locally
+}
+
+// Passed tells whether all the checks done on the host pass
+func (v HostValidation) Passed() bool {
+	return v.ResolveErr == nil && v.ConnectErr == nil && v.AuthErr == nil
+}
+
+// ValidateTopology parses the topology file, resolves each host in it and
+// connects to the SSH port of it in parallel, the SSH login is tried as well
+// if auth is not nil. It reports the results of each host, and fails if any
+// of them fails, without changing anything on the hosts.
+//
+// If an SSH proxy is set, the hosts are only reachable through it, so they
+// are checked by logging in via the proxy and auth is required.
+func (m *Manager) ValidateTopology(topoFile string, gOpt operator.Options, auth *DeployOptions) error {
+	if gOpt.SSHProxyHost != "" && auth == nil {
+		return perrs.New("the hosts can only be validated with --auth when --ssh-proxy-host is set")
+	}
+
+	metadata := m.specManager.NewMetadata()
+	topo := metadata.GetTopology()
+	if err := spec.ParseTopologyYaml(topoFile, topo); err != nil {
+		return err
+	}
+
+	var sshProps, sshProxyProps *tui.SSHConnectionProps
+	if auth != nil {
+		var err error
+		if sshProps, err = tui.ReadIdentityFileOrPassword(auth.IdentityFile, auth.UsePassword); err != nil {
+			return err
+		}
+		if gOpt.SSHProxyHost != "" {
+			if sshProxyProps, err = tui.ReadIdentityFileOrPassword(gOpt.SSHProxyIdentity, gOpt.SSHProxyUsePassword); err != nil {
+				return err
+			}
+		}
+	}
+
+	results := validateHosts(context.Background(), topo, gOpt, auth, sshProps, sshProxyProps)
+
+	failed := 0
+	table := [][]string{{"Host", "SSH Port", "Resolve", "Connect", "Auth"}}
+	for _, r := range results {
+		if !r.Passed() {
+			failed++
+		}
+		resolve := validationResult(r.ResolveErr, !r.Proxied)
+		if r.ResolveErr == nil && len(r.Addrs) > 0 {
+			resolve = color.GreenString(fmt.Sprintf("%v", r.Addrs))
+		}
+		table = append(table, []string{
+			r.Host,
+			strconv.Itoa(r.Port),
+			resolve,
+			validationResult(r.ConnectErr, !r.Proxied && r.ResolveErr == nil),
+			validationResult(r.AuthErr, r.AuthTried),
+		})
+	}
+	tui.PrintTable(table, true)
+
+	if failed > 0 {
+		return perrs.Errorf("%d of %d hosts in %s failed the validation", failed, len(results), topoFile)
+	}
+	m.logger.Infof("All %d hosts in %s are reachable", len(results), topoFile)
+	return nil
+}
+
+// validationResult formats the result of a check, it's `-` if the check is not done
+func validationResult(err error, done bool) string {
+	switch {
+	case !done:
+		return "-"
+	case err != nil:
+		return color.RedString("Fail: %s", err)
+	default:
+		return color.GreenString("Pass")
+	}
+}
+
+// validateHosts checks the SSH ports of the hosts in the topology, each pair of
+// host and port is checked once
+func validateHosts(
+	ctx context.Context,
+	topo spec.Topology,
+	gOpt operator.Options,
+	auth *DeployOptions,
+	sshProps, sshProxyProps *tui.SSHConnectionProps,
+) []HostValidation {
+	timeout := time.Duration(gOpt.SSHTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	seen := make(map[string]struct{})
+	var results []HostValidation
+	topo.IterInstance(func(inst spec.Instance) {
+		addr := utils.JoinHostPort(inst.GetManageHost(), inst.GetSSHPort())
+		if _, ok := seen[addr]; ok {
+			return
+		}
+		seen[addr] = struct{}{}
+		results = append(results, HostValidation{
+			Host:    inst.GetManageHost(),
+			Port:    inst.GetSSHPort(),
+			Proxied: gOpt.SSHProxyHost != "",
+		})
+	})
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return results[i].Host < results[j].Host
+		}
+		return results[i].Port < results[j].Port
+	})
+
+	errg, ctx := errgroup.WithContext(ctx)
+	if gOpt.Concurrency > 0 {
+		errg.SetLimit(gOpt.Concurrency)
+	}
+	for i := range results {
+		i := i
+		errg.Go(func() error {
+			validateHost(ctx, &results[i], timeout, gOpt, auth, sshProps, sshProxyProps)
+			return nil
+		})
+	}
+	_ = errg.Wait()
+	return results
+}
+
+// validateHost resolves the host, connects to its SSH port and logs in if
+// auth is set, the later checks are skipped once one fails. The host is only
+// logged in via the proxy if it's proxied.
+func validateHost(
+	ctx context.Context,
+	r *HostValidation,
+	timeout time.Duration,
+	gOpt operator.Options,
+	auth *DeployOptions,
+	sshProps, sshProxyProps *tui.SSHConnectionProps,
+) {
+	if !r.Proxied {
+		rctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if net.ParseIP(r.Host) == nil {
+			r.Addrs, r.ResolveErr = net.DefaultResolver.LookupHost(rctx, r.Host)
+			if r.ResolveErr != nil {
+				return
+			}
+		}
+
+		conn, err := net.DialTimeout("tcp", utils.JoinHostPort(r.Host, r.Port), timeout)
+		if err != nil {
+			r.ConnectErr = err
+			return
+		}
+		conn.Close()
+	}
+
+	if auth == nil {
+		return
+	}
+	r.AuthTried = true
+	sshType := gOpt.SSHType
+	if sshType == "" || sshType == executor.SSHTypeNone || sshType == executor.SSHTypeAuto {
+		sshType = executor.SSHTypeBuiltin
+	}
+	sc := executor.SSHConfig{
+		Host:       r.Host,
+		Port:       r.Port,
+		User:       auth.User,
+		Password:   sshProps.Password,
+		KeyFile:    sshProps.IdentityFile,
+		Passphrase: sshProps.IdentityFilePassphrase,
+		Timeout:    timeout,
+	}
+	if r.Proxied {
+		sc.Proxy = &executor.SSHConfig{
+			Host:       gOpt.SSHProxyHost,
+			Port:       gOpt.SSHProxyPort,
+			User:       gOpt.SSHProxyUser,
+			Password:   sshProxyProps.Password,
+			KeyFile:    sshProxyProps.IdentityFile,
+			Passphrase: sshProxyProps.IdentityFilePassphrase,
+			Timeout:    time.Second * time.Duration(gOpt.SSHProxyTimeout),
+		}
+	}
+	e, err := executor.New(sshType, false, sc)
+	if err != nil {
+		r.AuthErr = err
+		return
+	}
+	// the connection is not used any more once the host is checked
+	if c, ok := executor.UnwarpCheckPointExecutor(e).(io.Closer); ok {
+		defer c.Close()
+	}
+	if _, _, err := e.Execute(ctx, "true", false, timeout); err != nil {
+		r.AuthErr = perrs.Cause(err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTopology(t *testing.T) {
+	assert := require.New(t)
+
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer open.Close()
+
+	topoFile := filepath.Join(t.TempDir(), "topology.yaml")
+	assert.Nil(os.WriteFile(topoFile, []byte(fmt.Sprintf(`
+pd_servers:
+  - host: 127.0.0.1
+    ssh_port: %d
+tikv_servers:
+  - host: 127.0.0.1
+    ssh_port: %d
+  - host: 127.0.0.2
+    ssh_port: %d
+tidb_servers:
+  - host: tidb.invalid
+`, open.Addr().(*net.TCPAddr).Port, open.Addr().(*net.TCPAddr).Port, closedPort(t))), 0644))
+
+	topo := &spec.Specification{}
+	assert.Nil(spec.ParseTopologyYaml(topoFile, topo))
+	results := validateHosts(context.Background(), topo, operator.Options{Concurrency: 2, SSHTimeout: 2}, nil, nil, nil)
+	assert.Len(results, 3)
+
+	assert.Equal("127.0.0.1", results[0].Host)
+	assert.True(results[0].Passed())
+	assert.False(results[0].AuthTried)
+
+	assert.Equal("127.0.0.2", results[1].Host)
+	assert.Nil(results[1].ResolveErr)
+	assert.NotNil(results[1].ConnectErr)
+
+	assert.Equal("tidb.invalid", results[2].Host)
+	assert.NotNil(results[2].ResolveErr)
+	assert.False(results[2].Passed())
+
+	m := NewManager("tidb", spec.NewSpec(t.TempDir(), func() spec.Metadata {
+		return &spec.ClusterMeta{Topology: new(spec.Specification)}
+	}), logprinter.NewLogger(""))
+	err = m.ValidateTopology(topoFile, operator.Options{Concurrency: 2, SSHTimeout: 2}, nil)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "2 of 3 hosts")
+
+	// the hosts behind a proxy are not resolved or connected locally
+	proxied := operator.Options{Concurrency: 2, SSHTimeout: 2, SSHProxyHost: "proxy.invalid"}
+	results = validateHosts(context.Background(), topo, proxied, nil, nil, nil)
+	assert.Len(results, 3)
+	for _, r := range results {
+		assert.True(r.Proxied)
+		assert.True(r.Passed())
+		assert.False(r.AuthTried)
+	}
+	err = m.ValidateTopology(topoFile, proxied, nil)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "--auth")
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+)
+
+// maxHandshakeResponseLen is the max number of bytes read from the service in a handshake
+const maxHandshakeResponseLen = 512
+
+// Handshake is a minimal exchange with the service listening on a port to
+// verify it's able to serve, an open port only tells the socket accepts
+// connections. It's done on the host of the service with bash's /dev/tcp.
+type Handshake struct {
+	// Name of the handshake in messages, e.g. `MySQL greeting`.
+	Name string
+	// Address to connect to on the host, default 127.0.0.1.
+	Host string
+	// Bytes sent after connecting, empty for the protocols the server speaks first.
+	Send []byte
+	// Checks the first response of the service, it's read with a single read
+	// of at most 512 bytes.
+	Expect func(resp []byte) error
+	// Maximum duration of a handshake, default 3 seconds.
+	Timeout time.Duration
+}
+
+// MySQLHandshake expects the initial handshake packet of the MySQL protocol,
+// which the server sends right after accepting a connection, e.g. TiDB
+func MySQLHandshake(host string) *Handshake {
+	return &Handshake{
+		Name: "MySQL greeting",
+		Host: host,
+		Expect: func(resp []byte) error {
+			// 3 bytes of payload length, 1 byte of sequence ID, then the payload
+			// starting with the protocol version 10, an error packet starts
+			// with 0xff, e.g. the server refusing connections
+			if len(resp) < 5 {
+				return errors.Errorf("incomplete MySQL packet of %d bytes", len(resp))
+			}
+			switch resp[4] {
+			case 0x0a:
+				return nil
+			case 0xff:
+				return errors.Errorf("MySQL error packet: %s", mysqlErrorMessage(resp[5:]))
+			default:
+				return errors.Errorf("unexpected MySQL protocol version %d", resp[4])
+			}
+		},
+	}
+}
+
+// mysqlErrorMessage extracts the message from the payload of an error packet
+// following the 0xff header, i.e. 2 bytes of code, optional `#` and 5 bytes
+// of SQL state, then the message
+func mysqlErrorMessage(payload []byte) string {
+	if len(payload) < 2 {
+		return "unknown error"
+	}
+	msg := payload[2:]
+	if len(msg) > 6 && msg[0] == '#' {
+		msg = msg[6:]
+	}
+	return strings.TrimSpace(string(msg))
+}
+
+// command returns the shell command doing the handshake with port on the host
+func (h *Handshake) command(port int) (string, error) {
+	host := h.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	// the host is put in a quoted bash command
+	if strings.ContainsAny(host, "'\"$`\\ /") {
+		return "", errors.Errorf("invalid host '%s' to handshake with", host)
+	}
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = 3 * time.Second
+	}
+
+	var send strings.Builder
+	if len(h.Send) > 0 {
+		send.WriteString("printf '")
+		for _, b := range h.Send {
+			fmt.Fprintf(&send, "\\x%02x", b)
+		}
+		send.WriteString("' >&3 && ")
+	}
+	script := fmt.Sprintf("exec 3<>/dev/tcp/%s/%d && %sdd bs=%d count=1 <&3 2>/dev/null",
+		host, port, send.String(), maxHandshakeResponseLen)
+	return fmt.Sprintf(`timeout %d bash -c "%s"`, max(int(timeout.Seconds()+0.5), 1), script), nil
+}
+
+// do performs the handshake with port on the host the executor connects to
+func (h *Handshake) do(ctx context.Context, e ctxt.Executor, port int) error {
+	cmd, err := h.command(port)
+	if err != nil {
+		return err
+	}
+	stdout, _, err := e.Execute(ctx, cmd, false)
+	if err != nil {
+		return errors.Annotatef(err, "connect to port %d", port)
+	}
+	if len(stdout) == 0 {
+		return errors.Errorf("no response from port %d", port)
+	}
+	if h.Expect == nil {
+		return nil
+	}
+	return h.Expect(stdout)
+}
+
+// String implements the fmt.Stringer interface
+func (h *Handshake) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return "handshake"
+}
@@ -17,30 +17,128 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/ctxt"
 	"github.com/pingcap/tiup/pkg/cluster/executor"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
 	"github.com/pingcap/tiup/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// maxWaitForOutputLen is the max length of command output kept in the timeout error
+const maxWaitForOutputLen = 1024
+
+// a warning is printed once listing ports failed so many times in a row, and
+// at most once in the interval after that, so a persistent failure such as a
+// broken sudo is not hidden behind a silent wait
+const (
+	waitForErrorWarnCount    = 3
+	waitForErrorWarnInterval = 10 * time.Second
+)
+
+// commands to list the listening TCP ports, the sbin dirs are not always in
+// PATH of the deploy user
+const (
+	portListSS      = "PATH=$PATH:/usr/sbin:/sbin ss -ltn"
+	portListNetstat = "PATH=$PATH:/usr/sbin:/sbin netstat -ltn"
+)
+
+// states of a port that WaitFor could wait for
+const (
+	PortStateStarted = "started" // the port is open
+	PortStateStopped = "stopped" // the port is closed
+)
+
+// phases of an operation a wait belongs to, they are put in the errors of
+// WaitFor so it's obvious where e.g. a restart got stuck
+const (
+	PhaseStop  = "waiting for old instance to stop"
+	PhaseStart = "waiting for new instance to start"
+)
+
 // WaitForConfig is the configurations of WaitFor module.
 type WaitForConfig struct {
 	Port  int           // Port number to poll.
 	Sleep time.Duration // Duration to sleep between checks, default 1 second.
+	// Fraction of Sleep to randomize each sleep by in either direction, e.g. 0.2
+	// for +/-20%, so the polls of many instances started together spread out
+	// instead of hitting the hosts at the same time. Default to the value set by
+	// SetWaitForJitter, which is 0 unless changed.
+	Jitter float64
 	// Choices:
 	// started
 	// stopped
 	// When checking a port started will ensure the port is open, stopped will check that it is closed
 	State   string
 	Timeout time.Duration // Maximum duration to wait for.
+	// Maximum number of polls, it fails on whichever of it and Timeout comes
+	// first, default 0 means unlimited polls in the timeout.
+	MaxAttempts int
+	// Number of consecutive polls the state must be observed in before succeeding,
+	// it avoids taking a port that flaps during startup as ready, default 1.
+	StableCount int
+	// Extra conditions that must be satisfied together with Port and State,
+	// all of them are checked against the same snapshot in each poll.
+	Conditions []PortCondition
+	// Command to list the listening ports, e.g. `/opt/bin/ss -ltn`, its output
+	// must contain `:<port> ` for each open port as `ss`, `netstat` and `lsof`
+	// do. Default to `ss -ltn`, or `netstat -ltn` on hosts without `ss`.
+	Command string
+	// Handshake to do with Port once it's open when waiting for it to be
+	// started, e.g. MySQLHandshake for TiDB, the port is not taken as started
+	// until the service responds as expected, which tells it's able to serve
+	// rather than just accepting connections.
+	Handshake *Handshake
+	// Phase of the operation the wait belongs to, e.g. PhaseStop, it's put in
+	// the errors. Default to the phase implied by the states of the conditions
+	// not satisfied, a port not stopped yet is taken as PhaseStop.
+	Phase string
+}
+
+// PortCondition is the state a port is expected to be in
+type PortCondition struct {
+	Port  int
+	State string // started or stopped
+}
+
+func (c PortCondition) String() string {
+	return fmt.Sprintf("port %d to be %s", c.Port, c.State)
+}
+
+// satisfied checks the condition against the output of listing ports
+func (c PortCondition) satisfied(stdout []byte) bool {
+	open := bytes.Contains(stdout, []byte(fmt.Sprintf(":%d ", c.Port)))
+	if c.State == PortStateStopped {
+		return !open
+	}
+	return open
+}
+
+// waitForJitter is the default Jitter of WaitForConfig in 1/1000
+var waitForJitter atomic.Int64
+
+// SetWaitForJitter sets the default fraction of the sleep between polls of
+// WaitFor to randomize, it's recommended for large clusters to avoid the polls
+// of instances being synchronized. Pass 0 to disable it.
+func SetWaitForJitter(jitter float64) {
+	waitForJitter.Store(int64(jitter * 1000))
+}
+
+func defaultWaitForJitter() float64 {
+	return float64(waitForJitter.Load()) / 1000
 }
 
 // WaitFor is the module used to wait for some condition.
 type WaitFor struct {
-	c WaitForConfig
+	c          WaitForConfig
+	conditions []PortCondition
+	err        error // error of invalid config, returned on Execute
 }
 
 // NewWaitFor create a WaitFor instance.
@@ -51,45 +149,341 @@ func NewWaitFor(c WaitForConfig) *WaitFor {
 	if c.Timeout == 0 {
 		c.Timeout = time.Second * 60
 	}
+	if c.Jitter == 0 {
+		c.Jitter = defaultWaitForJitter()
+	}
 	if c.State == "" {
-		c.State = "started"
+		c.State = PortStateStarted
+	}
+	if c.StableCount <= 0 {
+		c.StableCount = 1
 	}
 
 	w := &WaitFor{
 		c: c,
 	}
+	if c.Port != 0 {
+		w.conditions = append(w.conditions, PortCondition{Port: c.Port, State: c.State})
+	}
+	for _, cond := range c.Conditions {
+		if cond.State == "" {
+			cond.State = PortStateStarted
+		}
+		w.conditions = append(w.conditions, cond)
+	}
+	w.err = validatePortConditions(w.conditions)
 
 	return w
 }
 
+// validatePortConditions checks that the conditions are valid and not conflicting
+func validatePortConditions(conditions []PortCondition) error {
+	if len(conditions) == 0 {
+		return errors.New("no port to wait for")
+	}
+	states := make(map[int]string)
+	for _, cond := range conditions {
+		if cond.Port <= 0 || cond.Port > 65535 {
+			return errors.Errorf("invalid port %d to wait for", cond.Port)
+		}
+		if cond.State != PortStateStarted && cond.State != PortStateStopped {
+			return errors.Errorf("invalid state '%s' of port %d, must be %s or %s",
+				cond.State, cond.Port, PortStateStarted, PortStateStopped)
+		}
+		if state, ok := states[cond.Port]; ok && state != cond.State {
+			return errors.Errorf("port %d can't be both %s and %s", cond.Port, state, cond.State)
+		}
+		states[cond.Port] = cond.State
+	}
+	return nil
+}
+
 // Execute the module return nil if successfully wait for the event.
 func (w *WaitFor) Execute(ctx context.Context, e ctxt.Executor) (err error) {
-	pattern := []byte(fmt.Sprintf(":%d ", w.c.Port))
+	if w.err != nil {
+		return w.err
+	}
 
 	retryOpt := utils.RetryOption{
 		Delay:   w.c.Sleep,
 		Timeout: w.c.Timeout,
+		Jitter:  w.c.Jitter,
+	}
+	if w.c.MaxAttempts > 0 {
+		retryOpt.Attempts = int64(w.c.MaxAttempts)
 	}
-	if err := utils.Retry(func() error {
-		// only listing TCP ports
-		stdout, _, err := executor.UnwarpCheckPointExecutor(e).Execute(ctx, "ss -ltn", false)
-		if err == nil {
-			switch w.c.State {
-			case "started":
-				if bytes.Contains(stdout, pattern) {
-					return nil
-				}
-			case "stopped":
-				if !bytes.Contains(stdout, pattern) {
-					return nil
-				}
+	var attempts int // number of polls done
+	var lastOutput []byte
+	var pending []PortCondition // conditions not satisfied in the last snapshot
+	var stable int              // number of consecutive snapshots the state is satisfied in
+	var observedAt time.Time    // time of the last snapshot counted in stable
+	var execErrors int          // number of consecutive failures of listing ports
+	var lastExecErr error       // the last failure of listing ports
+	var warnedAt time.Time      // time of the last warning about the failures
+	var handshakeErr error      // the last failure of the handshake
+	notBefore := time.Now()
+	e = executor.UnwarpCheckPointExecutor(e)
+	snapshot, release := portSnapshots(ctx).acquire(e, w.c.Command)
+	defer release()
+	if err := utils.RetryWithContext(ctx, func() error {
+		attempts++
+		handshakeErr = nil
+		// only listing TCP ports, the output is shared by all checks on the
+		// same host as long as it's taken after this check began
+		at, stdout, err := snapshot.fetch(ctx, e, w.c.Command, notBefore, w.c.Sleep)
+		if err != nil {
+			stable = 0
+			execErrors++
+			lastExecErr = err
+			if execErrors >= waitForErrorWarnCount && time.Since(warnedAt) >= waitForErrorWarnInterval {
+				warnedAt = time.Now()
+				warnWaitFor(ctx, "failed to list listening ports %d times in a row, still retrying: %s", execErrors, err)
 			}
+			return err
+		}
+		execErrors = 0
+		lastOutput = stdout
+		pending = pending[:0]
+		for _, cond := range w.conditions {
+			if !cond.satisfied(stdout) {
+				pending = append(pending, cond)
+			}
+		}
+		if len(pending) > 0 {
+			stable = 0
 			return errors.New("still waiting for port state to be satisfied")
 		}
-		return err
+		if w.checkHandshake() {
+			if handshakeErr = w.c.Handshake.do(ctx, e, w.c.Port); handshakeErr != nil {
+				stable = 0
+				return handshakeErr
+			}
+		}
+		// a shared snapshot seen again is not a new observation
+		if !at.Equal(observedAt) {
+			stable++
+			observedAt = at
+		}
+		if stable < w.c.StableCount {
+			return errors.New("still waiting for port state to be stable")
+		}
+		return nil
 	}, retryOpt); err != nil {
 		zap.L().Debug("retry error", zap.Error(err))
-		return errors.Errorf("timed out waiting for port %d to be %s after %s", w.c.Port, w.c.State, w.c.Timeout)
+		phase := w.phase(pending)
+		defer func() {
+			err = errors.Annotate(err, phase)
+		}()
+		if ctx.Err() != nil {
+			return errors.Annotate(ctx.Err(), "stopped waiting for the ports as the operation is cancelled")
+		}
+		limit := w.c.Timeout.String()
+		if w.c.MaxAttempts > 0 && attempts >= w.c.MaxAttempts {
+			limit = fmt.Sprintf("%d attempts", attempts)
+		}
+		if len(pending) == 0 && handshakeErr != nil {
+			return errors.Errorf("timed out waiting for port %d to respond with %s after %s, %s",
+				w.c.Port, w.c.Handshake, limit, handshakeErr)
+		}
+		if len(pending) == 0 {
+			pending = w.conditions
+		}
+		waiting := make([]string, 0, len(pending))
+		for _, cond := range pending {
+			waiting = append(waiting, cond.String())
+		}
+		if execErrors > 0 {
+			return errors.Errorf("timed out waiting for %s after %s, listing ports failed %d times in a row, last error: %s",
+				strings.Join(waiting, ", "), limit, execErrors, lastExecErr)
+		}
+		if len(lastOutput) == 0 {
+			return errors.Errorf("timed out waiting for %s after %s", strings.Join(waiting, ", "), limit)
+		}
+		return errors.Errorf("timed out waiting for %s after %s, last output of listing ports:\n%s",
+			strings.Join(waiting, ", "), limit, truncateOutput(lastOutput, maxWaitForOutputLen))
 	}
 	return nil
 }
+
+// phase returns the phase of the operation the wait got stuck at
+func (w *WaitFor) phase(pending []PortCondition) string {
+	if w.c.Phase != "" {
+		return w.c.Phase
+	}
+	for _, cond := range pending {
+		if cond.State == PortStateStopped {
+			return PhaseStop
+		}
+	}
+	if len(pending) == 0 && w.c.State == PortStateStopped {
+		return PhaseStop
+	}
+	return PhaseStart
+}
+
+// checkHandshake tells whether the handshake with the port should be done
+func (w *WaitFor) checkHandshake() bool {
+	return w.c.Port != 0 && w.c.State == PortStateStarted && w.c.Handshake != nil
+}
+
+// warnWaitFor prints the warning with the logger of the context if any
+func warnWaitFor(ctx context.Context, format string, args ...any) {
+	if logger, ok := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger); ok {
+		logger.Warnf(format, args...)
+		return
+	}
+	zap.L().Warn(fmt.Sprintf(format, args...))
+}
+
+// truncateOutput keeps the head of output with at most n bytes
+func truncateOutput(output []byte, n int) string {
+	output = bytes.TrimSpace(output)
+	if len(output) <= n {
+		return string(output)
+	}
+	return string(output[:n]) + "\n...(truncated)"
+}
+
+// portSnapshotsKey is the key of the portSnapshotCache shared by the WaitFor
+// checks of an operation
+type portSnapshotsKey struct{}
+
+// portSnapshots returns the cache of listening ports of the operation, so that
+// the concurrent WaitFor checks on a host with many instances could share a
+// single command in each poll interval instead of running their own ones. A
+// new cache is returned if ctx is not the context of an operation.
+func portSnapshots(ctx context.Context) *portSnapshotCache {
+	inner, ok := ctxt.LookupInner(ctx)
+	if !ok {
+		return newPortSnapshotCache()
+	}
+	return inner.Shared(portSnapshotsKey{}, func() any {
+		return newPortSnapshotCache()
+	}).(*portSnapshotCache)
+}
+
+// portSnapshotKey identifies the snapshot by the host and the listing command
+type portSnapshotKey struct {
+	e       ctxt.Executor
+	command string
+}
+
+// portSnapshotCache is the per-host cache of listening ports, the snapshots
+// are dropped once no WaitFor check uses them
+type portSnapshotCache struct {
+	mu      sync.Mutex
+	entries map[portSnapshotKey]*portSnapshot
+}
+
+func newPortSnapshotCache() *portSnapshotCache {
+	return &portSnapshotCache{entries: make(map[portSnapshotKey]*portSnapshot)}
+}
+
+// portSnapshot is the output of listing ports on a host at some time
+type portSnapshot struct {
+	refs int // number of the checks using it, guarded by the mutex of the cache
+
+	mu      sync.Mutex
+	at      time.Time // the time when the command was issued
+	tool    string    // the detected command when no command is specified
+	stdout  []byte
+	err     error
+	running chan struct{} // closed when the running command finishes, nil if none
+}
+
+// acquire returns the snapshot of the host which the executor connects to, it
+// must be released once the caller doesn't poll it any more
+func (c *portSnapshotCache) acquire(e ctxt.Executor, command string) (*portSnapshot, func()) {
+	// executors that can't be used as map keys are never shared
+	if !reflect.TypeOf(e).Comparable() {
+		return &portSnapshot{}, func() {}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := portSnapshotKey{e: e, command: command}
+	s, ok := c.entries[key]
+	if !ok {
+		s = &portSnapshot{}
+		c.entries[key] = s
+	}
+	s.refs++
+	return s, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if s.refs--; s.refs == 0 {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// fetch returns the cached output if it's taken after notBefore and not
+// older than maxAge, otherwise it runs the command again, or waits for the
+// one already running. The time when the returned output was taken is
+// returned as well.
+func (s *portSnapshot) fetch(ctx context.Context, e ctxt.Executor, command string, notBefore time.Time, maxAge time.Duration) (time.Time, []byte, error) {
+	s.mu.Lock()
+	for {
+		if !s.at.IsZero() && !s.at.Before(notBefore) && time.Since(s.at) < maxAge {
+			defer s.mu.Unlock()
+			return s.at, s.stdout, s.err
+		}
+		if s.running == nil {
+			break
+		}
+		running := s.running
+		s.mu.Unlock()
+		select {
+		case <-running:
+		case <-ctx.Done():
+			return time.Time{}, nil, ctx.Err()
+		}
+		s.mu.Lock()
+	}
+	running := make(chan struct{})
+	s.running = running
+	tool := s.tool
+	s.mu.Unlock()
+
+	at := time.Now()
+	stdout, tool, err := listPorts(ctx, e, command, tool)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = nil
+	close(running)
+	s.tool = tool
+	// the failure caused by the context of this caller is not shared with
+	// the others, they run the command again instead
+	if ctx.Err() == nil {
+		s.at, s.stdout, s.err = at, stdout, err
+	}
+	return at, stdout, err
+}
+
+// listPorts runs the command to list ports, or the tool detected on the host
+// if the command is empty, and returns the tool for the following polls
+func listPorts(ctx context.Context, e ctxt.Executor, command, tool string) ([]byte, string, error) {
+	if command != "" {
+		stdout, _, err := e.Execute(ctx, command, false)
+		return stdout, tool, err
+	}
+
+	if tool == "" {
+		tool = portListSS
+	}
+	stdout, stderr, err := e.Execute(ctx, tool, false)
+	if err != nil && tool == portListSS && commandNotFound(stderr) {
+		// the snapshot lives with the host, so `ss` is only detected once and
+		// all the following polls on the host use netstat directly
+		tool = portListNetstat
+		stdout, _, err = e.Execute(ctx, tool, false)
+	}
+	return stdout, tool, err
+}
+
+// commandNotFound checks the stderr of shells when the command is absent,
+// e.g. `bash: ss: command not found` or `sh: 1: ss: not found`
+func commandNotFound(stderr []byte) bool {
+	return bytes.Contains(stderr, []byte("not found"))
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+// countingExecutor counts the commands run, each one waits for unblock if set
+type countingExecutor struct {
+	calls   *atomic.Int32
+	unblock chan struct{}
+}
+
+func (e countingExecutor) Execute(ctx context.Context, _ string, _ bool, _ ...time.Duration) ([]byte, []byte, error) {
+	e.calls.Add(1)
+	if e.unblock != nil {
+		select {
+		case <-e.unblock:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return []byte("LISTEN 0.0.0.0:4000"), nil, nil
+}
+
+func (countingExecutor) Transfer(context.Context, string, string, bool, int, bool) error {
+	return nil
+}
+
+func TestPortSnapshots(t *testing.T) {
+	assert := require.New(t)
+
+	// the cache is shared in an operation only
+	ctx := ctxt.New(context.Background(), 0, logprinter.NewLogger(""))
+	assert.Same(portSnapshots(ctx), portSnapshots(ctx))
+	assert.NotSame(portSnapshots(context.Background()), portSnapshots(context.Background()))
+
+	// the snapshots are dropped once released by all the users
+	cache := portSnapshots(ctx)
+	e := countingExecutor{calls: new(atomic.Int32)}
+	s1, release1 := cache.acquire(e, "ss")
+	s2, release2 := cache.acquire(e, "ss")
+	assert.Same(s1, s2)
+	release1()
+	assert.Len(cache.entries, 1)
+	release2()
+	assert.Len(cache.entries, 0)
+}
+
+func TestPortSnapshotFetch(t *testing.T) {
+	assert := require.New(t)
+	start := time.Now()
+
+	// the failure caused by the context of the caller is not cached
+	e := countingExecutor{calls: new(atomic.Int32)}
+	s := &portSnapshot{}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := s.fetch(canceled, e, "ss", start, time.Minute)
+	assert.ErrorIs(err, context.Canceled)
+	_, stdout, err := s.fetch(context.Background(), e, "ss", start, time.Minute)
+	assert.Nil(err)
+	assert.Equal("LISTEN 0.0.0.0:4000", string(stdout))
+	assert.Equal(int32(2), e.calls.Load())
+
+	// the following callers share the output
+	_, _, err = s.fetch(context.Background(), e, "ss", start, time.Minute)
+	assert.Nil(err)
+	assert.Equal(int32(2), e.calls.Load())
+
+	// the callers waiting for a slow command give up with their own context
+	e = countingExecutor{calls: new(atomic.Int32), unblock: make(chan struct{})}
+	s = &portSnapshot{}
+	done := make(chan error)
+	go func() {
+		_, _, err := s.fetch(context.Background(), e, "ss", start, time.Minute)
+		done <- err
+	}()
+	assert.Eventually(func() bool { return e.calls.Load() == 1 }, time.Second, time.Millisecond)
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = s.fetch(short, e, "ss", start, time.Minute)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	close(e.unblock)
+	assert.Nil(<-done)
+	_, _, err = s.fetch(context.Background(), e, "ss", start, time.Minute)
+	assert.Nil(err)
+	assert.Equal(int32(1), e.calls.Load())
+}
@@ -18,6 +18,10 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -59,6 +63,14 @@ func Enable(
 	nodeFilter := set.NewStringSet(options.Nodes...)
 	components := cluster.ComponentsByStartOrder()
 	components = FilterComponent(components, roleFilter)
+	if options.DryRun {
+		action := "disable"
+		if isEnable {
+			action = "enable"
+		}
+		dryRunComponents(ctx, action, components, nodeFilter, options)
+		return nil
+	}
 	monitoredOptions := cluster.GetMonitoredOptions()
 	noAgentHosts := set.NewStringSet()
 	systemdMode := string(cluster.BaseTopo().GlobalOptions.SystemdMode)
@@ -72,7 +84,7 @@ func Enable(
 	})
 
 	for _, comp := range components {
-		insts := FilterInstance(comp.Instances(), nodeFilter)
+		insts := FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())
 		err := EnableComponent(ctx, insts, noAgentHosts, options, isEnable, systemdMode)
 		if err != nil {
 			return errors.Annotatef(err, "failed to enable/disable %s", comp.Name())
@@ -114,6 +126,10 @@ func Start(
 	nodeFilter := set.NewStringSet(options.Nodes...)
 	components := cluster.ComponentsByStartOrder()
 	components = FilterComponent(components, roleFilter)
+	if options.DryRun {
+		dryRunComponents(ctx, "start", components, nodeFilter, options)
+		return nil
+	}
 	monitoredOptions := cluster.GetMonitoredOptions()
 	noAgentHosts := set.NewStringSet()
 	systemdMode := string(cluster.BaseTopo().GlobalOptions.SystemdMode)
@@ -123,36 +139,106 @@ func Start(
 		}
 	})
 
+	var insts []spec.Instance
 	for _, comp := range components {
-		insts := FilterInstance(comp.Instances(), nodeFilter)
-		err := StartComponent(ctx, insts, noAgentHosts, options, tlsCfg, systemdMode)
-		if err != nil {
-			return errors.Annotatef(err, "failed to start %s", comp.Name())
-		}
+		insts = append(insts, FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())...)
+	}
+	total := len(insts)
+	threshold, err := ParseFailureThreshold(options.TolerateFailures, total)
+	if err != nil {
+		return err
+	}
+	failures := &PartialFailureError{}
+	failed := set.NewStringSet()
+	options.hooks = cluster.BaseTopo().GlobalOptions.Hooks
 
-		errg, _ := errgroup.WithContext(ctx)
-		for _, inst := range insts {
-			if !inst.IgnoreMonitorAgent() {
-				uniqueHosts.Insert(inst.GetManageHost())
+	stages, ignored, err := StartStages(components, options.ParallelRoles)
+	if err != nil {
+		return err
+	}
+	if err := checkDataDirSpace(ctx, insts, cluster.BaseTopo().GlobalOptions.User, options); err != nil {
+		return err
+	}
+	if len(ignored) > 0 {
+		logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+		logger.Warnf("Dependencies of parallel roles %s are unknown, starting them serially", strings.Join(ignored, " "))
+		options.Result.warn("dependencies of parallel roles %s are unknown, started them serially", strings.Join(ignored, " "))
+	}
+
+	for _, stage := range stages {
+		// the components of a stage are started concurrently, then checked in order
+		stageInsts := make([][]spec.Instance, len(stage))
+		stageErrs := make([]error, len(stage))
+		var wg sync.WaitGroup
+		for i, comp := range stage {
+			i := i
+			stageInsts[i] = FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())
+			nctx := ctx
+			if len(stage) > 1 {
+				// checkpoint must be in a new context
+				nctx = checkpoint.NewContext(ctx)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				stageErrs[i] = StartComponent(nctx, stageInsts[i], noAgentHosts, options, tlsCfg, systemdMode)
+			}()
+		}
+		wg.Wait()
+
+		for i, comp := range stage {
+			insts, err := stageInsts[i], stageErrs[i]
+			if pErr, ok := err.(*PartialFailureError); ok {
+				failures.Failures = append(failures.Failures, pErr.Failures...)
+				for _, f := range pErr.Failures {
+					failed.Insert(f.ID)
+				}
+				if len(failures.Failures) > threshold {
+					return errors.Annotatef(failures, "failed to start %s, exceeded the failure tolerance %d", comp.Name(), threshold)
+				}
+			} else if err != nil {
+				return errors.Annotatef(err, "failed to start %s", comp.Name())
 			}
 
-			if restoreLeader {
-				rIns, ok := inst.(spec.RollingUpdateInstance)
-				if ok {
-					// checkpoint must be in a new context
-					nctx := checkpoint.NewContext(ctx)
-					errg.Go(func() error {
-						err := rIns.PostRestart(nctx, cluster, tlsCfg)
-						if err != nil && !options.Force {
-							return err
-						}
-						return nil
-					})
+			errg, _ := errgroup.WithContext(ctx)
+			for _, inst := range insts {
+				if failed.Exist(inst.ID()) {
+					continue
+				}
+				if !inst.IgnoreMonitorAgent() {
+					uniqueHosts.Insert(inst.GetManageHost())
 				}
+
+				if restoreLeader {
+					rIns, ok := inst.(spec.RollingUpdateInstance)
+					if ok {
+						// checkpoint must be in a new context
+						nctx := checkpoint.NewContext(ctx)
+						errg.Go(func() error {
+							err := rIns.PostRestart(nctx, cluster, tlsCfg)
+							if err != nil && !options.Force {
+								return err
+							}
+							return nil
+						})
+					}
+				}
+			}
+			if err := errg.Wait(); err != nil {
+				return err
+			}
+
+			if err := warmupComponent(ctx, roleKey(comp), len(insts), options.DelayStart[roleKey(comp)]); err != nil {
+				return err
 			}
 		}
-		if err := errg.Wait(); err != nil {
-			return err
+	}
+
+	if len(failures.Failures) > 0 {
+		logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+		logger.Warnf("%d of %d instance(s) started, failures tolerated (%d allowed):", total-len(failures.Failures), total, threshold)
+		for _, f := range failures.Failures {
+			logger.Warnf("\t%s: %s", f.ID, f.Err)
 		}
 	}
 
@@ -179,6 +265,14 @@ func Stop(
 	nodeFilter := set.NewStringSet(options.Nodes...)
 	components := cluster.ComponentsByStopOrder()
 	components = FilterComponent(components, roleFilter)
+	components, _, err := StopOrder(components, options.StopOrder)
+	if err != nil {
+		return err
+	}
+	if options.DryRun {
+		dryRunComponents(ctx, "stop", components, nodeFilter, options)
+		return nil
+	}
 	monitoredOptions := cluster.GetMonitoredOptions()
 	noAgentHosts := set.NewStringSet()
 	systemdMode := string(cluster.BaseTopo().GlobalOptions.SystemdMode)
@@ -192,7 +286,7 @@ func Stop(
 	})
 
 	for _, comp := range components {
-		insts := FilterInstance(comp.Instances(), nodeFilter)
+		insts := FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())
 		err := StopComponent(
 			ctx,
 			cluster,
@@ -264,6 +358,10 @@ func Restart(
 	options Options,
 	tlsCfg *tls.Config,
 ) error {
+	if options.RestartBatch > 0 || len(options.RestartBatchRoles) > 0 {
+		return restartInBatches(ctx, cluster, options, tlsCfg)
+	}
+
 	err := Stop(ctx, cluster, options, false, tlsCfg)
 	if err != nil {
 		return errors.Annotatef(err, "failed to stop")
@@ -277,6 +375,73 @@ func Restart(
 	return nil
 }
 
+// restartInBatches restarts the components in the start order, the instances of
+// each component are restarted batch by batch and the next batch is not touched
+// until the instances of the previous one are ready and pass the batch gate
+func restartInBatches(
+	ctx context.Context,
+	cluster spec.Topology,
+	options Options,
+	tlsCfg *tls.Config,
+) error {
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	roleFilter := set.NewStringSet(options.Roles...)
+	nodeFilter := set.NewStringSet(options.Nodes...)
+	components := FilterComponent(cluster.ComponentsByStartOrder(), roleFilter)
+
+	for _, comp := range components {
+		insts := FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())
+		if len(insts) == 0 {
+			continue
+		}
+		batches := restartBatches(insts, options.BatchSize(roleKey(comp)))
+		total := len(batches)
+
+		for i, nodes := range batches {
+			// stop between batches, the restarted ones are already serving
+			if err := ctx.Err(); err != nil {
+				return errors.Annotatef(err, "restart stopped before batch %d/%d of %s", i+1, total, comp.Name())
+			}
+			logger.Infof("Restarting batch %d/%d of %s: %s", i+1, total, comp.Name(), strings.Join(nodes, ","))
+
+			batchOpts := options
+			batchOpts.Roles = nil
+			batchOpts.Nodes = nodes
+			if err := Stop(ctx, cluster, batchOpts, false, tlsCfg); err != nil {
+				return errors.Annotatef(err, "failed to stop batch %d/%d of %s", i+1, total, comp.Name())
+			}
+			if err := Start(ctx, cluster, batchOpts, false, tlsCfg); err != nil {
+				return errors.Annotatef(err, "failed to start batch %d/%d of %s", i+1, total, comp.Name())
+			}
+			if options.DryRun || options.RestartBatchGate == nil {
+				continue
+			}
+			if err := options.RestartBatchGate(ctx, cluster, nodes); err != nil {
+				return errors.Annotatef(err, "batch %d/%d of %s is not healthy after restart", i+1, total, comp.Name())
+			}
+		}
+	}
+	return nil
+}
+
+// restartBatches splits the IDs of the instances into batches of size, all
+// the instances are in one batch if size is not positive
+func restartBatches(insts []spec.Instance, size int) [][]string {
+	if size <= 0 || size > len(insts) {
+		size = len(insts)
+	}
+	var batches [][]string
+	for i := 0; i < len(insts); i += size {
+		end := min(i+size, len(insts))
+		nodes := make([]string, 0, end-i)
+		for _, inst := range insts[i:end] {
+			nodes = append(nodes, inst.ID())
+		}
+		batches = append(batches, nodes)
+	}
+	return batches
+}
+
 // StartMonitored start BlackboxExporter and NodeExporter
 func StartMonitored(ctx context.Context, hosts []string, noAgentHosts set.StringSet, options *spec.MonitoredOptions, timeout uint64, systemdMode string) error {
 	return systemctlMonitor(ctx, hosts, noAgentHosts, options, "start", timeout, systemdMode)
@@ -322,9 +487,14 @@ func systemctlMonitor(ctx context.Context, hosts []string, noAgentHosts set.Stri
 			}
 			nctx := checkpoint.NewContext(ctx)
 			errg.Go(func() error {
-				logger.Infof("\t%s instance %s", actionPrevMsgs[action], host)
 				e := ctxt.GetInner(nctx).Get(host)
 				service := fmt.Sprintf("%s-%d.service", comp, ports[comp])
+				if (action == "enable" || action == "disable") &&
+					unitEnabledState(nctx, e, service, systemdMode) == action+"d" {
+					logger.Debugf("\t%s on %s is already %sd", comp, host, action)
+					return nil
+				}
+				logger.Infof("\t%s instance %s", actionPrevMsgs[action], host)
 
 				if err := systemctl(nctx, e, service, action, timeout, systemdMode); err != nil {
 					return toFailedActionError(err, action, host, service, "")
@@ -355,7 +525,8 @@ func systemctlMonitor(ctx context.Context, hosts []string, noAgentHosts set.Stri
 
 func restartInstance(ctx context.Context, ins spec.Instance, timeout uint64, tlsCfg *tls.Config, systemdMode string) error {
 	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
-	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger).
+		With("instance", ins.ID()).With("phase", "restart")
 	logger.Infof("\tRestarting instance %s", ins.ID())
 
 	if err := systemctl(ctx, e, ins.ServiceName(), "restart", timeout, systemdMode); err != nil {
@@ -372,43 +543,75 @@ func restartInstance(ctx context.Context, ins spec.Instance, timeout uint64, tls
 	return nil
 }
 
-func enableInstance(ctx context.Context, ins spec.Instance, timeout uint64, isEnable bool, systemdMode string) error {
+// InstanceEnabledState returns the enablement state of the systemd unit of the
+// instance, e.g. enabled or disabled, it's empty if the state could not be queried
+func InstanceEnabledState(ctx context.Context, ins spec.Instance, systemdMode string) string {
 	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
-	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	return unitEnabledState(ctx, e, ins.ServiceName(), systemdMode)
+}
 
+// enableInstance enables or disables the unit of the instance if it's not in
+// the state yet, it returns whether the unit is changed. Set reload if the unit
+// or its drop-ins were rewritten, the daemon is reloaded even the unit is kept
+func enableInstance(ctx context.Context, ins spec.Instance, timeout uint64, isEnable, reload bool, systemdMode string) (bool, error) {
+	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
 	action := "disable"
 	if isEnable {
 		action = "enable"
 	}
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger).
+		With("instance", ins.ID()).With("phase", action)
+
+	if unitEnabledState(ctx, e, ins.ServiceName(), systemdMode) == action+"d" {
+		logger.Debugf("\tInstance %s is already %sd", ins.ID(), action)
+		if reload {
+			if err := daemonReload(ctx, e, systemdMode); err != nil {
+				return false, toFailedActionError(err, "daemon-reload", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+			}
+		}
+		return false, nil
+	}
 	logger.Infof("\t%s instance %s", actionPrevMsgs[action], ins.ID())
 
 	// Enable/Disable by systemd.
 	if err := systemctl(ctx, e, ins.ServiceName(), action, timeout, systemdMode); err != nil {
-		return toFailedActionError(err, action, ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+		return false, toFailedActionError(err, action, ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
 	}
 
 	logger.Infof("\t%s instance %s success", actionPostMsgs[action], ins.ID())
 
-	return nil
+	return true, nil
 }
 
-func startInstance(ctx context.Context, ins spec.Instance, timeout uint64, tlsCfg *tls.Config, systemdMode string) error {
+func startInstance(ctx context.Context, ins spec.Instance, timeout uint64, tlsCfg *tls.Config, systemdMode string, hooks map[string]spec.ComponentHooks) (time.Duration, error) {
 	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
-	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger).
+		With("instance", ins.ID()).With("phase", "start")
 	logger.Infof("\tStarting instance %s", ins.ID())
 
+	if err := runHook(ctx, ins, hooks, spec.HookPreStart); err != nil {
+		return 0, err
+	}
+
 	if err := systemctl(ctx, e, ins.ServiceName(), "start", timeout, systemdMode); err != nil {
-		return toFailedActionError(err, "start", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+		return 0, toFailedActionError(err, "start", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
 	}
 
 	// Check ready.
-	if err := ins.Ready(ctx, e, timeout, tlsCfg); err != nil {
-		return toFailedActionError(err, "start", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+	readyBegin := time.Now()
+	err := ins.Ready(ctx, e, timeout, tlsCfg)
+	waited := time.Since(readyBegin)
+	if err != nil {
+		return waited, toFailedActionError(err, "start", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+	}
+
+	if err := runHook(ctx, ins, hooks, spec.HookPostStart); err != nil {
+		return waited, err
 	}
 
 	logger.Infof("\tStart instance %s success", ins.ID())
 
-	return nil
+	return waited, nil
 }
 
 func systemctl(ctx context.Context, executor ctxt.Executor, service string, action string, timeout uint64, scope string) error {
@@ -457,7 +660,16 @@ func EnableComponent(ctx context.Context, instances []spec.Instance, noAgentHost
 		logger.Infof("Disabling component %s", name)
 	}
 
+	minDelay, maxDelay, err := ParseBootDelay(options.BootDelay)
+	if err != nil {
+		return err
+	}
+
+	// the units are re-rendered by the manager before if a template is given
+	reload := isEnable && options.UnitTemplate != ""
+
 	errg, _ := errgroup.WithContext(ctx)
+	var changed, unchanged atomic.Int32
 
 	for _, ins := range instances {
 		ins := ins
@@ -477,15 +689,43 @@ func EnableComponent(ctx context.Context, instances []spec.Instance, noAgentHost
 		// of checkpoint context every time put it into a new goroutine.
 		nctx := checkpoint.NewContext(ctx)
 		errg.Go(func() error {
-			err := enableInstance(nctx, ins, options.OptTimeout, isEnable, systemdMode)
+			// the delay is left untouched when enabling without it configured,
+			// and removed if any when disabling
+			reloadUnit := reload
+			if options.BootDelay != "" || !isEnable {
+				delay := time.Duration(0)
+				if isEnable {
+					delay = bootDelayOf(ins.ID(), minDelay, maxDelay)
+				}
+				e := ctxt.GetInner(nctx).Get(ins.GetManageHost())
+				changed, err := setBootDelay(nctx, e, ins.ServiceName(), delay, systemdMode)
+				if err != nil {
+					return err
+				}
+				reloadUnit = reloadUnit || changed
+			}
+			done, err := enableInstance(nctx, ins, options.OptTimeout, isEnable, reloadUnit, systemdMode)
 			if err != nil {
 				return err
 			}
+			if done {
+				changed.Add(1)
+			} else {
+				unchanged.Add(1)
+			}
 			return nil
 		})
 	}
 
-	return errg.Wait()
+	if err := errg.Wait(); err != nil {
+		return err
+	}
+	action := "Disabled"
+	if isEnable {
+		action = "Enabled"
+	}
+	logger.Infof("%s %d instance(s) of %s, already %s %d", action, changed.Load(), name, strings.ToLower(action), unchanged.Load())
+	return nil
 }
 
 // StartComponent start the instances.
@@ -513,6 +753,12 @@ func StartComponent(ctx context.Context, instances []spec.Instance, noAgentHosts
 
 	errg, _ := errgroup.WithContext(ctx)
 
+	// collect failures of all instances instead of returning the first one
+	// if some failures are tolerated
+	tolerate := options.TolerateFailures != ""
+	var mu sync.Mutex
+	failures := &PartialFailureError{}
+
 	for _, ins := range instances {
 		ins := ins
 		switch name {
@@ -529,37 +775,75 @@ func StartComponent(ctx context.Context, instances []spec.Instance, noAgentHosts
 		// of checkpoint context every time put it into a new goroutine.
 		nctx := checkpoint.NewContext(ctx)
 		errg.Go(func() error {
-			if err := ins.PrepareStart(nctx, tlsCfg); err != nil {
-				return err
+			err := prepareAndStartInstance(nctx, ins, options, tlsCfg, systemdMode)
+			if err != nil && tolerate {
+				mu.Lock()
+				failures.Failures = append(failures.Failures, InstanceError{ID: ins.ID(), Err: err})
+				mu.Unlock()
+				options.Result.warn("failed to start %s, the failure is tolerated: %s", ins.ID(), err)
+				return nil
 			}
-			return startInstance(nctx, ins, options.OptTimeout, tlsCfg, systemdMode)
+			return err
 		})
 	}
 
-	return errg.Wait()
+	if err := errg.Wait(); err != nil {
+		return err
+	}
+	if len(failures.Failures) > 0 {
+		return failures
+	}
+	return nil
 }
 
 func serialStartInstances(ctx context.Context, instances []spec.Instance, options Options, tlsCfg *tls.Config, systemdMode string) error {
 	for _, ins := range instances {
-		if err := ins.PrepareStart(ctx, tlsCfg); err != nil {
-			return err
-		}
-		if err := startInstance(ctx, ins, options.OptTimeout, tlsCfg, systemdMode); err != nil {
+		if err := prepareAndStartInstance(ctx, ins, options, tlsCfg, systemdMode); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func stopInstance(ctx context.Context, ins spec.Instance, timeout uint64, systemdMode string) error {
+// prepareAndStartInstance starts the instance and records the outcome to the result of options
+func prepareAndStartInstance(ctx context.Context, ins spec.Instance, options Options, tlsCfg *tls.Config, systemdMode string) error {
+	begin := time.Now()
+	var waited time.Duration
+	err := ins.PrepareStart(ctx, tlsCfg)
+	if err == nil {
+		waited, err = startInstance(ctx, ins, options.OptTimeout, tlsCfg, systemdMode, options.hooks)
+	}
+	options.Result.record(ins, "start", begin, waited, err)
+	return err
+}
+
+func stopInstance(ctx context.Context, ins spec.Instance, timeout uint64, systemdMode string, hooks map[string]spec.ComponentHooks, verify bool) error {
 	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
-	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger).
+		With("instance", ins.ID()).With("phase", "stop")
 	logger.Infof("\tStopping instance %s", ins.GetManageHost())
 
+	if err := runHook(ctx, ins, hooks, spec.HookPreStop); err != nil {
+		return err
+	}
+
 	if err := systemctl(ctx, e, ins.ServiceName(), "stop", timeout, systemdMode); err != nil {
 		return toFailedActionError(err, "stop", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
 	}
 
+	// systemd may take the unit as stopped while a process left behind still
+	// holds the port, which makes the next start fail, so make sure it's closed
+	if verify {
+		if err := spec.PortStopped(ctx, e, ins.GetPort(), timeout); err != nil {
+			err = errors.Annotatef(err, "port %d is still in use after stopping, a process may be stuck", ins.GetPort())
+			return toFailedActionError(err, "stop", ins.GetManageHost(), ins.ServiceName(), ins.LogDir())
+		}
+	}
+
+	if err := runHook(ctx, ins, hooks, spec.HookPostStop); err != nil {
+		return err
+	}
+
 	logger.Infof("\tStop %s %s success", ins.ComponentName(), ins.ID())
 
 	return nil
@@ -583,6 +867,7 @@ func StopComponent(ctx context.Context,
 	name := instances[0].ComponentName()
 	logger.Infof("Stopping component %s", name)
 	systemdMode := string(topo.BaseTopo().GlobalOptions.SystemdMode)
+	hooks := topo.BaseTopo().GlobalOptions.Hooks
 	errg, _ := errgroup.WithContext(ctx)
 
 	for _, ins := range instances {
@@ -608,7 +893,10 @@ func StopComponent(ctx context.Context,
 					return err
 				}
 			}
-			if err := stopInstance(nctx, ins, options.OptTimeout, systemdMode); err != nil {
+			begin := time.Now()
+			err := stopInstance(nctx, ins, options.OptTimeout, systemdMode, hooks, !options.SkipStopVerify)
+			options.Result.record(ins, "stop", begin, 0, err)
+			if err != nil {
 				return err
 			}
 			// continue here, to skip the logic below.
@@ -629,7 +917,9 @@ func StopComponent(ctx context.Context,
 					}
 				}
 			}
-			err := stopInstance(nctx, ins, options.OptTimeout, systemdMode)
+			begin := time.Now()
+			err := stopInstance(nctx, ins, options.OptTimeout, systemdMode, hooks, !options.SkipStopVerify)
+			options.Result.record(ins, "stop", begin, 0, err)
 			if err != nil {
 				return err
 			}
@@ -640,6 +930,29 @@ func StopComponent(ctx context.Context,
 	return errg.Wait()
 }
 
+// runHook executes the lifecycle hook of the instance's component if it's defined
+func runHook(ctx context.Context, ins spec.Instance, hooks map[string]spec.ComponentHooks, stage string) error {
+	hook := hooks[ins.ComponentName()].Get(stage)
+	if hook == nil || hook.Command == "" {
+		return nil
+	}
+
+	e := ctxt.GetInner(ctx).Get(ins.GetManageHost())
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger.Infof("\tRunning %s hook of instance %s", stage, ins.ID())
+
+	// hooks are executed with the deploy user
+	_, stderr, err := e.Execute(ctx, hook.Command, false)
+	if err == nil {
+		return nil
+	}
+	if hook.BestEffort {
+		logger.Warnf("\t%s hook of instance %s failed and is ignored: %s", stage, ins.ID(), err)
+		return nil
+	}
+	return errors.Annotatef(err, "%s hook of instance %s failed, stderr: %s", stage, ins.ID(), string(stderr))
+}
+
 // toFailedActionError formats the errror msg for failed action
 func toFailedActionError(err error, action string, host, service, logDir string) error {
 	return errors.Annotatef(err,
@@ -672,3 +985,52 @@ func executeSSHCommand(ctx context.Context, action, host, command string) error
 	logger.Infof("\t%s", stdout)
 	return nil
 }
+
+// warmupComponent waits for the warmup delay of the component after its instances are ready
+func warmupComponent(ctx context.Context, name string, count int, delay time.Duration) error {
+	if delay <= 0 || count == 0 {
+		return nil
+	}
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger.Infof("\tWaiting %s for %s to warm up", delay, name)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// ParseDelayStart parses the warmup delays in the form of role=duration, all
+// the roles must be in validRoles
+func ParseDelayStart(items map[string]string, validRoles []string) (map[string]time.Duration, error) {
+	delays := make(map[string]time.Duration, len(items))
+	for role, value := range items {
+		if err := checkRoles([]string{role}, validRoles); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, errors.Errorf("invalid warmup delay '%s' of %s, should be a duration like 30s", value, role)
+		}
+		delays[role] = d
+	}
+	return delays, nil
+}
+
+// ParseRestartBatch parses the restart batch sizes in the form of role=size,
+// all the roles must be in validRoles
+func ParseRestartBatch(items map[string]string, validRoles []string) (map[string]int, error) {
+	sizes := make(map[string]int, len(items))
+	for role, value := range items {
+		if err := checkRoles([]string{role}, validRoles); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, errors.Errorf("invalid batch size '%s' of %s, should be a positive integer", value, role)
+		}
+		sizes[role] = n
+	}
+	return sizes, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+const (
+	// bootDelayDropIn is the name of the systemd drop-in holding the startup delay
+	bootDelayDropIn = "tiup-boot-delay.conf"
+	// bootDelayUptime is how long after boot the delay still applies, so that
+	// starting the service manually later is not slowed down
+	bootDelayUptime = 10 * time.Minute
+)
+
+// ParseBootDelay parses the startup delay range in the form of `D` or `MIN-MAX`,
+// e.g. `30s` or `10s-2m`, an empty string means no delay configured
+func ParseBootDelay(delay string) (minDelay, maxDelay time.Duration, err error) {
+	if delay == "" {
+		return 0, 0, nil
+	}
+	lo, hi, isRange := strings.Cut(delay, "-")
+	if minDelay, err = time.ParseDuration(strings.TrimSpace(lo)); err != nil {
+		return 0, 0, fmt.Errorf("invalid boot delay '%s': %s", delay, err)
+	}
+	maxDelay = minDelay
+	if isRange {
+		if maxDelay, err = time.ParseDuration(strings.TrimSpace(hi)); err != nil {
+			return 0, 0, fmt.Errorf("invalid boot delay '%s': %s", delay, err)
+		}
+	}
+	if minDelay < 0 || maxDelay < minDelay {
+		return 0, 0, fmt.Errorf("invalid boot delay '%s', should be non-negative and MIN <= MAX", delay)
+	}
+	return minDelay, maxDelay, nil
+}
+
+// bootDelayOf picks the startup delay of the instance in [minDelay, maxDelay]
+// in seconds, it is derived from the instance ID so instances are spread
+// over the range while re-running enable keeps the same delay
+func bootDelayOf(id string, minDelay, maxDelay time.Duration) time.Duration {
+	spread := int64((maxDelay - minDelay) / time.Second)
+	if spread <= 0 {
+		return minDelay.Truncate(time.Second)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return minDelay.Truncate(time.Second) + time.Duration(int64(h.Sum32())%(spread+1))*time.Second
+}
+
+// bootDelayUnit renders the drop-in that sleeps before starting the service
+// if the host has just booted, `$$` is the escaped `$` for systemd
+func bootDelayUnit(delay time.Duration) string {
+	return fmt.Sprintf(`# generated by TiUP, stagger the service start at boot time
+[Service]
+ExecStartPre=/bin/sh -c 'if [ "$$(cut -d. -f1 /proc/uptime)" -lt %d ]; then sleep %d; fi'
+`, int(bootDelayUptime/time.Second), int(delay/time.Second))
+}
+
+// setBootDelay writes or removes (when delay is 0) the startup delay drop-in
+// of the service, it returns whether the drop-in is changed, the caller must
+// reload the daemon to apply it then
+func setBootDelay(ctx context.Context, e ctxt.Executor, service string, delay time.Duration, systemdMode string) (bool, error) {
+	systemdDir := "/etc/systemd/system/"
+	sudo := true
+	if systemdMode == string(spec.UserMode) {
+		systemdDir = "~/.config/systemd/user/"
+		sudo = false
+	}
+	dropInDir := fmt.Sprintf("%s%s.d", systemdDir, service)
+
+	if delay > 0 {
+		// the content is encoded to survive the quoting of sudo
+		cmd := fmt.Sprintf("mkdir -p %[1]s && echo %[2]s | base64 -d > %[1]s/%[3]s",
+			dropInDir, base64.StdEncoding.EncodeToString([]byte(bootDelayUnit(delay))), bootDelayDropIn)
+		if _, stderr, err := e.Execute(ctx, cmd, sudo); err != nil {
+			return false, errors.Annotatef(err, "failed to set boot delay of %s: %s", service, stderr)
+		}
+		return true, nil
+	}
+
+	// most of the units never have the drop-in
+	cmd := fmt.Sprintf("if [ -f %[1]s/%[2]s ]; then rm -f %[1]s/%[2]s && echo removed; fi", dropInDir, bootDelayDropIn)
+	stdout, stderr, err := e.Execute(ctx, cmd, sudo)
+	if err != nil {
+		return false, errors.Annotatef(err, "failed to remove boot delay of %s: %s", service, stderr)
+	}
+	return strings.TrimSpace(string(stdout)) == "removed", nil
+}
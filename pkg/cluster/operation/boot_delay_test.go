@@ -0,0 +1,68 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// shellExecutor runs the commands with the local shell
+type shellExecutor struct{}
+
+func (shellExecutor) Execute(ctx context.Context, cmd string, _ bool, _ ...time.Duration) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err := c.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+func (shellExecutor) Transfer(context.Context, string, string, bool, int, bool) error {
+	return nil
+}
+
+func TestSetBootDelay(t *testing.T) {
+	assert := require.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dropIn := filepath.Join(home, ".config/systemd/user/tikv-20160.service.d", bootDelayDropIn)
+	ctx := context.Background()
+	mode := string(spec.UserMode)
+
+	// nothing to remove
+	changed, err := setBootDelay(ctx, shellExecutor{}, "tikv-20160.service", 0, mode)
+	assert.Nil(err)
+	assert.False(changed)
+
+	changed, err = setBootDelay(ctx, shellExecutor{}, "tikv-20160.service", 30*time.Second, mode)
+	assert.Nil(err)
+	assert.True(changed)
+	data, err := os.ReadFile(dropIn)
+	assert.Nil(err)
+	assert.Equal(bootDelayUnit(30*time.Second), string(data))
+
+	changed, err = setBootDelay(ctx, shellExecutor{}, "tikv-20160.service", 0, mode)
+	assert.Nil(err)
+	assert.True(changed)
+	assert.NoFileExists(dropIn)
+}
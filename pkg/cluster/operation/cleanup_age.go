@@ -0,0 +1,120 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+)
+
+// ParseFileAge parses the age of files to be cleaned up, it's either a number of
+// days like 7d or a duration like 12h
+func ParseFileAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, perrs.Errorf("invalid age '%s', should be like 7d or 12h", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, perrs.Errorf("invalid age '%s', should be like 7d or 12h", s)
+	}
+	return d, nil
+}
+
+// FormatFileAge formats the age the same way as ParseFileAge accepts
+func FormatFileAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// CleanupFilter narrows down the files to be cleaned up
+type CleanupFilter struct {
+	OlderThan time.Duration // only the files modified longer than it ago, 0 means all files
+	Exclude   []string      // names of the entries directly under the cleaned dirs to keep
+}
+
+// IsZero checks whether the filter keeps nothing
+func (f CleanupFilter) IsZero() bool {
+	return f.OlderThan <= 0 && len(f.Exclude) == 0
+}
+
+// ValidateCleanupExclude checks the names to be excluded from cleaning up are
+// plain names of entries, e.g. backup
+func ValidateCleanupExclude(names []string) error {
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/'") {
+			return perrs.Errorf("invalid name '%s' to exclude, it must be the name of an entry in the data directory", name)
+		}
+	}
+	return nil
+}
+
+// ValidateCleanupPaths checks the extra paths to be cleaned up are relative
+// ones staying inside the deploy directory, e.g. bin/old-version
+func ValidateCleanupPaths(paths []string) error {
+	for _, p := range paths {
+		clean := path.Clean(p)
+		if p == "" || path.IsAbs(p) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") ||
+			strings.ContainsAny(p, "'\" \t\n;&|$`") {
+			return perrs.Errorf("invalid path '%s' to cleanup, it must be a path relative to the deploy directory", p)
+		}
+	}
+	return nil
+}
+
+// FindCommand runs the action on each file matching the paths and the filter,
+// the paths are globs in their last element, e.g. `/log/*.log`. The action is
+// the tail of the find command like `-exec rm -rf {} +`, and the dirs that do
+// not exist are skipped as `rm -rf` does.
+func FindCommand(paths []string, filter CleanupFilter, action string) string {
+	var conds []string
+	for _, name := range filter.Exclude {
+		conds = append(conds, fmt.Sprintf("! -name '%s'", name))
+	}
+	if filter.OlderThan > 0 {
+		// -mmin +N matches the files modified more than N minutes ago
+		conds = append(conds, fmt.Sprintf("-mmin +%d", int64(filter.OlderThan/time.Minute)))
+	}
+	cond := strings.Join(conds, " ")
+
+	cmds := make([]string, 0, len(paths))
+	for _, p := range paths {
+		dir, pattern := path.Dir(p), path.Base(p)
+		cmds = append(cmds, fmt.Sprintf("if [ -d %[1]s ]; then find %[1]s -mindepth 1 -maxdepth 1 -name '%[2]s' %[3]s %[4]s; fi",
+			dir, pattern, cond, action))
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// cleanupCommand deletes the paths, or only the files matching the filter if it's set
+func cleanupCommand(paths []string, filter CleanupFilter) string {
+	if filter.IsZero() {
+		return fmt.Sprintf("rm -rf %s;", strings.Join(paths, " "))
+	}
+	return FindCommand(paths, filter, "-exec rm -rf {} +")
+}
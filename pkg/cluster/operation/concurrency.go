@@ -0,0 +1,83 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ConcurrencyAuto is the value of Options.Concurrency meaning that it should
+// be sized by the number of hosts with AutoConcurrency before running tasks
+const ConcurrencyAuto = -1
+
+// concurrency bounds of the auto sizing
+const (
+	// DefaultConcurrency is the concurrency used when it can't be sized
+	DefaultConcurrency = 5
+	// DefaultConcurrencyCap is the default upper bound of the auto sizing
+	DefaultConcurrencyCap = 32
+)
+
+// AutoConcurrency sizes the concurrency by the number of unique hosts: one
+// parallel task per host so that every host is busy while none of them is
+// flooded with SSH sessions, no less than DefaultConcurrency for small
+// clusters and no more than the cap to keep the local file descriptors and
+// network connections bounded for large ones.
+func AutoConcurrency(hosts, limit int) int {
+	if limit <= 0 {
+		limit = DefaultConcurrencyCap
+	}
+	c := max(hosts, DefaultConcurrency)
+	return min(c, limit)
+}
+
+// ConcurrencyValue is a pflag.Value accepting a positive number or `auto`
+// for the concurrency
+type ConcurrencyValue struct {
+	v *int
+}
+
+// NewConcurrencyValue creates a ConcurrencyValue with the default value
+func NewConcurrencyValue(v *int, def int) *ConcurrencyValue {
+	*v = def
+	return &ConcurrencyValue{v: v}
+}
+
+// Set implements pflag.Value
+func (c *ConcurrencyValue) Set(s string) error {
+	if s == "auto" {
+		*c.v = ConcurrencyAuto
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid concurrency '%s', should be a positive number or auto", s)
+	}
+	*c.v = n
+	return nil
+}
+
+// String implements pflag.Value
+func (c *ConcurrencyValue) String() string {
+	if *c.v == ConcurrencyAuto {
+		return "auto"
+	}
+	return strconv.Itoa(*c.v)
+}
+
+// Type implements pflag.Value
+func (c *ConcurrencyValue) Type() string {
+	return "concurrency"
+}
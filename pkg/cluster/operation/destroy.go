@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -34,6 +36,7 @@ import (
 	"github.com/pingcap/tiup/pkg/proxy"
 	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/sync/errgroup"
 )
 
 // Destroy the cluster.
@@ -307,31 +310,57 @@ func DestroyMonitored(ctx context.Context, inst spec.Instance, options *spec.Mon
 	return nil
 }
 
-// CleanupComponent cleanup the instances
-func CleanupComponent(ctx context.Context, delFileMaps map[string]set.StringSet, sudo bool) error {
+// CleanupComponent cleanup the instances, only the files matching the filter are
+// deleted if it's set. The data, log and tls files of a host are deleted in
+// parallel, so a slow wipe of large data dirs doesn't hold up the others.
+func CleanupComponent(ctx context.Context, delFileMaps map[string]set.StringSet, sudo bool, filter CleanupFilter) error {
 	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
-	for host, delFiles := range delFileMaps {
+	hosts := make([]string, 0, len(delFileMaps))
+	for host := range delFileMaps {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
 		e := ctxt.GetInner(ctx).Get(host)
 		logger.Infof("Cleanup instance %s", host)
-		logger.Debugf("Deleting paths on %s: %s", host, strings.Join(delFiles.Slice(), " "))
-		c := module.ShellModuleConfig{
-			Command:  fmt.Sprintf("rm -rf %s;", strings.Join(delFiles.Slice(), " ")),
-			Sudo:     sudo, // the .service files are in a directory owned by root
-			Chdir:    "",
-			UseShell: true,
-		}
-		shell := module.NewShellModule(c)
-		stdout, stderr, err := shell.Execute(ctx, e)
 
-		if len(stdout) > 0 {
-			fmt.Println(string(stdout))
-		}
-		if len(stderr) > 0 {
-			logger.Errorf(string(stderr))
+		groups := groupCleanupPaths(delFileMaps[host].Slice())
+		errg, _ := errgroup.WithContext(ctx)
+		if limit := ctxt.GetInner(ctx).Concurrency; limit > 0 {
+			errg.SetLimit(limit)
 		}
+		for _, category := range cleanupCategories {
+			delFiles := groups[category]
+			if len(delFiles) == 0 {
+				continue
+			}
+			category := category
+			errg.Go(func() error {
+				logger.Debugf("Deleting %s paths on %s: %s", category, host, strings.Join(delFiles, " "))
+				c := module.ShellModuleConfig{
+					Command:  cleanupCommand(delFiles, filter),
+					Sudo:     sudo, // the .service files are in a directory owned by root
+					Chdir:    "",
+					UseShell: true,
+				}
+				shell := module.NewShellModule(c)
+				stdout, stderr, err := shell.Execute(ctx, e)
 
-		if err != nil {
-			return perrs.Annotatef(err, "failed to cleanup: %s", host)
+				if len(stdout) > 0 {
+					fmt.Println(string(stdout))
+				}
+				if len(stderr) > 0 {
+					logger.Errorf(string(stderr))
+				}
+
+				if err != nil {
+					return perrs.Annotatef(err, "failed to cleanup %s: %s", category, host)
+				}
+				return nil
+			})
+		}
+		if err := errg.Wait(); err != nil {
+			return err
 		}
 
 		logger.Infof("Cleanup %s success", host)
@@ -340,6 +369,38 @@ func CleanupComponent(ctx context.Context, delFileMaps map[string]set.StringSet,
 	return nil
 }
 
+// categories of the paths to cleanup, each of them is deleted in its own step
+var cleanupCategories = []string{"data", "log", "crash", "tls", "extra"}
+
+// CoreDumpPatterns are the names of core dump files left by crashed components
+// in their working dirs, i.e. the deploy dirs, and data dirs
+var CoreDumpPatterns = []string{"core", "core.*", "*.core"}
+
+// groupCleanupPaths groups the paths by category: log files, content of data
+// dirs, core dumps, tls dirs and the extra paths in deploy dirs, the paths
+// are sorted
+func groupCleanupPaths(paths []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		category := "extra"
+		switch {
+		case filepath.Base(p) == spec.TLSCertKeyDir || filepath.Base(p) == spec.TLSCertKeyDirWithAnsible:
+			category = "tls"
+		case slices.Contains(CoreDumpPatterns, filepath.Base(p)):
+			category = "crash"
+		case strings.HasSuffix(p, ".log"):
+			category = "log"
+		case strings.HasSuffix(p, "/*"):
+			category = "data"
+		}
+		groups[category] = append(groups[category], p)
+	}
+	for _, g := range groups {
+		sort.Strings(g)
+	}
+	return groups
+}
+
 // DestroyComponent destroy the instances.
 func DestroyComponent(ctx context.Context, instances []spec.Instance, cls spec.Topology, options Options) error {
 	if len(instances) == 0 {
@@ -436,6 +497,13 @@ func DestroyComponent(ctx context.Context, instances []spec.Instance, cls spec.T
 			delPaths.Insert(deployDir)
 		}
 
+		// the extra paths are only needed if the deploy dir itself is kept
+		if !dataRetained && !delPaths.Exist(deployDir) {
+			for _, p := range options.CleanupPaths {
+				delPaths.Insert(filepath.Join(deployDir, p))
+			}
+		}
+
 		systemdDir := "/etc/systemd/system/"
 		sudo := true
 		if cls.BaseTopo().GlobalOptions.SystemdMode == spec.UserMode {
@@ -0,0 +1,138 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-units"
+	perrs "github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMinFreeSpace is the default threshold of free space on the data dirs
+// checked before starting instances
+const DefaultMinFreeSpace = 1 * units.GiB
+
+// lowSpaceDir is a data dir with less free space than the threshold
+type lowSpaceDir struct {
+	ID   string
+	Host string
+	Dir  string
+	Free uint64 // in bytes
+}
+
+// ParseMinFreeSpace parses the free space threshold like 10GiB, an empty
+// string or 0 disables the check
+func ParseMinFreeSpace(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := units.RAMInBytes(s)
+	if err != nil || v < 0 {
+		return 0, perrs.Errorf("invalid free space threshold '%s', should be like 10GiB", s)
+	}
+	return uint64(v), nil
+}
+
+// checkDataDirSpace queries the free space of the data dirs of the instances
+// before they are started, dirs below the threshold are warned about or abort
+// the start. Dirs failed to be queried, e.g. not created yet, are skipped.
+func checkDataDirSpace(ctx context.Context, insts []spec.Instance, user string, options Options) error {
+	if options.MinFreeSpace == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	var low []lowSpaceDir
+	errg, _ := errgroup.WithContext(ctx)
+	for _, inst := range insts {
+		if inst.DataDir() == "" {
+			continue
+		}
+		e, found := ctxt.GetInner(ctx).GetExecutor(inst.GetManageHost())
+		if !found {
+			continue
+		}
+		for _, dir := range spec.MultiDirAbs(user, inst.DataDir()) {
+			inst, dir := inst, dir
+			errg.Go(func() error {
+				stdout, _, err := e.Execute(ctx, fmt.Sprintf("df -Pk '%s'", dir), false)
+				if err != nil {
+					return nil
+				}
+				free, ok := parseDfAvail(stdout)
+				if !ok || free >= options.MinFreeSpace {
+					return nil
+				}
+				mu.Lock()
+				low = append(low, lowSpaceDir{ID: inst.ID(), Host: inst.GetManageHost(), Dir: dir, Free: free})
+				mu.Unlock()
+				return nil
+			})
+		}
+	}
+	_ = errg.Wait()
+	if len(low) == 0 {
+		return nil
+	}
+
+	sort.Slice(low, func(i, j int) bool {
+		if low[i].ID != low[j].ID {
+			return low[i].ID < low[j].ID
+		}
+		return low[i].Dir < low[j].Dir
+	})
+	threshold := units.BytesSize(float64(options.MinFreeSpace))
+	msgs := make([]string, 0, len(low))
+	for _, d := range low {
+		msgs = append(msgs, fmt.Sprintf("%s: %s has %s free", d.ID, d.Dir, units.BytesSize(float64(d.Free))))
+	}
+	if options.AbortOnLowSpace {
+		return perrs.Errorf("free space of data dirs is below %s:\n\t%s", threshold, strings.Join(msgs, "\n\t"))
+	}
+
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger.Warnf("Free space of data dirs is below %s, the instances may fail after start:", threshold)
+	for _, msg := range msgs {
+		logger.Warnf("\t%s", msg)
+		options.Result.warn("low free space on %s", msg)
+	}
+	return nil
+}
+
+// parseDfAvail parses the available space in bytes from the output of `df -Pk`
+func parseDfAvail(stdout []byte) (uint64, bool) {
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	if len(lines) < 2 {
+		return 0, false
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, false
+	}
+	kb, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return kb * 1024, true
+}
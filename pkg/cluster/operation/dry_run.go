@@ -0,0 +1,39 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/pingcap/tiup/pkg/set"
+)
+
+// dryRunComponents prints the instances the action would be applied to in
+// order instead of applying it, for the operations running with --dry-run
+func dryRunComponents(ctx context.Context, action string, components []spec.Component, nodeFilter set.StringSet, options Options) {
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	logger.Warnf("DRY RUN: nothing is changed, the instances to %s are:", action)
+	for _, comp := range components {
+		insts := FilterInstanceBy(FilterInstance(comp.Instances(), nodeFilter), options.InstanceFilter())
+		if len(insts) == 0 {
+			continue
+		}
+		logger.Infof("Would %s component %s", action, comp.Name())
+		for _, inst := range insts {
+			logger.Infof("\t%s %s", action, inst.ID())
+		}
+	}
+}
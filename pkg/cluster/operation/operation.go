@@ -14,9 +14,15 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/cluster/spec"
 	"github.com/pingcap/tiup/pkg/set"
@@ -34,12 +40,16 @@ type Options struct {
 	Nodes               []string
 	Force               bool             // Option for upgrade/tls subcommand
 	SSHTimeout          uint64           // timeout in seconds when connecting an SSH server
+	SSHKeepAlive        uint64           // interval in seconds of keepalive messages on SSH connections, 0 disables them
 	OptTimeout          uint64           // timeout in seconds for operations that support it, not to confuse with SSH timeout
+	WaitJitter          uint64           // percentage of the interval between polls of waiting for ports to randomize, 0 disables it
+	OperationTimeout    uint64           // timeout in seconds of the whole operation, 0 means no limit
 	APITimeout          uint64           // timeout in seconds for API operations that support it, like transferring store leader
 	IgnoreConfigCheck   bool             // should we ignore the config check result after init config
 	NativeSSH           bool             // should use native ssh client or builtin easy ssh (deprecated, shoule use SSHType)
 	SSHType             executor.SSHType // the ssh type: 'builtin', 'system', 'none'
-	Concurrency         int              // max number of parallel tasks to run
+	Concurrency         int              // max number of parallel tasks to run, ConcurrencyAuto to size it by hosts
+	ConcurrencyCap      int              // upper bound of the auto sized concurrency
 	SSHProxyHost        string           // the ssh proxy host
 	SSHProxyPort        int              // the ssh proxy port
 	SSHProxyUser        string           // the ssh proxy user
@@ -47,18 +57,130 @@ type Options struct {
 	SSHProxyUsePassword bool             // use password instead of identity file for ssh proxy connection
 	SSHProxyTimeout     uint64           // timeout in seconds when connecting the proxy host
 	SSHCustomScripts    SSHCustomScripts // custom scripts to be executed during the operation
+	ConfigOverrides     []string         // one-off config overrides in `component.key=value` form, not saved to the topology
+	TolerateFailures    string           // number (N) or percentage (N%) of instances allowed to fail when starting
+	IfChanged           bool             // only restart the instances whose deployed config changed when reloading
+	AtomicReload        bool             // push the config of each instance right before restarting it when reloading
+
+	// Filter is an optional extra predicate ANDed with the role and node selection
+	// of lifecycle operations, nil means no extra filtering
+	Filter func(spec.Instance) bool
+	// Labels selects the instances having all of the instance labels, it's
+	// ANDed with the role and node selection as well
+	Labels map[string]string
+
+	// DelayStart is the warmup delay after all the instances of a component are ready
+	// when starting, before moving on to the components depending on it, keyed by
+	// the component name, components not in it are not delayed
+	DelayStart map[string]time.Duration
+
+	// ExcludeNodes and ExcludeRoles are subtracted from the instances selected
+	// by the other filters, nodes are matched by instance IDs
+	ExcludeNodes []string
+	ExcludeRoles []string
+
+	// ParallelRoles are the groups of roles whose instances could be started
+	// concurrently, see StartStages for how they are ordered
+	ParallelRoles [][]string
+
+	// SkipStopVerify skips waiting for the port of each stopped instance to be
+	// closed, by default a port still in use after the timeout fails the stop
+	SkipStopVerify bool
+
+	// StopOrder overrides the order of stopping components, the listed roles are
+	// stopped first in the order and the rest in the default order after them
+	StopOrder []string
+
+	// WaitHealthy waits until the status APIs of the started instances report
+	// healthy after the cluster is started, in the wait timeout
+	WaitHealthy bool
+
+	// RestartBatch restarts the instances of each component in batches of the
+	// size instead of all at once, RestartBatchRoles overrides it for the listed
+	// components, 0 means restarting all the instances of a component together
+	RestartBatch      int
+	RestartBatchRoles map[string]int
+
+	// RestartBatchGate is an optional gate run after each batch is restarted, e.g.
+	// to wait for the instances to be healthy, its error stops the restart
+	RestartBatchGate func(ctx context.Context, topo spec.Topology, nodes []string) error
+
+	// BeforeRestartInstance is an optional hook run on each instance right before
+	// it's restarted in a rolling upgrade, e.g. to push its config so the two are
+	// done as one unit, its error fails the upgrade
+	BeforeRestartInstance func(ctx context.Context, inst spec.Instance) error
+
+	// SilenceAlerts silences the alerts of the cluster in its Alertmanager for
+	// SilenceDuration during stop and restart. The silence is lifted after the
+	// restart finishes, and kept after the stop until it expires.
+	SilenceAlerts   bool
+	SilenceDuration time.Duration
+
+	// VerifyBinary checks the binaries of the instances against the checksums
+	// recorded when deployed before starting them, a mismatch aborts the start
+	VerifyBinary bool
+
+	// PostStartVerify is an optional gate run after the cluster is started, e.g. to
+	// smoke test the SQL endpoint, its error fails the start operation
+	PostStartVerify func(ctx context.Context, topo spec.Topology) error
+
+	// MinFreeSpace is the threshold in bytes of free space on the data dirs checked
+	// before starting instances, 0 disables the check. The instances below it are
+	// warned about, or abort the start if AbortOnLowSpace is set.
+	MinFreeSpace    uint64
+	AbortOnLowSpace bool
+
+	// OnlyFailed selects the instances not succeeded in the last run of the
+	// same operation instead of the role and node selection
+	OnlyFailed bool
+
+	// Result is an optional sink of the per-instance outcome of start and stop,
+	// it's shared by the copies of the options
+	Result *OperationResult
 
 	// What type of things should we cleanup in clean command
-	CleanupData     bool // should we cleanup data
-	CleanupLog      bool // should we clenaup log
-	CleanupAuditLog bool // should we clenaup tidb server auit log
+	CleanupData     bool          // should we cleanup data
+	CleanupLog      bool          // should we clenaup log
+	CleanupAuditLog bool          // should we clenaup tidb server auit log
+	CleanupCrashes  bool          // should we cleanup the core dumps in deploy and data dirs
+	DryRun          bool          // only print what would be done without side effects, e.g. the files to be deleted
+	FollowSymlinks  bool          // cleanup the content of data dirs even if they are symlinks
+	EstimateSize    bool          // estimate the space to be freed before cleaning up
+	AgeReport       bool          // report the age distribution of the files before cleaning up
+	OlderThan       time.Duration // only cleanup the files modified longer than it ago, 0 means all files
+	ExcludeData     []string      // names of the entries in data dirs to keep, e.g. backup
+	CleanupPaths    []string      // extra paths relative to the deploy dirs to cleanup, e.g. bin/old-version
+	PlanFile        string        // write the files to be deleted to the path before cleaning up, as JSON if it ends with .json
+
+	IgnoreProtection bool // run destructive operations even if the cluster is protected
+
+	// ConfirmToken confirms destructive operations without the interactive
+	// prompt if it's the name of the cluster, and aborts them otherwise
+	ConfirmToken string
+
+	// Remove the certificates on the hosts when destroying the cluster even if
+	// TLS is enabled, they are always kept by clean to not break the cluster
+	CleanupTLS bool
 
 	// Some data will be retained when destroying instances
 	RetainDataRoles []string
 	RetainDataNodes []string
 
-	DisplayMode string // the output format
-	Operation   Operation
+	// Only the data of the instances is cleaned by clean, everything in their
+	// deploy dirs is kept even if other categories are selected, so they could
+	// be brought back quickly with the config and binaries in place
+	DataOnlyRoles []string
+	DataOnlyNodes []string
+
+	UnitTemplate string // path of the user supplied systemd unit template to re-render units on enable
+	BootDelay    string // range of the startup delay at boot written into units on enable, `D` or `MIN-MAX`
+
+	DisplayMode  string // the output format
+	StreamOutput bool   // stream the output of remote commands line by line as they run
+	Profile      bool   // print the number and time of remote commands per host after the operation
+	Operation    Operation
+
+	hooks map[string]spec.ComponentHooks // lifecycle hooks of components, set by the operation
 }
 
 // SSHCustomScripts represents the custom ssh script set to be executed during cluster operations
@@ -114,6 +236,16 @@ func (op Operation) String() string {
 	return fmt.Sprintf("unknonw-op(%d)", op)
 }
 
+// checkRoles checks all the roles are in validRoles
+func checkRoles(roles []string, validRoles []string) error {
+	for _, r := range roles {
+		if !slices.Contains(validRoles, r) {
+			return errors.Errorf("not valid role: %s, should be one of: %v", r, validRoles)
+		}
+	}
+	return nil
+}
+
 // FilterComponent filter components by set
 func FilterComponent(comps []spec.Component, components set.StringSet) (res []spec.Component) {
 	if len(components) == 0 {
@@ -155,3 +287,108 @@ func FilterInstance(instances []spec.Instance, nodes set.StringSet) (res []spec.
 
 	return
 }
+
+// CleanupFilter returns the filter of files to be cleaned up
+func (opt *Options) CleanupFilter() CleanupFilter {
+	return CleanupFilter{
+		OlderThan: opt.OlderThan,
+		Exclude:   opt.ExcludeData,
+	}
+}
+
+// InstanceFilter returns the predicate combining Filter, Labels and the
+// exclusions, nil if none of them is set
+func (opt *Options) InstanceFilter() func(spec.Instance) bool {
+	filter := opt.Filter
+	if len(opt.Labels) > 0 {
+		labels, inner := opt.Labels, filter
+		filter = func(inst spec.Instance) bool {
+			return MatchLabels(inst, labels) && (inner == nil || inner(inst))
+		}
+	}
+	if len(opt.ExcludeNodes) > 0 || len(opt.ExcludeRoles) > 0 {
+		nodes, roles, inner := set.NewStringSet(opt.ExcludeNodes...), set.NewStringSet(opt.ExcludeRoles...), filter
+		filter = func(inst spec.Instance) bool {
+			if nodes.Exist(inst.ID()) || roles.Exist(inst.ComponentName()) || roles.Exist(inst.Role()) {
+				return false
+			}
+			return inner == nil || inner(inst)
+		}
+	}
+	return filter
+}
+
+// BatchSize returns the size of the restart batches of the component
+func (opt *Options) BatchSize(role string) int {
+	if size, ok := opt.RestartBatchRoles[role]; ok {
+		return size
+	}
+	return opt.RestartBatch
+}
+
+// MatchLabels checks whether the instance has all the labels
+func MatchLabels(inst spec.Instance, labels map[string]string) bool {
+	instLabels := inst.InstanceLabels()
+	for k, v := range labels {
+		if val, ok := instLabels[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterInstanceBy filter instances by the predicate, nil predicate keeps all of them
+func FilterInstanceBy(instances []spec.Instance, filter func(spec.Instance) bool) (res []spec.Instance) {
+	if filter == nil {
+		res = instances
+		return
+	}
+
+	for _, c := range instances {
+		if !filter(c) {
+			continue
+		}
+		res = append(res, c)
+	}
+
+	return
+}
+
+// InstanceError is the error of a single instance in a lifecycle operation
+type InstanceError struct {
+	ID  string
+	Err error
+}
+
+// PartialFailureError represents that some of the instances failed in a lifecycle operation
+type PartialFailureError struct {
+	Failures []InstanceError
+}
+
+func (e *PartialFailureError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", f.ID, f.Err))
+	}
+	return fmt.Sprintf("%d instance(s) failed:\n%s", len(e.Failures), strings.Join(msgs, "\n"))
+}
+
+// ParseFailureThreshold parses the tolerance in the form of `N` or `N%` and
+// returns the max number of instances allowed to fail among total ones
+func ParseFailureThreshold(tolerance string, total int) (int, error) {
+	if tolerance == "" {
+		return 0, nil
+	}
+	if p, ok := strings.CutSuffix(tolerance, "%"); ok {
+		percent, err := strconv.ParseFloat(p, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return 0, fmt.Errorf("invalid failure tolerance '%s', percentage should be in [0, 100]", tolerance)
+		}
+		return int(float64(total) * percent / 100), nil
+	}
+	n, err := strconv.Atoi(tolerance)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid failure tolerance '%s', should be a non-negative number or percentage", tolerance)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartBatches(t *testing.T) {
+	assert := require.New(t)
+
+	topo := &spec.Specification{TiKVServers: []*spec.TiKVSpec{
+		{Host: "172.16.5.1", Port: 20160},
+		{Host: "172.16.5.2", Port: 20160},
+		{Host: "172.16.5.3", Port: 20160},
+	}}
+	insts := (&spec.TiKVComponent{Topology: topo}).Instances()
+
+	assert.Equal([][]string{
+		{"172.16.5.1:20160", "172.16.5.2:20160"},
+		{"172.16.5.3:20160"},
+	}, restartBatches(insts, 2))
+	assert.Equal([][]string{
+		{"172.16.5.1:20160"}, {"172.16.5.2:20160"}, {"172.16.5.3:20160"},
+	}, restartBatches(insts, 1))
+
+	all := [][]string{{"172.16.5.1:20160", "172.16.5.2:20160", "172.16.5.3:20160"}}
+	assert.Equal(all, restartBatches(insts, 0))
+	assert.Equal(all, restartBatches(insts, 5))
+	assert.Empty(restartBatches(nil, 2))
+}
+
+func TestRestartInBatches(t *testing.T) {
+	assert := require.New(t)
+
+	topo := &spec.Specification{TiKVServers: []*spec.TiKVSpec{
+		{Host: "172.16.5.1", Port: 20160},
+		{Host: "172.16.5.2", Port: 20160},
+	}}
+	gated := 0
+	opt := Options{
+		DryRun:       true,
+		RestartBatch: 1,
+		RestartBatchGate: func(ctx context.Context, topo spec.Topology, nodes []string) error {
+			gated++
+			return nil
+		},
+	}
+
+	ctx := ctxt.New(context.Background(), 0, logprinter.NewLogger(""))
+	assert.Nil(restartInBatches(ctx, topo, opt, nil))
+	// the gate is not checked in dry run
+	assert.Equal(0, gated)
+
+	// nothing is restarted once the operation is cancelled
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	err := restartInBatches(cctx, topo, opt, nil)
+	assert.Equal(context.Canceled, errors.Cause(err))
+	assert.Contains(err.Error(), "batch 1/2 of tikv")
+}
+
+func TestParseRestartBatch(t *testing.T) {
+	assert := require.New(t)
+
+	roles := spec.AllComponentNames()
+	sizes, err := ParseRestartBatch(map[string]string{"tikv": "1", "tispark-worker": "2"}, roles)
+	assert.Nil(err)
+	assert.Equal(map[string]int{"tikv": 1, "tispark-worker": 2}, sizes)
+
+	for _, items := range []map[string]string{
+		{"tikv": "0"},
+		{"tikv": "-1"},
+		{"tikv": "a"},
+		{"tispark": "1"},
+	} {
+		_, err = ParseRestartBatch(items, roles)
+		assert.NotNil(err, "%v", items)
+	}
+}
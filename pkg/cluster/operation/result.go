@@ -0,0 +1,88 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+// InstanceResult is the outcome of an instance in a start or stop operation
+type InstanceResult struct {
+	ID        string        `json:"id"`
+	Component string        `json:"component"`
+	Host      string        `json:"host"`
+	Operation string        `json:"operation"` // start or stop
+	Success   bool          `json:"success"`
+	Elapsed   time.Duration `json:"elapsed"`          // the whole time spent on the instance
+	Waited    time.Duration `json:"waited,omitempty"` // the part of it spent waiting for the instance to be ready
+	Error     string        `json:"error,omitempty"`
+}
+
+// OperationResult collects the per-instance outcome of lifecycle operations,
+// set Options.Result to a non-nil value to have it populated. It is filled on
+// both success and failure, so it can be inspected whatever the error is.
+type OperationResult struct {
+	mu        sync.Mutex
+	Instances []InstanceResult `json:"instances"`
+	Warnings  []string         `json:"warnings,omitempty"`
+}
+
+// Failed returns the results of the instances that failed
+func (r *OperationResult) Failed() []InstanceResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failed := []InstanceResult{}
+	for _, ins := range r.Instances {
+		if !ins.Success {
+			failed = append(failed, ins)
+		}
+	}
+	return failed
+}
+
+// record adds the outcome of an instance, it's a no-op on a nil result
+func (r *OperationResult) record(ins spec.Instance, op string, begin time.Time, waited time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	res := InstanceResult{
+		ID:        ins.ID(),
+		Component: ins.ComponentName(),
+		Host:      ins.GetManageHost(),
+		Operation: op,
+		Success:   err == nil,
+		Elapsed:   time.Since(begin),
+		Waited:    waited,
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Instances = append(r.Instances, res)
+}
+
+// warn adds a warning, it's a no-op on a nil result
+func (r *OperationResult) warn(format string, args ...any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+// versionArgs are the args to print the version of the components, the
+// components not listed are not queried as an unknown arg may start them
+var versionArgs = map[string]string{
+	spec.ComponentTiDB:     "-V",
+	spec.ComponentTiKV:     "-V",
+	spec.ComponentPD:       "-V",
+	spec.ComponentPump:     "-V",
+	spec.ComponentDrainer:  "-V",
+	spec.ComponentDMMaster: "-V",
+	spec.ComponentDMWorker: "-V",
+	spec.ComponentCDC:      "version",
+	spec.ComponentTiFlash:  "version",
+}
+
+// releaseVersionRegexp matches the version printed by the components, e.g.
+// `Release Version: v7.1.0`, TiKV prints it without the leading `v`
+var releaseVersionRegexp = regexp.MustCompile(`Release Version:\s*(v?[0-9]+\.[0-9]+\.[0-9]+\S*)`)
+
+// GetRunningVersion returns the version of the binary the running process of
+// the instance is started from, which is queried through /proc/<pid>/exe so
+// it's still the old one if the binary is replaced but the process is not
+// restarted. It's empty if the instance is not running or the version can't
+// be told.
+func GetRunningVersion(ctx context.Context, e ctxt.Executor, ins spec.Instance, systemdMode string) string {
+	args, ok := versionArgs[ins.ComponentName()]
+	if !ok {
+		return ""
+	}
+	systemctl := "systemctl"
+	sudo := true
+	if systemdMode == string(spec.UserMode) {
+		systemctl = "systemctl --user"
+		sudo = false
+	}
+	cmd := fmt.Sprintf(`pid=$(%s show -p MainPID %s | cut -d= -f2) && [ -n "$pid" ] && [ "$pid" != 0 ] && /proc/$pid/exe %s 2>&1`,
+		systemctl, ins.ServiceName(), args)
+	stdout, _, err := e.Execute(ctx, cmd, sudo)
+	if err != nil {
+		return ""
+	}
+	return parseReleaseVersion(string(stdout))
+}
+
+// parseReleaseVersion extracts the version from the output of the components
+func parseReleaseVersion(output string) string {
+	m := releaseVersionRegexp.FindStringSubmatch(output)
+	if m == nil {
+		return ""
+	}
+	if !strings.HasPrefix(m[1], "v") {
+		return "v" + m[1]
+	}
+	return m[1]
+}
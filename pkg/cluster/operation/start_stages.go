@@ -0,0 +1,140 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+// startDependencies are the components that must be started before each known
+// component, the components not in it are always started serially
+var startDependencies = map[string][]string{
+	spec.ComponentPD:           {},
+	spec.ComponentDashboard:    {spec.ComponentPD},
+	spec.ComponentTiProxy:      {spec.ComponentPD},
+	spec.ComponentTiKV:         {spec.ComponentPD},
+	spec.ComponentPump:         {spec.ComponentPD},
+	spec.ComponentTiDB:         {spec.ComponentPD, spec.ComponentTiKV, spec.ComponentPump},
+	spec.ComponentTiFlash:      {spec.ComponentPD, spec.ComponentTiKV},
+	spec.ComponentDrainer:      {spec.ComponentPD, spec.ComponentPump},
+	spec.ComponentCDC:          {spec.ComponentPD, spec.ComponentTiKV},
+	spec.ComponentTiKVCDC:      {spec.ComponentPD, spec.ComponentTiKV},
+	spec.ComponentPrometheus:   {},
+	spec.ComponentGrafana:      {},
+	spec.ComponentAlertmanager: {},
+	spec.RoleTiSparkMaster:     {spec.ComponentPD},
+	spec.RoleTiSparkWorker:     {spec.RoleTiSparkMaster},
+	spec.ComponentDMMaster:     {},
+	spec.ComponentDMWorker:     {spec.ComponentDMMaster},
+}
+
+// roleKey is the key of the component in startDependencies and the parallel
+// groups, i.e. its role as given to --role, the tispark master and workers
+// share the component name but depend on each other
+func roleKey(comp spec.Component) string {
+	if comp.Name() == spec.ComponentTiSpark {
+		return comp.Role()
+	}
+	return comp.Name()
+}
+
+// ParseParallelRoles parses the groups of roles that could be started
+// concurrently, each in the form of `role1,role2`, all the roles must be in
+// validRoles
+func ParseParallelRoles(items []string, validRoles []string) ([][]string, error) {
+	groups := make([][]string, 0, len(items))
+	for _, item := range items {
+		group := strings.Split(item, ",")
+		if err := checkRoles(group, validRoles); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// StartStages groups the components in start order into stages, the components
+// of a stage are started concurrently and the stages one after another. All the
+// components of a parallel group are started at the position of the first one,
+// which is refused if any of them depends on a component it would skip over or
+// on another member of the group. Groups with unknown components are ignored and
+// returned in the second value, so they are started serially.
+func StartStages(components []spec.Component, groups [][]string) ([][]spec.Component, []string, error) {
+	groupOf := make(map[string]int)
+	var ignored []string
+	for i, group := range groups {
+		known := true
+		for _, name := range group {
+			if _, ok := groupOf[name]; ok {
+				return nil, nil, errors.Errorf("role %s is in more than one parallel group", name)
+			}
+			found := false
+			for _, comp := range components {
+				if roleKey(comp) == name {
+					if _, ok := startDependencies[roleKey(comp)]; !ok {
+						known = false
+					}
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+			groupOf[name] = i
+		}
+		if !known {
+			ignored = append(ignored, strings.Join(group, ","))
+			for _, name := range group {
+				delete(groupOf, name)
+			}
+		}
+	}
+
+	var stages [][]spec.Component
+	stageOf := make(map[int]int) // group index to stage index
+	for _, comp := range components {
+		g, ok := groupOf[roleKey(comp)]
+		if !ok {
+			stages = append(stages, []spec.Component{comp})
+			continue
+		}
+		s, ok := stageOf[g]
+		if !ok {
+			stageOf[g] = len(stages)
+			stages = append(stages, []spec.Component{comp})
+			continue
+		}
+
+		// the components started between the stage and this one, and the
+		// members of the group, must not be depended on
+		var skipped []spec.Component
+		skipped = append(skipped, stages[s]...)
+		for _, stage := range stages[s+1:] {
+			skipped = append(skipped, stage...)
+		}
+		for _, dep := range startDependencies[roleKey(comp)] {
+			for _, other := range skipped {
+				if roleKey(other) == dep {
+					return nil, nil, errors.Errorf("%s depends on %s and can not be started in parallel with or before it",
+						roleKey(comp), dep)
+				}
+			}
+		}
+		stages[s] = append(stages[s], comp)
+	}
+	return stages, ignored, nil
+}
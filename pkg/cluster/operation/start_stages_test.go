@@ -0,0 +1,120 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// stageRoles lists the roles of each stage, joined by commas
+func stageRoles(stages [][]spec.Component) []string {
+	res := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		roles := make([]string, 0, len(stage))
+		for _, comp := range stage {
+			roles = append(roles, roleKey(comp))
+		}
+		res = append(res, strings.Join(roles, ","))
+	}
+	return res
+}
+
+func TestStartStages(t *testing.T) {
+	assert := require.New(t)
+
+	comps := (&spec.Specification{}).ComponentsByStartOrder()
+	serial := []string{
+		"pd", "tidb-dashboard", "tiproxy", "tikv", "pump", "tidb", "tiflash", "drainer", "cdc", "tikv-cdc",
+		"prometheus", "grafana", "alertmanager", "tispark-master", "tispark-worker",
+	}
+
+	stages, ignored, err := StartStages(comps, nil)
+	assert.Nil(err)
+	assert.Empty(ignored)
+	assert.Equal(serial, stageRoles(stages))
+
+	// the later members are started at the position of the first one
+	stages, ignored, err = StartStages(comps, [][]string{{"tikv", "tiproxy", "tidb-dashboard"}, {"tiflash", "cdc"}})
+	assert.Nil(err)
+	assert.Empty(ignored)
+	assert.Equal([]string{
+		"pd", "tidb-dashboard,tiproxy,tikv", "pump", "tidb", "tiflash,cdc", "drainer", "tikv-cdc",
+		"prometheus", "grafana", "alertmanager", "tispark-master", "tispark-worker",
+	}, stageRoles(stages))
+
+	// the tispark master and workers are grouped by their roles
+	stages, _, err = StartStages(comps, [][]string{{"grafana", "tispark-master"}})
+	assert.Nil(err)
+	assert.Equal([]string{
+		"pd", "tidb-dashboard", "tiproxy", "tikv", "pump", "tidb", "tiflash", "drainer", "cdc", "tikv-cdc",
+		"prometheus", "grafana,tispark-master", "alertmanager", "tispark-worker",
+	}, stageRoles(stages))
+	_, _, err = StartStages(comps, [][]string{{"tispark-master", "tispark-worker"}})
+	assert.NotNil(err)
+
+	// depending on a member of the group or a component skipped over
+	_, _, err = StartStages(comps, [][]string{{"pd", "tikv"}})
+	assert.NotNil(err)
+	_, _, err = StartStages(comps, [][]string{{"tiproxy", "tidb"}})
+	assert.NotNil(err)
+	_, _, err = StartStages(comps, [][]string{{"tikv"}, {"tikv", "pd"}})
+	assert.NotNil(err)
+
+	// roles not in the components are skipped
+	stages, ignored, err = StartStages(comps, [][]string{{"dm-master", "tikv"}})
+	assert.Nil(err)
+	assert.Empty(ignored)
+	assert.Equal(serial, stageRoles(stages))
+}
+
+func TestParseParallelRoles(t *testing.T) {
+	assert := require.New(t)
+
+	roles := spec.AllComponentNames()
+	groups, err := ParseParallelRoles([]string{"tikv,tiproxy", "tispark-master,grafana"}, roles)
+	assert.Nil(err)
+	assert.Equal([][]string{{"tikv", "tiproxy"}, {"tispark-master", "grafana"}}, groups)
+
+	groups, err = ParseParallelRoles(nil, roles)
+	assert.Nil(err)
+	assert.Empty(groups)
+
+	_, err = ParseParallelRoles([]string{"tikv,tispark"}, roles)
+	assert.NotNil(err)
+	_, err = ParseParallelRoles([]string{"tikv,"}, roles)
+	assert.NotNil(err)
+}
+
+func TestParseDelayStart(t *testing.T) {
+	assert := require.New(t)
+
+	roles := spec.AllComponentNames()
+	delays, err := ParseDelayStart(map[string]string{"tikv": "30s", "tispark-master": "1m"}, roles)
+	assert.Nil(err)
+	assert.Equal(map[string]time.Duration{"tikv": 30 * time.Second, "tispark-master": time.Minute}, delays)
+
+	for _, items := range []map[string]string{
+		{"tikv": "30"},
+		{"tikv": "-1s"},
+		{"tispark": "1s"},
+	} {
+		_, err = ParseDelayStart(items, roles)
+		assert.NotNil(err, "%v", items)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/spec"
+)
+
+// StopOrder returns the components in the order they are stopped. The
+// components named in override, by component name or role, are stopped first
+// in the given order, the rest keep their default order after them and are
+// returned in the second value, so they could be reported as not covered.
+func StopOrder(components []spec.Component, override []string) ([]spec.Component, []string, error) {
+	if len(override) == 0 {
+		return components, nil, nil
+	}
+
+	picked := make(map[int]bool)
+	ordered := make([]spec.Component, 0, len(components))
+	seen := make(map[string]bool)
+	for _, name := range override {
+		if seen[name] {
+			return nil, nil, errors.Errorf("component %s is specified more than once in the stop order", name)
+		}
+		seen[name] = true
+
+		found := false
+		for i, comp := range components {
+			if comp.Name() != name && comp.Role() != name {
+				continue
+			}
+			found = true
+			if !picked[i] {
+				picked[i] = true
+				ordered = append(ordered, comp)
+			}
+		}
+		if !found {
+			return nil, nil, errors.Errorf("component %s in the stop order is not in the cluster or not selected", name)
+		}
+	}
+
+	var rest []string
+	for i, comp := range components {
+		if picked[i] {
+			continue
+		}
+		ordered = append(ordered, comp)
+		rest = append(rest, comp.Role())
+	}
+	return ordered, rest, nil
+}
+
+// ComponentNames returns the roles of components for displaying
+func ComponentNames(components []spec.Component) []string {
+	names := make([]string, 0, len(components))
+	for _, comp := range components {
+		names = append(names, comp.Role())
+	}
+	return names
+}
@@ -96,3 +96,34 @@ func parseSystemctlSince(str string) (dur time.Duration) {
 
 	return time.Since(tm)
 }
+
+// unitEnabledState returns the enablement state of the unit reported by
+// `systemctl is-enabled`, e.g. enabled or disabled, it's empty if the state
+// could not be queried
+func unitEnabledState(ctx context.Context, e ctxt.Executor, unit string, systemdMode string) string {
+	c := module.SystemdModuleConfig{
+		Unit:   unit,
+		Action: "is-enabled",
+		Scope:  systemdMode,
+	}
+	systemd := module.NewSystemdModule(c)
+	// ignore error since a disabled unit returns exit code 1
+	stdout, _, _ := systemd.Execute(ctx, e)
+	state, _, _ := strings.Cut(strings.TrimSpace(string(stdout)), "\n")
+	return state
+}
+
+// daemonReload reloads the systemd manager configuration to pick up the
+// rewritten units and drop-ins
+func daemonReload(ctx context.Context, e ctxt.Executor, systemdMode string) error {
+	c := module.SystemdModuleConfig{
+		Action: "daemon-reload",
+		Scope:  systemdMode,
+	}
+	systemd := module.NewSystemdModule(c)
+	_, stderr, err := systemd.Execute(ctx, e)
+	if err != nil {
+		return errors.Annotatef(err, "failed to reload systemd daemon: %s", stderr)
+	}
+	return nil
+}
@@ -61,7 +61,7 @@ func Upgrade(
 	var cdcOpenAPIClient *api.CDCOpenAPIClient // client for cdc openapi, only used when upgrade cdc
 
 	for _, component := range components {
-		instances := FilterInstance(component.Instances(), nodeFilter)
+		instances := FilterInstanceBy(FilterInstance(component.Instances(), nodeFilter), options.InstanceFilter())
 		if len(instances) < 1 {
 			continue
 		}
@@ -247,6 +247,12 @@ func upgradeInstance(
 		return err
 	}
 
+	if options.BeforeRestartInstance != nil {
+		if err := options.BeforeRestartInstance(ctx, instance); err != nil {
+			return err
+		}
+	}
+
 	if isRollingInstance {
 		err := rollingInstance.PreRestart(ctx, topo, int(options.APITimeout), tlsCfg)
 		if err != nil && !options.Force {
@@ -43,6 +43,8 @@ type AlertmanagerSpec struct {
 	LogDir          string               `yaml:"log_dir,omitempty"`
 	NumaNode        string               `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
 	ResourceControl meta.ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string    `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool                 `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch            string               `yaml:"arch,omitempty"`
 	OS              string               `yaml:"os,omitempty"`
 	ConfigFilePath  string               `yaml:"config_file,omitempty" validate:"config_file:editable"`
@@ -47,6 +47,8 @@ type DrainerSpec struct {
 	NumaNode        string               `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
 	Config          map[string]any       `yaml:"config,omitempty" validate:"config:ignore"`
 	ResourceControl meta.ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string    `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool                 `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch            string               `yaml:"arch,omitempty"`
 	OS              string               `yaml:"os,omitempty"`
 }
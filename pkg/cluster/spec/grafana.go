@@ -44,6 +44,8 @@ type GrafanaSpec struct {
 	DeployDir       string               `yaml:"deploy_dir,omitempty"`
 	Config          map[string]string    `yaml:"config,omitempty" validate:"config:ignore"`
 	ResourceControl meta.ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string    `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool                 `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch            string               `yaml:"arch,omitempty"`
 	OS              string               `yaml:"os,omitempty"`
 	DashboardDir    string               `yaml:"dashboard_dir,omitempty" validate:"dashboard_dir:editable"`
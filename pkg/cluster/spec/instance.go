@@ -90,12 +90,15 @@ type Instance interface {
 	Ready(context.Context, ctxt.Executor, uint64, *tls.Config) error
 	InitConfig(ctx context.Context, e ctxt.Executor, clusterName string, clusterVersion string, deployUser string, paths meta.DirPaths) error
 	ScaleConfig(ctx context.Context, e ctxt.Executor, topo Topology, clusterName string, clusterVersion string, deployUser string, paths meta.DirPaths) error
+	RenderSystemdUnit(ctx context.Context, e ctxt.Executor, opt GlobalOptions, user string, paths meta.DirPaths, tpl string) error
 	PrepareStart(ctx context.Context, tlsCfg *tls.Config) error
 	ComponentName() string
 	ComponentSource() string
 	InstanceName() string
 	ServiceName() string
 	ResourceControl() meta.ResourceControl
+	InstanceLabels() map[string]string
+	DisabledAtBoot() bool
 	GetHost() string
 	GetManageHost() string
 	GetPort() int
@@ -122,8 +125,23 @@ type Instance interface {
 func PortStarted(ctx context.Context, e ctxt.Executor, port int, timeout uint64) error {
 	c := module.WaitForConfig{
 		Port:    port,
-		State:   "started",
+		State:   module.PortStateStarted,
 		Timeout: time.Second * time.Duration(timeout),
+		Phase:   module.PhaseStart,
+	}
+	w := module.NewWaitFor(c)
+	return w.Execute(ctx, e)
+}
+
+// PortResponds wait until a port is being listened and the service on it
+// responds to the handshake
+func PortResponds(ctx context.Context, e ctxt.Executor, port int, timeout uint64, handshake *module.Handshake) error {
+	c := module.WaitForConfig{
+		Port:      port,
+		State:     module.PortStateStarted,
+		Timeout:   time.Second * time.Duration(timeout),
+		Handshake: handshake,
+		Phase:     module.PhaseStart,
 	}
 	w := module.NewWaitFor(c)
 	return w.Execute(ctx, e)
@@ -133,8 +151,9 @@ func PortStarted(ctx context.Context, e ctxt.Executor, port int, timeout uint64)
 func PortStopped(ctx context.Context, e ctxt.Executor, port int, timeout uint64) error {
 	c := module.WaitForConfig{
 		Port:    port,
-		State:   "stopped",
+		State:   module.PortStateStopped,
 		Timeout: time.Second * time.Duration(timeout),
+		Phase:   module.PhaseStop,
 	}
 	w := module.NewWaitFor(c)
 	return w.Execute(ctx, e)
@@ -184,6 +203,26 @@ func (i *BaseInstance) InitConfig(ctx context.Context, e ctxt.Executor, opt Glob
 		return nil
 	}
 
+	if err := i.RenderSystemdUnit(ctx, e, opt, user, paths, ""); err != nil {
+		return err
+	}
+
+	// doesn't work
+	if _, err := i.setTLSConfig(ctx, false, nil, paths); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenderSystemdUnit renders the systemd unit of the instance and installs it
+// on the host, tpl overrides the built-in template if it's not empty
+func (i *BaseInstance) RenderSystemdUnit(ctx context.Context, e ctxt.Executor, opt GlobalOptions, user string, paths meta.DirPaths, tpl string) error {
+	comp := i.ComponentName()
+	host := i.GetHost()
+	port := i.GetPort()
+	sysCfg := filepath.Join(paths.Cache, fmt.Sprintf("%s-%s-%d.service", comp, host, port))
+
 	systemdMode := opt.SystemdMode
 	if len(systemdMode) == 0 {
 		systemdMode = SystemMode
@@ -204,8 +243,18 @@ func (i *BaseInstance) InitConfig(ctx context.Context, e ctxt.Executor, opt Glob
 		systemCfg.Restart = "on-failure"
 	}
 
-	if err := systemCfg.ConfigToFile(sysCfg); err != nil {
-		return errors.Trace(err)
+	if tpl == "" {
+		if err := systemCfg.ConfigToFile(sysCfg); err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		content, err := systemCfg.ConfigWithTemplate(tpl)
+		if err != nil {
+			return errors.Annotatef(err, "render systemd unit of %s", i.ID())
+		}
+		if err := utils.WriteFile(sysCfg, content, 0755); err != nil {
+			return errors.Trace(err)
+		}
 	}
 	tgt := filepath.Join("/tmp", comp+"_"+uuid.New().String()+".service")
 	if err := e.Transfer(ctx, sysCfg, tgt, false, 0, false); err != nil {
@@ -221,12 +270,6 @@ func (i *BaseInstance) InitConfig(ctx context.Context, e ctxt.Executor, opt Glob
 	if _, _, err := e.Execute(ctx, cmd, sudo); err != nil {
 		return errors.Annotatef(err, "execute: %s", cmd)
 	}
-
-	// doesn't work
-	if _, err := i.setTLSConfig(ctx, false, nil, paths); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -385,6 +428,19 @@ func (i *BaseInstance) GetListenHost() string {
 	return i.ListenHost
 }
 
+// localAddr returns the address to connect to a service listening on the
+// listen host from the same host
+func localAddr(listenHost string) string {
+	switch listenHost {
+	case "", "0.0.0.0":
+		return "127.0.0.1"
+	case "::":
+		return "::1"
+	default:
+		return listenHost
+	}
+}
+
 // GetSSHPort implements Instance interface
 func (i *BaseInstance) GetSSHPort() int {
 	return i.SSHP
@@ -517,6 +573,24 @@ func (i *BaseInstance) ResourceControl() meta.ResourceControl {
 	return meta.ResourceControl{}
 }
 
+// InstanceLabels returns the free-form labels of the instance used to select
+// instances in operations
+func (i *BaseInstance) InstanceLabels() map[string]string {
+	if v := reflect.Indirect(reflect.ValueOf(i.InstanceSpec)).FieldByName("InstanceLabels"); v.IsValid() {
+		return v.Interface().(map[string]string)
+	}
+	return nil
+}
+
+// DisabledAtBoot returns whether the instance is intended to be not started
+// at boot, i.e. its unit is kept disabled
+func (i *BaseInstance) DisabledAtBoot() bool {
+	if v := reflect.Indirect(reflect.ValueOf(i.InstanceSpec)).FieldByName("DisabledAtBoot"); v.IsValid() {
+		return v.Bool()
+	}
+	return false
+}
+
 // GetPort implements Instance interface
 func (i *BaseInstance) GetPort() int {
 	return i.Port
@@ -0,0 +1,165 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// MaintenanceWindow is a weekly time range in the local time zone in which
+// disruptive operations are allowed, in the format of `[DAYS] HH:MM-HH:MM`,
+// DAYS is a comma separated list of weekdays or ranges of them and default
+// to every day, e.g. `Sat,Sun 02:00-06:00` or `Mon-Fri 22:00-01:00`. A window
+// ending not later than it starts lasts until the end time of the next day.
+type MaintenanceWindow struct {
+	raw   string
+	days  [7]bool
+	start int // minutes since midnight
+	end   int // minutes since midnight
+}
+
+// ParseMaintenanceWindow parses the maintenance window from string
+func ParseMaintenanceWindow(s string) (*MaintenanceWindow, error) {
+	w := &MaintenanceWindow{raw: strings.TrimSpace(s)}
+	fields := strings.Fields(w.raw)
+	var daySpec, timeSpec string
+	switch len(fields) {
+	case 1:
+		timeSpec = fields[0]
+		for i := range w.days {
+			w.days[i] = true
+		}
+	case 2:
+		daySpec, timeSpec = fields[0], fields[1]
+		if err := w.parseDays(daySpec); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, perrs.Errorf("invalid maintenance window '%s', the format is `[DAYS] HH:MM-HH:MM`", s)
+	}
+
+	start, end, found := strings.Cut(timeSpec, "-")
+	if !found {
+		return nil, perrs.Errorf("invalid time range '%s' of maintenance window, the format is HH:MM-HH:MM", timeSpec)
+	}
+	var err error
+	if w.start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// parseDays parses days like `Mon-Fri,Sun`
+func (w *MaintenanceWindow) parseDays(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := weekdayNames[strings.ToLower(from)]
+		if !ok {
+			return perrs.Errorf("invalid weekday '%s' of maintenance window", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdayNames[strings.ToLower(to)]; !ok {
+				return perrs.Errorf("invalid weekday '%s' of maintenance window", to)
+			}
+		}
+		// ranges like Sat-Mon wrap around the week
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM to minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, perrs.Errorf("invalid time '%s' of maintenance window, the format is HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// String implements fmt.Stringer
+func (w *MaintenanceWindow) String() string {
+	return w.raw
+}
+
+// Contains checks whether the time is in the window
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && m >= w.start && m < w.end
+	}
+	// the window crosses midnight
+	prev := (day + 6) % 7
+	return (w.days[day] && m >= w.start) || (w.days[prev] && m < w.end)
+}
+
+// NextStart returns the start time of the first window after t
+func (w *MaintenanceWindow) NextStart(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i <= 7; i++ {
+		day := midnight.AddDate(0, 0, i)
+		start := day.Add(time.Duration(w.start) * time.Minute)
+		if w.days[day.Weekday()] && start.After(t) {
+			return start
+		}
+	}
+	// unreachable as a valid window has at least one day
+	return time.Time{}
+}
+
+// ParseMaintenanceWindows parses a list of maintenance windows
+func ParseMaintenanceWindows(windows []string) ([]*MaintenanceWindow, error) {
+	res := make([]*MaintenanceWindow, 0, len(windows))
+	for _, s := range windows {
+		w, err := ParseMaintenanceWindow(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, w)
+	}
+	return res, nil
+}
+
+// FormatMaintenanceWindows joins the windows for displaying
+func FormatMaintenanceWindows(windows []*MaintenanceWindow) string {
+	names := make([]string, 0, len(windows))
+	for _, w := range windows {
+		names = append(names, fmt.Sprintf("`%s`", w))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"time"
+
+	"github.com/pingcap/check"
+)
+
+type maintenanceWindowSuite struct{}
+
+var _ = check.Suite(&maintenanceWindowSuite{})
+
+func (s *maintenanceWindowSuite) TestParseMaintenanceWindow(c *check.C) {
+	for _, w := range []string{"02:00-06:00", "Sat,Sun 02:00-06:00", "Mon-Fri 22:00-01:00", "sat-mon 00:00-00:00"} {
+		_, err := ParseMaintenanceWindow(w)
+		c.Assert(err, check.IsNil, check.Commentf(w))
+	}
+	for _, w := range []string{"", "02:00", "Someday 02:00-06:00", "Mon 25:00-26:00", "Mon Tue 02:00-03:00"} {
+		_, err := ParseMaintenanceWindow(w)
+		c.Assert(err, check.NotNil, check.Commentf(w))
+	}
+}
+
+func (s *maintenanceWindowSuite) TestMaintenanceWindowContains(c *check.C) {
+	// 2023-06-02 is a Friday
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2023, 6, day, hour, min, 0, 0, time.Local)
+	}
+
+	w, err := ParseMaintenanceWindow("Sat,Sun 02:00-06:00")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Contains(at(3, 2, 0)), check.IsTrue)
+	c.Assert(w.Contains(at(3, 6, 0)), check.IsFalse)
+	c.Assert(w.Contains(at(2, 3, 0)), check.IsFalse)
+	c.Assert(w.NextStart(at(2, 3, 0)), check.Equals, at(3, 2, 0))
+	c.Assert(w.NextStart(at(4, 3, 0)), check.Equals, at(10, 2, 0))
+
+	// the window crosses midnight and lasts until Saturday morning
+	w, err = ParseMaintenanceWindow("Mon-Fri 22:00-01:00")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Contains(at(2, 23, 0)), check.IsTrue)
+	c.Assert(w.Contains(at(3, 0, 30)), check.IsTrue)
+	c.Assert(w.Contains(at(3, 23, 0)), check.IsFalse)
+	c.Assert(w.Contains(at(5, 0, 30)), check.IsFalse)
+	c.Assert(w.NextStart(at(3, 12, 0)), check.Equals, at(5, 22, 0))
+}
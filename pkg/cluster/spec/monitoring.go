@@ -54,6 +54,8 @@ type PrometheusSpec struct {
 	PushgatewayAddrs      []string               `yaml:"pushgateway_addrs,omitempty" validate:"pushgateway_addrs:ignore"`
 	Retention             string                 `yaml:"storage_retention,omitempty" validate:"storage_retention:editable"`
 	ResourceControl       meta.ResourceControl   `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels        map[string]string      `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot        bool                   `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch                  string                 `yaml:"arch,omitempty"`
 	OS                    string                 `yaml:"os,omitempty"`
 	RuleDir               string                 `yaml:"rule_dir,omitempty" validate:"rule_dir:editable"`
@@ -14,10 +14,12 @@
 package spec
 
 import (
+	"io"
 	"os"
 	"path"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/joomcode/errorx"
 	"github.com/pingcap/tiup/pkg/tui"
@@ -35,16 +37,44 @@ var (
 	ErrTopologyParseFailed = errNSTopolohy.NewType("parse_failed", utils.ErrTraitPreCheck)
 )
 
-// ReadYamlFile read yaml content from file`
+// TopologyFromStdin is the file name to read the topology from stdin
+const TopologyFromStdin = "-"
+
+// stdinTopology keeps the content read from stdin, as the topology file may
+// be read several times in an operation but stdin could only be read once
+var stdinTopology struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// topologySource is the name of the topology file shown to users
+func topologySource(file string) string {
+	if file == TopologyFromStdin {
+		return "stdin"
+	}
+	return file
+}
+
+// ReadYamlFile read yaml content from file`, or stdin if file is `-`
 func ReadYamlFile(file string) ([]byte, error) {
 	suggestionProps := map[string]string{
-		"File": file,
+		"File": topologySource(file),
 	}
 
-	yamlFile, err := os.ReadFile(file)
+	var yamlFile []byte
+	var err error
+	if file == TopologyFromStdin {
+		stdinTopology.once.Do(func() {
+			stdinTopology.data, stdinTopology.err = io.ReadAll(os.Stdin)
+		})
+		yamlFile, err = stdinTopology.data, stdinTopology.err
+	} else {
+		yamlFile, err = os.ReadFile(file)
+	}
 	if err != nil {
 		return nil, ErrTopologyReadFailed.
-			Wrap(err, "Failed to read topology file %s", file).
+			Wrap(err, "Failed to read topology file %s", topologySource(file)).
 			WithProperty(tui.SuggestionFromTemplate(`
 Please check whether your topology file {{ColorKeyword}}{{.File}}{{ColorReset}} exists and try again.
 
@@ -59,10 +89,10 @@ To generate a sample topology file:
 // ignoreGlobal ignore global variables in file, only ignoreGlobal with a index of 0 is effective
 func ParseTopologyYaml(file string, out Topology, ignoreGlobal ...bool) error {
 	suggestionProps := map[string]string{
-		"File": file,
+		"File": topologySource(file),
 	}
 
-	zap.L().Debug("Parse topology file", zap.String("file", file))
+	zap.L().Debug("Parse topology file", zap.String("file", topologySource(file)))
 
 	yamlFile, err := ReadYamlFile(file)
 	if err != nil {
@@ -88,7 +118,7 @@ func ParseTopologyYaml(file string, out Topology, ignoreGlobal ...bool) error {
 
 	if err = yaml.UnmarshalStrict(yamlFile, out); err != nil {
 		return ErrTopologyParseFailed.
-			Wrap(err, "Failed to parse topology file %s", file).
+			Wrap(err, "Failed to parse topology file %s", topologySource(file)).
 			WithProperty(tui.SuggestionFromTemplate(`
 Please check the syntax of your topology file {{ColorKeyword}}{{.File}}{{ColorReset}} and try again.
 `, suggestionProps))
@@ -71,6 +71,22 @@ func (s *topoSuite) TestParseTopologyYamlIgnoreGlobal(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *topoSuite) TestParseTopologyYamlFromStdin(c *check.C) {
+	f, err := os.Open(filepath.Join("testdata", "topology_err.yaml"))
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	// stdin is read only once and shared by the following reads
+	for i := 0; i < 2; i++ {
+		topo := Specification{}
+		c.Assert(ParseTopologyYaml(TopologyFromStdin, &topo), check.IsNil)
+		c.Assert(topo.GlobalOptions.DeployDir, check.Equals, "/tidb/deploy")
+	}
+}
+
 func (s *topoSuite) TestRelativePath(c *check.C) {
 	// test relative path
 	withTempFile(`
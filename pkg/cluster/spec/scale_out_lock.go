@@ -0,0 +1,116 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	perrs "github.com/pingcap/errors"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
+)
+
+// the holder of the scale-out lock is recorded as comments at the head of the
+// lock file, so that it's still a plain topology file
+const (
+	lockHeaderUser          = "# locked-by: "
+	lockHeaderTime          = "# locked-at: "
+	lockHeaderOperation     = "# operation: "
+	lockHeaderCorrelationID = "# correlation-id: "
+)
+
+// ScaleOutLockInfo describes the scale-out lock of a cluster
+type ScaleOutLockInfo struct {
+	User          string    `json:"user,omitempty"` // user@host holding the lock, blank for locks of old versions
+	Time          time.Time `json:"time"`
+	Operation     string    `json:"operation,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Instances     []string  `json:"instances"` // the instances waiting to be started by scale-out --stage2
+}
+
+// scaleOutLockHeader returns the comments recording the current holder of the lock
+func scaleOutLockHeader(operation string, now time.Time) []byte {
+	holder := "unknown"
+	if u, err := user.Current(); err == nil {
+		holder = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		holder += "@" + host
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s%s\n", lockHeaderUser, holder)
+	fmt.Fprintf(buf, "%s%s\n", lockHeaderTime, now.Format(time.RFC3339))
+	fmt.Fprintf(buf, "%s%s\n", lockHeaderOperation, operation)
+	fmt.Fprintf(buf, "%s%s\n", lockHeaderCorrelationID, logprinter.CorrelationID())
+	return buf.Bytes()
+}
+
+// parseScaleOutLockHeader fills the info with the comments at the head of the lock file
+func parseScaleOutLockHeader(data []byte, info *ScaleOutLockInfo) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, lockHeaderUser):
+			info.User = strings.TrimPrefix(line, lockHeaderUser)
+		case strings.HasPrefix(line, lockHeaderTime):
+			if t, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, lockHeaderTime)); err == nil {
+				info.Time = t
+			}
+		case strings.HasPrefix(line, lockHeaderOperation):
+			info.Operation = strings.TrimPrefix(line, lockHeaderOperation)
+		case strings.HasPrefix(line, lockHeaderCorrelationID):
+			info.CorrelationID = strings.TrimPrefix(line, lockHeaderCorrelationID)
+		}
+	}
+}
+
+// ScaleOutLockInfo returns the holder and the pending instances of the
+// scale-out lock of the cluster, nil if the cluster is not locked
+func (s *SpecManager) ScaleOutLockInfo(clusterName string) (*ScaleOutLockInfo, error) {
+	fname := s.Path(clusterName, ScaleOutLockName)
+	stat, err := os.Stat(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, perrs.AddStack(err)
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, perrs.AddStack(err)
+	}
+
+	// the modification time is the best guess for locks without the header
+	info := &ScaleOutLockInfo{Time: stat.ModTime(), Instances: []string{}}
+	parseScaleOutLockHeader(data, info)
+
+	topo, err := s.ScaleOutLock(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	topo.IterInstance(func(inst Instance) {
+		info.Instances = append(info.Instances, inst.ID())
+	})
+	return info, nil
+}
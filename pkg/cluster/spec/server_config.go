@@ -214,6 +214,73 @@ func searchValue(m map[any]any, ss []string) any {
 	return nil
 }
 
+// ParseConfigOverrides parses overrides in the form of `component.key=value`,
+// the key may be a nested one like `tikv.log.level=debug`
+func ParseConfigOverrides(overrides []string) (map[string]map[string]any, error) {
+	result := make(map[string]map[string]any)
+	for _, o := range overrides {
+		kv, raw, found := strings.Cut(o, "=")
+		comp, key, ok := strings.Cut(kv, ".")
+		if !found || !ok || comp == "" || key == "" {
+			return nil, perrs.Errorf("invalid config override '%s', should be in the form of component.key=value", o)
+		}
+
+		// parse the value as YAML to keep the type of numbers and booleans
+		var val any
+		if err := yaml.Unmarshal([]byte(raw), &val); err != nil || val == nil {
+			val = raw
+		}
+		if result[comp] == nil {
+			result[comp] = make(map[string]any)
+		}
+		result[comp][key] = val
+	}
+	return result, nil
+}
+
+// ApplyConfigOverrides sets the overrides to the instance level config of
+// matched components, so they take precedence over all other configs when
+// the config files are rendered, the topology should NOT be saved afterwards
+func ApplyConfigOverrides(topo *Specification, overrides map[string]map[string]any) error {
+	applied := make(map[string]bool)
+	v := reflect.ValueOf(topo).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Slice {
+			continue
+		}
+		for j := 0; j < field.Len(); j++ {
+			ins, ok := field.Index(j).Interface().(InstanceSpec)
+			if !ok {
+				continue
+			}
+			kvs, ok := overrides[ins.Role()]
+			if !ok {
+				continue
+			}
+			cfgField := field.Index(j).Elem().FieldByName("Config")
+			if !cfgField.IsValid() || cfgField.Type() != reflect.TypeOf(map[string]any{}) {
+				continue
+			}
+
+			// flatten the original config to avoid conflicting with nested keys
+			cfg := FlattenMap(cfgField.Interface().(map[string]any))
+			for k, val := range kvs {
+				cfg[k] = val
+			}
+			cfgField.Set(reflect.ValueOf(cfg))
+			applied[ins.Role()] = true
+		}
+	}
+
+	for comp := range overrides {
+		if !applied[comp] {
+			return perrs.Errorf("no instance of component '%s' found for config override", comp)
+		}
+	}
+	return nil
+}
+
 // Merge2Toml merge the config of global.
 func Merge2Toml(comp string, global, overwrite map[string]any) ([]byte, error) {
 	lhs := MergeConfig(global, overwrite)
@@ -289,12 +356,11 @@ func checkConfig(ctx context.Context, e ctxt.Executor, componentName, componentS
 			return nil
 		}
 
-		extra := ""
-		if componentName == ComponentTiKV {
-			// Pass in an empty pd address and the correct data dir
-			extra = fmt.Sprintf(`--pd "" --data-dir "%s"`, paths.Data[0])
+		args := ConfigCheckArgs(componentName, configPath, paths.Data[0])
+		for i, arg := range args {
+			args[i] = fmt.Sprintf("%q", arg)
 		}
-		cmd = fmt.Sprintf("%s --config-check --config=%s %s", binPath, configPath, extra)
+		cmd = fmt.Sprintf("%s %s", binPath, strings.Join(args, " "))
 	}
 
 	_, _, err := e.Execute(ctx, cmd, false)
@@ -304,6 +370,78 @@ func checkConfig(ctx context.Context, e ctxt.Executor, componentName, componentS
 	return nil
 }
 
+// ConfigCheckArgs returns the args of the component binary to check its config
+// file, the binaries are expected to support `--config-check`
+func ConfigCheckArgs(componentName, configPath, dataDir string) []string {
+	args := []string{"--config-check", "--config=" + configPath}
+	if componentName == ComponentTiKV {
+		// Pass in an empty pd address and the correct data dir
+		args = append(args, "--pd", "", "--data-dir", dataDir)
+	}
+	return args
+}
+
+// ServerConfigsOf returns the server_configs of the component, the bool is
+// false for components whose config files are not rendered from them
+func (s *Specification) ServerConfigsOf(componentName string) (map[string]any, bool) {
+	switch componentName {
+	case ComponentTiDB:
+		return s.ServerConfigs.TiDB, true
+	case ComponentTiKV:
+		return s.ServerConfigs.TiKV, true
+	case ComponentPD:
+		return s.ServerConfigs.PD, true
+	case ComponentDashboard:
+		return s.ServerConfigs.Dashboard, true
+	case ComponentTiFlash:
+		return s.ServerConfigs.TiFlash, true
+	case ComponentTiProxy:
+		return s.ServerConfigs.TiProxy, true
+	case ComponentPump:
+		return s.ServerConfigs.Pump, true
+	case ComponentDrainer:
+		return s.ServerConfigs.Drainer, true
+	case ComponentCDC:
+		return s.ServerConfigs.CDC, true
+	case ComponentTiKVCDC:
+		return s.ServerConfigs.TiKVCDC, true
+	}
+	return nil, false
+}
+
+// InstanceConfig is the config file of an instance rendered from the topology
+type InstanceConfig struct {
+	Instance Instance
+	Content  []byte
+}
+
+// RenderInstanceConfigs merges the server_configs and the instance level config
+// of each instance having a TOML config file, the same way as they are merged on
+// deploy, but without the values only known when the instance is initialized
+func RenderInstanceConfigs(topo *Specification) ([]InstanceConfig, error) {
+	result := make([]InstanceConfig, 0)
+	var err error
+	topo.IterInstance(func(inst Instance) {
+		global, ok := topo.ServerConfigsOf(inst.ComponentName())
+		if err != nil || !ok {
+			return
+		}
+		var local map[string]any
+		if v := reflect.ValueOf(inst).Elem().FieldByName("InstanceSpec"); v.IsValid() && !v.IsNil() {
+			if cfg := v.Elem().Elem().FieldByName("Config"); cfg.IsValid() && cfg.Type() == reflect.TypeOf(map[string]any{}) {
+				local = cfg.Interface().(map[string]any)
+			}
+		}
+		var content []byte
+		if content, err = Merge2Toml(inst.ComponentName(), global, local); err != nil {
+			err = perrs.Annotatef(err, "render config of %s", inst.ID())
+			return
+		}
+		result = append(result, InstanceConfig{Instance: inst, Content: content})
+	})
+	return result, err
+}
+
 func hasConfigCheckFlag(ctx context.Context, e ctxt.Executor, binPath string) bool {
 	stdout, stderr, _ := e.Execute(ctx, fmt.Sprintf("%s --help", binPath), false)
 	return strings.Contains(string(stdout), "config-check") || strings.Contains(string(stderr), "config-check")
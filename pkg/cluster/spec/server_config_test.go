@@ -169,3 +169,70 @@ remote_read:
 	c.Assert(err, check.IsNil)
 	c.Assert(bs, check.BytesEquals, yamlData)
 }
+
+func (s *configSuite) TestApplyConfigOverrides(c *check.C) {
+	topo := new(Specification)
+	err := yaml.Unmarshal([]byte(`
+tikv_servers:
+  - host: 172.16.5.138
+    config:
+      log:
+        level: info
+        file.max-days: 3
+  - host: 172.16.5.139
+`), topo)
+	c.Assert(err, check.IsNil)
+
+	overrides, err := ParseConfigOverrides([]string{"tikv.log.level=debug", "tikv.raftstore.capacity=10"})
+	c.Assert(err, check.IsNil)
+	c.Assert(ApplyConfigOverrides(topo, overrides), check.IsNil)
+
+	for _, s := range topo.TiKVServers {
+		c.Assert(GetValueFromPath(s.Config, "log.level"), check.Equals, "debug")
+		c.Assert(GetValueFromPath(s.Config, "raftstore.capacity"), check.Equals, 10)
+	}
+	c.Assert(GetValueFromPath(topo.TiKVServers[0].Config, "log.file.max-days"), check.Equals, 3)
+
+	_, err = ParseConfigOverrides([]string{"tikv=debug"})
+	c.Assert(err, check.NotNil)
+
+	overrides, err = ParseConfigOverrides([]string{"tidb.log.level=debug"})
+	c.Assert(err, check.IsNil)
+	c.Assert(ApplyConfigOverrides(topo, overrides), check.NotNil)
+}
+
+func (s *configSuite) TestRenderInstanceConfigs(c *check.C) {
+	topo := new(Specification)
+	err := yaml.Unmarshal([]byte(`
+server_configs:
+  tikv:
+    log.level: warn
+    storage.reserve-space: 1GB
+tikv_servers:
+  - host: 172.16.5.138
+    config:
+      log.level: info
+pd_servers:
+  - host: 172.16.5.139
+monitoring_servers:
+  - host: 172.16.5.139
+`), topo)
+	c.Assert(err, check.IsNil)
+
+	configs, err := RenderInstanceConfigs(topo)
+	c.Assert(err, check.IsNil)
+	c.Assert(configs, check.HasLen, 2)
+	for _, cfg := range configs {
+		switch cfg.Instance.ComponentName() {
+		case ComponentTiKV:
+			c.Assert(bytes.Contains(cfg.Content, []byte(`level = "info"`)), check.IsTrue)
+			c.Assert(bytes.Contains(cfg.Content, []byte(`reserve-space = "1GB"`)), check.IsTrue)
+		case ComponentPD:
+		default:
+			c.Fatalf("unexpected component %s", cfg.Instance.ComponentName())
+		}
+	}
+
+	args := ConfigCheckArgs(ComponentTiKV, "/tmp/tikv.toml", "/data")
+	c.Assert(args, check.DeepEquals, []string{"--config-check", "--config=/tmp/tikv.toml", "--pd", "", "--data-dir", "/data"})
+}
@@ -101,6 +101,23 @@ type (
 		Arch            string               `yaml:"arch,omitempty"`
 		Custom          any                  `yaml:"custom,omitempty" validate:"custom:ignore"`
 		SystemdMode     SystemdMode          `yaml:"systemd_mode,omitempty" default:"system"`
+		// lifecycle hooks of components, the key is the component name
+		Hooks map[string]ComponentHooks `yaml:"hooks,omitempty" validate:"hooks:ignore"`
+	}
+
+	// LifecycleHook is a command executed with the deploy user on the host of
+	// each instance during lifecycle operations
+	LifecycleHook struct {
+		Command    string `yaml:"command"`
+		BestEffort bool   `yaml:"best_effort,omitempty"` // do not fail the operation if the hook fails
+	}
+
+	// ComponentHooks represents the lifecycle hooks of a component
+	ComponentHooks struct {
+		PreStart  *LifecycleHook `yaml:"pre_start,omitempty"`
+		PostStart *LifecycleHook `yaml:"post_start,omitempty"`
+		PreStop   *LifecycleHook `yaml:"pre_stop,omitempty"`
+		PostStop  *LifecycleHook `yaml:"post_stop,omitempty"`
 	}
 
 	// MonitoredOptions represents the monitored node configuration
@@ -190,6 +207,29 @@ type (
 	}
 )
 
+// stages of lifecycle hooks
+const (
+	HookPreStart  = "pre_start"
+	HookPostStart = "post_start"
+	HookPreStop   = "pre_stop"
+	HookPostStop  = "post_stop"
+)
+
+// Get returns the hook of the stage, nil if it's not defined
+func (h ComponentHooks) Get(stage string) *LifecycleHook {
+	switch stage {
+	case HookPreStart:
+		return h.PreStart
+	case HookPostStart:
+		return h.PostStart
+	case HookPreStop:
+		return h.PreStop
+	case HookPostStop:
+		return h.PostStop
+	}
+	return nil
+}
+
 // BaseTopo is the base info to topology.
 type BaseTopo struct {
 	GlobalOptions    *GlobalOptions
@@ -234,6 +274,16 @@ type BaseMeta struct {
 	Group   string
 	Version string
 	OpsVer  *string `yaml:"last_ops_ver,omitempty"` // the version of ourself that updated the meta last time
+	Banner  string  // the environment banner displayed before destructive operations
+	// destructive operations are refused on a protected cluster, nil if the
+	// metadata does not support it
+	Protected *bool
+	// disruptive operations are only allowed in the windows, nil if the
+	// metadata does not support it
+	MaintenanceWindows *[]string
+	// checksums of the binaries of the instances by ID recorded when deployed,
+	// nil if the metadata does not support it
+	BinaryChecksums *map[string]string
 }
 
 // Metadata of a cluster.
@@ -17,6 +17,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/joomcode/errorx"
 	perrs "github.com/pingcap/errors"
@@ -46,6 +47,8 @@ const (
 	BackupDirName = "backup"
 	// ScaleOutLockName scale_out snapshot file, like file lock
 	ScaleOutLockName = ".scale-out.yaml"
+	// LastResultName is the file recording the per-instance outcome of the last start, stop or restart
+	LastResultName = ".last-result.json"
 )
 
 //revive:disable
@@ -229,7 +232,7 @@ func (s *SpecManager) ScaleOutLock(clusterName string) (Topology, error) {
 func (s *SpecManager) ScaleOutLockedErr(clusterName string) error {
 	if locked, err := s.IsScaleOutLocked(clusterName); locked {
 		return errNS.NewType("scale-out lock").Wrap(err, "Scale-out file lock already exists").
-			WithProperty(tui.SuggestionFromString("Please run 'tiup-cluster scale-out --stage2' to continue."))
+			WithProperty(tui.SuggestionFromFormat("Please run 'tiup-cluster scale-out %[1]s --stage2' to continue, or run 'tiup-cluster scale-out-lock show %[1]s' to inspect the lock and 'tiup-cluster scale-out-lock release %[1]s' to release it if the scale-out was aborted.", clusterName))
 	}
 	return nil
 }
@@ -249,8 +252,9 @@ func (s *SpecManager) IsScaleOutLocked(clusterName string) (locked bool, err err
 	return true, nil
 }
 
-// NewScaleOutLock save the meta with specified cluster name.
-func (s *SpecManager) NewScaleOutLock(clusterName string, topo Topology) error {
+// NewScaleOutLock save the meta with specified cluster name, now is the time
+// recorded as when the lock is taken.
+func (s *SpecManager) NewScaleOutLock(clusterName string, topo Topology, now time.Time) error {
 	wrapError := func(err error) *errorx.Error {
 		return ErrSaveScaleOutFileFailed.Wrap(err, "Failed to create scale-out file lock")
 	}
@@ -271,6 +275,7 @@ func (s *SpecManager) NewScaleOutLock(clusterName string, topo Topology) error {
 		return wrapError(err)
 	}
 
+	data = append(scaleOutLockHeader("scale-out --stage1", now), data...)
 	err = utils.WriteFile(lockFile, data, 0644)
 	if err != nil {
 		return wrapError(err)
@@ -279,7 +284,8 @@ func (s *SpecManager) NewScaleOutLock(clusterName string, topo Topology) error {
 	return nil
 }
 
-// ReleaseScaleOutLock remove the scale-out file lock with specified cluster
+// ReleaseScaleOutLock remove the scale-out file lock with specified cluster,
+// it's also used to force-release a lock left by a crashed scale-out
 func (s *SpecManager) ReleaseScaleOutLock(clusterName string) error {
 	return os.Remove(s.Path(clusterName, ScaleOutLockName))
 }
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -200,3 +201,46 @@ func TestSpec(t *testing.T) {
 	err = spec.Remove("name1")
 	assert.Nil(t, err)
 }
+
+func TestScaleOutLockInfo(t *testing.T) {
+	dir := t.TempDir()
+	spec := NewSpec(dir, func() Metadata {
+		return new(TestMetadata)
+	})
+
+	info, err := spec.ScaleOutLockInfo("name1")
+	assert.Nil(t, err)
+	assert.Nil(t, info)
+
+	topo := &Specification{
+		TiDBServers: []*TiDBSpec{{Host: "172.16.5.1", Port: 4000}},
+	}
+	lockedAt := time.Date(2023, 6, 3, 3, 0, 0, 0, time.UTC)
+	err = spec.NewScaleOutLock("name1", topo, lockedAt)
+	assert.Nil(t, err)
+
+	info, err = spec.ScaleOutLockInfo("name1")
+	assert.Nil(t, err)
+	assert.NotNil(t, info)
+	assert.NotEmpty(t, info.User)
+	assert.Equal(t, "scale-out --stage1", info.Operation)
+	assert.NotEmpty(t, info.CorrelationID)
+	assert.True(t, lockedAt.Equal(info.Time))
+	assert.Equal(t, []string{"172.16.5.1:4000"}, info.Instances)
+
+	// locks created by old versions have no header
+	lockFile := spec.Path("name1", ScaleOutLockName)
+	err = os.WriteFile(lockFile, []byte("tidb_servers:\n- host: 172.16.5.2\n  port: 4000\n"), 0644)
+	assert.Nil(t, err)
+	info, err = spec.ScaleOutLockInfo("name1")
+	assert.Nil(t, err)
+	assert.Empty(t, info.User)
+	assert.Empty(t, info.Operation)
+	assert.Equal(t, []string{"172.16.5.2:4000"}, info.Instances)
+
+	err = spec.ReleaseScaleOutLock("name1")
+	assert.Nil(t, err)
+	info, err = spec.ScaleOutLockInfo("name1")
+	assert.Nil(t, err)
+	assert.Nil(t, info)
+}
@@ -939,3 +939,28 @@ tidb_servers:
 	c.Assert(err, NotNil)
 	c.Assert(strings.Contains(err.Error(), "not found"), IsTrue)
 }
+
+func (s *metaSuiteTopo) TestTiDBProxyProtocolEnabled(c *C) {
+	topo := Specification{}
+	err := yaml.Unmarshal([]byte(`
+server_configs:
+  tidb:
+    proxy-protocol.networks: "*"
+tidb_servers:
+  - host: 172.16.5.138
+  - host: 172.16.5.139
+    config:
+      proxy-protocol:
+        networks: ""
+`), &topo)
+	c.Assert(err, IsNil)
+
+	insts := (&TiDBComponent{&topo}).Instances()
+	c.Assert(insts, HasLen, 2)
+	c.Assert(insts[0].(*TiDBInstance).proxyProtocolEnabled(), IsTrue)
+	c.Assert(insts[1].(*TiDBInstance).proxyProtocolEnabled(), IsFalse)
+
+	topo.ServerConfigs.TiDB = nil
+	insts = (&TiDBComponent{&topo}).Instances()
+	c.Assert(insts[0].(*TiDBInstance).proxyProtocolEnabled(), IsFalse)
+}
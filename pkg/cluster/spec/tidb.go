@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/cluster/module"
 	"github.com/pingcap/tiup/pkg/cluster/template/scripts"
 	"github.com/pingcap/tiup/pkg/meta"
 	"github.com/pingcap/tiup/pkg/tidbver"
@@ -48,6 +49,8 @@ type TiDBSpec struct {
 	NumaCores       string               `yaml:"numa_cores,omitempty" validate:"numa_cores:editable"`
 	Config          map[string]any       `yaml:"config,omitempty" validate:"config:ignore"`
 	ResourceControl meta.ResourceControl `yaml:"resource_control,omitempty" validate:"resource_control:editable"`
+	InstanceLabels  map[string]string    `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot  bool                 `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch            string               `yaml:"arch,omitempty"`
 	OS              string               `yaml:"os,omitempty"`
 }
@@ -167,6 +170,28 @@ type TiDBInstance struct {
 	topo Topology
 }
 
+// Ready implements Instance interface, TiDB is ready once it sends the MySQL
+// greeting, which tells it's able to serve rather than just listening. The
+// greeting is not sent to clients without a PROXY header if proxy protocol is
+// enabled, so only the port is waited for then.
+func (i *TiDBInstance) Ready(ctx context.Context, e ctxt.Executor, timeout uint64, _ *tls.Config) error {
+	if i.proxyProtocolEnabled() {
+		return PortStarted(ctx, e, i.Port, timeout)
+	}
+	return PortResponds(ctx, e, i.Port, timeout, module.MySQLHandshake(localAddr(i.GetListenHost())))
+}
+
+// proxyProtocolEnabled tells whether proxy-protocol.networks is set in the
+// config of the instance, or in the global config if the instance doesn't set it
+func (i *TiDBInstance) proxyProtocolEnabled() bool {
+	const key = "proxy-protocol.networks"
+	v := GetValueFromPath(i.InstanceSpec.(*TiDBSpec).Config, key)
+	if topo, ok := i.topo.(*Specification); ok && v == nil {
+		v = GetValueFromPath(topo.ServerConfigs.TiDB, key)
+	}
+	return v != nil && fmt.Sprint(v) != ""
+}
+
 // InitConfig implement Instance interface
 func (i *TiDBInstance) InitConfig(
 	ctx context.Context,
@@ -71,16 +71,18 @@ func proxyUptimeByHost(host string, port int, timeout time.Duration, tlsCfg *tls
 
 // TiProxySpec represents the TiProxy topology specification in topology.yaml
 type TiProxySpec struct {
-	Host       string         `yaml:"host"`
-	ManageHost string         `yaml:"manage_host,omitempty" validate:"manage_host:editable"`
-	SSHPort    int            `yaml:"ssh_port,omitempty" validate:"ssh_port:editable"`
-	Port       int            `yaml:"port" default:"6000"`
-	StatusPort int            `yaml:"status_port" default:"3080"`
-	DeployDir  string         `yaml:"deploy_dir,omitempty"`
-	NumaNode   string         `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
-	Config     map[string]any `yaml:"config,omitempty" validate:"config:ignore"`
-	Arch       string         `yaml:"arch,omitempty"`
-	OS         string         `yaml:"os,omitempty"`
+	Host           string            `yaml:"host"`
+	ManageHost     string            `yaml:"manage_host,omitempty" validate:"manage_host:editable"`
+	SSHPort        int               `yaml:"ssh_port,omitempty" validate:"ssh_port:editable"`
+	Port           int               `yaml:"port" default:"6000"`
+	StatusPort     int               `yaml:"status_port" default:"3080"`
+	DeployDir      string            `yaml:"deploy_dir,omitempty"`
+	NumaNode       string            `yaml:"numa_node,omitempty" validate:"numa_node:editable"`
+	Config         map[string]any    `yaml:"config,omitempty" validate:"config:ignore"`
+	InstanceLabels map[string]string `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot bool              `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
+	Arch           string            `yaml:"arch,omitempty"`
+	OS             string            `yaml:"os,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -49,6 +49,8 @@ type TiSparkMasterSpec struct {
 	JavaHome       string            `yaml:"java_home,omitempty" validate:"java_home:editable"`
 	SparkConfigs   map[string]any    `yaml:"spark_config,omitempty" validate:"spark_config:ignore"`
 	SparkEnvs      map[string]string `yaml:"spark_env,omitempty" validate:"spark_env:ignore"`
+	InstanceLabels map[string]string `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot bool              `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
 	Arch           string            `yaml:"arch,omitempty"`
 	OS             string            `yaml:"os,omitempty"`
 }
@@ -84,19 +86,21 @@ func (s *TiSparkMasterSpec) IgnoreMonitorAgent() bool {
 
 // TiSparkWorkerSpec is the topology specification for TiSpark slave nodes
 type TiSparkWorkerSpec struct {
-	Host           string `yaml:"host"`
-	ManageHost     string `yaml:"manage_host,omitempty"`
-	ListenHost     string `yaml:"listen_host,omitempty"`
-	SSHPort        int    `yaml:"ssh_port,omitempty" validate:"ssh_port:editable"`
-	Imported       bool   `yaml:"imported,omitempty"`
-	Patched        bool   `yaml:"patched,omitempty"`
-	IgnoreExporter bool   `yaml:"ignore_exporter,omitempty"`
-	Port           int    `yaml:"port" default:"7078"`
-	WebPort        int    `yaml:"web_port" default:"8081"`
-	DeployDir      string `yaml:"deploy_dir,omitempty"`
-	JavaHome       string `yaml:"java_home,omitempty" validate:"java_home:editable"`
-	Arch           string `yaml:"arch,omitempty"`
-	OS             string `yaml:"os,omitempty"`
+	Host           string            `yaml:"host"`
+	ManageHost     string            `yaml:"manage_host,omitempty"`
+	ListenHost     string            `yaml:"listen_host,omitempty"`
+	SSHPort        int               `yaml:"ssh_port,omitempty" validate:"ssh_port:editable"`
+	Imported       bool              `yaml:"imported,omitempty"`
+	Patched        bool              `yaml:"patched,omitempty"`
+	IgnoreExporter bool              `yaml:"ignore_exporter,omitempty"`
+	Port           int               `yaml:"port" default:"7078"`
+	WebPort        int               `yaml:"web_port" default:"8081"`
+	DeployDir      string            `yaml:"deploy_dir,omitempty"`
+	JavaHome       string            `yaml:"java_home,omitempty" validate:"java_home:editable"`
+	InstanceLabels map[string]string `yaml:"instance_labels,omitempty" validate:"instance_labels:editable"`
+	DisabledAtBoot bool              `yaml:"disabled_at_boot,omitempty" validate:"disabled_at_boot:editable"`
+	Arch           string            `yaml:"arch,omitempty"`
+	OS             string            `yaml:"os,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -18,11 +18,13 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/pingcap/tiup/pkg/localdata"
 	"github.com/pingcap/tiup/pkg/utils"
 	"github.com/pingcap/tiup/pkg/version"
 	"github.com/prometheus/common/expfmt"
@@ -45,6 +47,14 @@ type ClusterMeta struct {
 	Version string `yaml:"tidb_version"` // the version of TiDB cluster
 	// EnableFirewall bool   `yaml:"firewall"`
 	OpsVer string `yaml:"last_ops_ver,omitempty"` // the version of ourself that updated the meta last time
+	// the banner displayed before destructive operations, e.g. "PRODUCTION - change ticket required"
+	EnvironmentBanner string `yaml:"environment_banner,omitempty"`
+	// stop, restart, clean and destroy are refused on a protected cluster
+	Protected bool `yaml:"protected,omitempty"`
+	// stop and restart are refused outside the maintenance windows if any
+	MaintenanceWindows []string `yaml:"maintenance_windows,omitempty"`
+	// checksums of the bin dirs of the instances by ID, verified before starting if asked
+	BinaryChecksums map[string]string `yaml:"binary_checksums,omitempty"`
 
 	Topology *Specification `yaml:"topology"`
 }
@@ -79,14 +89,34 @@ func (m *ClusterMeta) SetTopology(topo Topology) {
 // GetBaseMeta implements Metadata interface.
 func (m *ClusterMeta) GetBaseMeta() *BaseMeta {
 	return &BaseMeta{
-		Version: m.Version,
-		User:    m.User,
-		OpsVer:  &m.OpsVer,
+		Version:   m.Version,
+		User:      m.User,
+		OpsVer:    &m.OpsVer,
+		Banner:    m.EnvironmentBanner,
+		Protected: &m.Protected,
+
+		MaintenanceWindows: &m.MaintenanceWindows,
+		BinaryChecksums:    &m.BinaryChecksums,
 	}
 }
 
-// AuditDir return the directory for saving audit log.
+// auditDirOverride is the audit directory set for this invocation, empty means unset
+var auditDirOverride string
+
+// SetAuditDir overrides the directory for saving audit log, empty string restores the default.
+func SetAuditDir(dir string) {
+	auditDirOverride = dir
+}
+
+// AuditDir return the directory for saving audit log, it could be overridden by
+// SetAuditDir or the TIUP_AUDIT_DIR environment variable in that order.
 func AuditDir() string {
+	if auditDirOverride != "" {
+		return auditDirOverride
+	}
+	if dir := os.Getenv(localdata.EnvNameAuditDir); dir != "" {
+		return dir
+	}
 	return filepath.Join(profileDir, TiUPAuditDir)
 }
 
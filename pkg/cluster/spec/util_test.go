@@ -14,7 +14,11 @@
 package spec
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/localdata"
 )
 
 type utilSuite struct{}
@@ -61,3 +65,15 @@ func (s *utilSuite) TestMultiDirAbs(c *check.C) {
 	c.Assert(paths[0], check.Equals, "/home/tidb/a")
 	c.Assert(paths[1], check.Equals, "/tmp/b")
 }
+
+func (s *utilSuite) TestAuditDirOverride(c *check.C) {
+	defer SetAuditDir("")
+	c.Assert(AuditDir(), check.Equals, filepath.Join(profileDir, TiUPAuditDir))
+
+	os.Setenv(localdata.EnvNameAuditDir, "/tmp/audit-env")
+	defer os.Unsetenv(localdata.EnvNameAuditDir)
+	c.Assert(AuditDir(), check.Equals, "/tmp/audit-env")
+
+	SetAuditDir("/tmp/audit-flag")
+	c.Assert(AuditDir(), check.Equals, "/tmp/audit-flag")
+}
@@ -38,7 +38,12 @@ func (c *CopyFile) Execute(ctx context.Context) error {
 		return ErrNoExecutor
 	}
 
-	err := e.Transfer(ctx, c.src, c.dst, c.download, c.limit, c.compress)
+	var err error
+	if c.download {
+		err = e.Transfer(ctx, c.src, c.dst, c.download, c.limit, c.compress)
+	} else {
+		err = uploadFile(ctx, e, c.src, c.dst, c.limit, c.compress)
+	}
 	if err != nil {
 		return errors.Annotate(err, "failed to transfer file")
 	}
@@ -0,0 +1,138 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/ctxt"
+	"github.com/pingcap/tiup/pkg/set"
+)
+
+var (
+	// files larger than this are uploaded in chunks that survive a dropped connection
+	resumableThreshold int64 = 64 << 20
+	// size of each chunk of a resumable upload
+	resumableChunkSize int64 = 32 << 20
+	// times to try uploading a chunk before giving up
+	resumableChunkAttempts = 3
+)
+
+// uploadFile copies a local file to the remote host, large files are uploaded
+// with uploadResumable so a retry does not start over from the first byte
+func uploadFile(ctx context.Context, e ctxt.Executor, src, dst string, limit int, compress bool) error {
+	fi, err := os.Stat(src)
+	if err != nil || fi.IsDir() || fi.Size() <= resumableThreshold {
+		return e.Transfer(ctx, src, dst, false, limit, compress)
+	}
+	return uploadResumable(ctx, e, src, dst, fi.Size(), limit, compress)
+}
+
+// uploadResumable uploads the file chunk by chunk into a parts dir next to dst, a chunk is
+// renamed to its final name only after it is fully transferred, so the chunks already on
+// the remote host are skipped when the upload is retried. The parts dir is named after
+// the checksum of the file to never mix chunks of different contents.
+func uploadResumable(ctx context.Context, e ctxt.Executor, src, dst string, size int64, limit int, compress bool) error {
+	sum, err := fileSHA256(src)
+	if err != nil {
+		return err
+	}
+	partsDir := fmt.Sprintf("%s.parts-%s", dst, sum[:12])
+
+	stdout, stderr, err := e.Execute(ctx, fmt.Sprintf("mkdir -p %s && ls %s", partsDir, partsDir), false)
+	if err != nil {
+		return errors.Annotatef(err, "failed to prepare %s, stderr: %s", partsDir, string(stderr))
+	}
+	done := set.NewStringSet(strings.Fields(string(stdout))...)
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for idx, offset := 0, int64(0); offset < size; idx, offset = idx+1, offset+resumableChunkSize {
+		part := fmt.Sprintf("part.%06d", idx)
+		if done.Exist(part) {
+			continue
+		}
+		length := min(resumableChunkSize, size-offset)
+		if err := uploadChunk(ctx, e, io.NewSectionReader(f, offset, length), partsDir+"/"+part, limit, compress); err != nil {
+			return errors.Annotatef(err, "failed to upload chunk %d of %s, the uploaded chunks are kept for retrying", idx, src)
+		}
+	}
+
+	// the zero padded names keep the glob in order
+	cmd := fmt.Sprintf("cat %[1]s/part.* > %[2]s.tmp && mv %[2]s.tmp %[2]s && sha256sum %[2]s", partsDir, dst)
+	stdout, stderr, err = e.Execute(ctx, cmd, false)
+	if err != nil {
+		return errors.Annotatef(err, "failed to assemble %s, stderr: %s", dst, string(stderr))
+	}
+	if fields := strings.Fields(string(stdout)); len(fields) == 0 || fields[0] != sum {
+		_, _, _ = e.Execute(ctx, fmt.Sprintf("rm -rf %s %s", partsDir, dst), false)
+		return errors.Errorf("checksum mismatch of %s after upload, expect %s but got %s", dst, sum, strings.TrimSpace(string(stdout)))
+	}
+	_, _, _ = e.Execute(ctx, fmt.Sprintf("rm -rf %s", partsDir), false)
+	return nil
+}
+
+// uploadChunk writes the chunk to a local temp file and uploads it as dst
+func uploadChunk(ctx context.Context, e ctxt.Executor, r io.Reader, dst string, limit int, compress bool) error {
+	tmp, err := os.CreateTemp("", "tiup-chunk-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = e.Transfer(ctx, tmp.Name(), dst+".tmp", false, limit, compress)
+		if err == nil {
+			_, stderr, err := e.Execute(ctx, fmt.Sprintf("mv %[1]s.tmp %[1]s", dst), false)
+			if err != nil {
+				return errors.Annotatef(err, "stderr: %s", string(stderr))
+			}
+			return nil
+		}
+		if attempt >= resumableChunkAttempts || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
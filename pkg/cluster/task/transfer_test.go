@@ -0,0 +1,93 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+type transferSuite struct{}
+
+var _ = check.Suite(&transferSuite{})
+
+// localExecutor runs the commands with the local shell and fails the transfers
+// whose destination contains failOn
+type localExecutor struct {
+	failOn    string
+	transfers []string
+}
+
+func (e *localExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err := c.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+func (e *localExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	e.transfers = append(e.transfers, filepath.Base(dst))
+	if e.failOn != "" && strings.Contains(dst, e.failOn) {
+		return errors.New("connection lost")
+	}
+	return utils.Copy(src, dst)
+}
+
+func (s *transferSuite) TestUploadResumable(c *check.C) {
+	origThreshold, origChunk := resumableThreshold, resumableChunkSize
+	resumableThreshold, resumableChunkSize = 8, 4
+	defer func() { resumableThreshold, resumableChunkSize = origThreshold, origChunk }()
+
+	dir := c.MkDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	c.Assert(os.WriteFile(src, []byte("0123456789abcdefXY"), 0644), check.IsNil)
+
+	// the third chunk can not be uploaded
+	e := &localExecutor{failOn: "part.000002"}
+	c.Assert(uploadFile(context.Background(), e, src, dst, 0, false), check.NotNil)
+	c.Assert(utils.IsNotExist(dst), check.IsTrue)
+
+	// the retry only uploads the rest chunks
+	e.failOn = ""
+	e.transfers = nil
+	c.Assert(uploadFile(context.Background(), e, src, dst, 0, false), check.IsNil)
+	c.Assert(e.transfers, check.DeepEquals, []string{"part.000002.tmp", "part.000003.tmp", "part.000004.tmp"})
+
+	data, err := os.ReadFile(dst)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "0123456789abcdefXY")
+	matches, _ := filepath.Glob(dst + ".parts-*")
+	c.Assert(matches, check.HasLen, 0)
+}
+
+func (s *transferSuite) TestUploadSmallFile(c *check.C) {
+	dir := c.MkDir()
+	src := filepath.Join(dir, "src")
+	c.Assert(os.WriteFile(src, []byte("small"), 0644), check.IsNil)
+
+	e := &localExecutor{}
+	c.Assert(uploadFile(context.Background(), e, src, filepath.Join(dir, "dst"), 0, false), check.IsNil)
+	c.Assert(e.transfers, check.DeepEquals, []string{"dst"})
+}
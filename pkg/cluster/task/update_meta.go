@@ -180,6 +180,16 @@ func (u *UpdateMeta) Execute(ctx context.Context) error {
 	}
 	newMeta.Topology.Alertmanagers = alertmanagers
 
+	// the binary checksums of the removed instances are dropped as well
+	if u.metadata.BinaryChecksums != nil {
+		newMeta.BinaryChecksums = make(map[string]string, len(u.metadata.BinaryChecksums))
+		for id, sum := range u.metadata.BinaryChecksums {
+			if !deleted.Exist(id) {
+				newMeta.BinaryChecksums[id] = sum
+			}
+		}
+	}
+
 	return spec.SaveClusterMeta(u.cluster, newMeta)
 }
 
@@ -73,6 +73,21 @@ type Environment struct {
 	// repo represents the components repository of TiUP, it can be a
 	// local file system or a HTTP URL
 	v1Repo *repository.V1Repository
+	// clock tells the current time, the system time is used if it's nil
+	clock utils.Clock
+}
+
+// SetClock sets the clock used by time based logic of the environment
+func (env *Environment) SetClock(c utils.Clock) {
+	env.clock = c
+}
+
+// Now returns the current time of the environment's clock
+func (env *Environment) Now() time.Time {
+	if env.clock == nil {
+		return utils.RealClock.Now()
+	}
+	return env.clock.Now()
 }
 
 // InitEnv creates a new Environment object configured using env vars and defaults.
@@ -104,7 +119,7 @@ func InitEnv(options repository.Options, mOpt repository.MirrorOptions) (*Enviro
 
 	zap.L().Debug("Initialize repository finished", zap.Duration("duration", time.Since(initRepo)))
 
-	return &Environment{profile, v1repo}, nil
+	return &Environment{profile: profile, v1Repo: v1repo}, nil
 }
 
 // V1Repository returns the initialized v1 repository
@@ -20,13 +20,18 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/user"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
 	"github.com/pingcap/tiup/pkg/tui"
 	"github.com/pingcap/tiup/pkg/utils"
 	"github.com/pkg/errors"
@@ -34,28 +39,119 @@ import (
 
 const (
 	// HistoryDir history save path
-	HistoryDir          = "history"
-	historyPrefix       = "tiup-history-"
-	historySize   int64 = 1024 * 64 //  history file default size is 64k
+	HistoryDir    = "history"
+	historyPrefix = "tiup-history-"
+	redactedValue = "******"
 )
 
+// historySize is the size a history file is rotated at, records are appended
+// to tiup-history-0 until it reaches the size, then to tiup-history-1 and so on
+var historySize int64 = 1024 * 64 //  history file default size is 64k
+
+// HistoryStderrSize is the max size of the stderr tail kept for a failed
+// command, the earlier output is dropped
+const HistoryStderrSize = 512
+
+// ansiEscapeRegexp matches the color codes in the output of commands
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// historySensitiveFlags are the flags whose values will be masked before
+// saving to history files, use RegisterHistorySensitiveFlags to extend it.
+// The value tells if the flag takes a value, for those not, e.g. the boolean
+// --password of cluster deploy, only the `--flag=value` form is masked
+var historySensitiveFlags = map[string]bool{
+	"--password":    false,
+	"--passwd":      false,
+	"--db-password": true,
+	"--token":       true,
+	"--secret":      true,
+}
+
+var historySensitiveFlagsMu sync.RWMutex
+
+// RegisterHistorySensitiveFlags adds flags taking a value that should be masked in history
+func RegisterHistorySensitiveFlags(flags ...string) {
+	historySensitiveFlagsMu.Lock()
+	defer historySensitiveFlagsMu.Unlock()
+
+	for _, f := range flags {
+		historySensitiveFlags[f] = true
+	}
+}
+
+// redactCommand masks the values of sensitive flags in command, the
+// `--flag=value` form is always masked, and the `--flag value` form is
+// masked only if the flag is known to take a value
+func redactCommand(command []string) []string {
+	historySensitiveFlagsMu.RLock()
+	defer historySensitiveFlagsMu.RUnlock()
+
+	redacted := make([]string, 0, len(command))
+	for i := 0; i < len(command); i++ {
+		arg := command[i]
+		if k, _, found := strings.Cut(arg, "="); found {
+			if _, ok := historySensitiveFlags[k]; ok {
+				redacted = append(redacted, k+"="+redactedValue)
+				continue
+			}
+		}
+		redacted = append(redacted, arg)
+		if !historySensitiveFlags[arg] {
+			continue
+		}
+		if i+1 < len(command) {
+			redacted = append(redacted, redactedValue)
+			i++
+		}
+	}
+	return redacted
+}
+
 // commandRow type of command history row
 type historyRow struct {
 	Date    time.Time `json:"time"`
 	Command string    `json:"command"`
+	Args    []string  `json:"args,omitempty"` // the command split into args, empty in old rows
 	Code    int       `json:"exit_code"`
+	User    string    `json:"user,omitempty"` // OS user running the command, empty in old rows
+	Host    string    `json:"host,omitempty"` // hostname of the control node, empty in old rows
+	// CorrelationID is shared with the audit and log records of the same invocation
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Duration is how long the command took, zero in old rows
+	Duration time.Duration `json:"duration,omitempty"`
+	// Stderr is the tail of the stderr of a failed command, empty for the
+	// succeeded ones
+	Stderr string `json:"stderr,omitempty"`
 }
 
+// The orders the history can be sorted by
+const (
+	HistorySortTimeAsc  = "time-asc"
+	HistorySortTimeDesc = "time-desc"
+	HistorySortStatus   = "status"
+	HistorySortDuration = "duration"
+)
+
+// HistorySortOrders are the available values of the history sort order
+var HistorySortOrders = []string{HistorySortTimeAsc, HistorySortTimeDesc, HistorySortStatus, HistorySortDuration}
+
+// historyArchiveLayout is the layout of the month in the names of the archives
+// history files are compacted into, e.g. tiup-history-2024-01
+const historyArchiveLayout = "2006-01"
+
 // historyItem  record history row file item
 type historyItem struct {
 	path  string
 	info  fs.FileInfo
 	index int
+	month string // month of the rows in it if it's an archive, empty otherwise
 }
 
 // HistoryRecord record tiup exec cmd
-func HistoryRecord(env *Environment, command []string, date time.Time, code int) error {
-	if env == nil {
+func HistoryRecord(env *Environment, command []string, date time.Time, code int, stderr string) error {
+	// the history commands are not recorded, otherwise replaying a replay
+	// would run itself again and again
+	if env == nil || isHistoryCommand(command) {
 		return nil
 	}
 
@@ -67,15 +163,57 @@ func HistoryRecord(env *Environment, command []string, date time.Time, code int)
 		}
 	}
 
+	args := redactCommand(command)
 	h := &historyRow{
-		Command: strings.Join(command, " "),
+		Command: strings.Join(args, " "),
+		Args:    args,
 		Date:    date,
 		Code:    code,
+		User:    historyUser(),
+		// the command is recorded when it finishes
+		Duration: env.Now().Sub(date),
+
+		CorrelationID: logprinter.CorrelationID(),
+	}
+	// left blank rather than guessed if unknown
+	h.Host, _ = os.Hostname()
+	if code != 0 {
+		h.Stderr = historyStderr(stderr)
 	}
 
 	return h.save(historyPath)
 }
 
+// historyStderr trims the stderr to be saved to the last HistoryStderrSize
+// bytes without the color codes
+func historyStderr(stderr string) string {
+	stderr = strings.TrimSpace(ansiEscapeRegexp.ReplaceAllString(stderr, ""))
+	if len(stderr) > HistoryStderrSize {
+		stderr = stderr[len(stderr)-HistoryStderrSize:]
+		// don't start in the middle of a multi-byte char
+		for len(stderr) > 0 && !utf8.RuneStart(stderr[0]) {
+			stderr = stderr[1:]
+		}
+	}
+	return stderr
+}
+
+// FailureReason returns the last line of the stderr of a failed command as
+// the reason of the failure, empty if it's not recorded
+func (r *historyRow) FailureReason() string {
+	lines := strings.Split(strings.TrimSpace(r.Stderr), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// historyUser returns the OS user running TiUP, blank if it's unknown
+func historyUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
 // save save commandRow to file
 func (r *historyRow) save(dir string) error {
 	rBytes, err := json.Marshal(r)
@@ -94,18 +232,27 @@ func (r *historyRow) save(dir string) error {
 	return err
 }
 
-// GetHistory get tiup history
-func (env *Environment) GetHistory(count int, all bool) ([]*historyRow, error) {
+// GetHistory get tiup history, only rows within the duration are returned if since is not zero
+func (env *Environment) GetHistory(count int, all bool, since time.Duration) ([]*historyRow, error) {
 	fList, err := getHistoryFileList(env.LocalPath(HistoryDir))
 	if err != nil {
 		return nil, err
 	}
+	cutoff := env.Now().Add(-since)
 	rows := []*historyRow{}
 	for _, f := range fList {
+		// files are listed from the latest one, if a file is last modified before
+		// the cutoff, all rows in it and the files after it are too old
+		if since > 0 && f.info != nil && f.info.ModTime().Before(cutoff) {
+			break
+		}
 		rs, err := f.getHistory()
 		if err != nil {
 			return rows, err
 		}
+		if since > 0 {
+			rs = filterHistorySince(rs, cutoff)
+		}
 		if (len(rows)+len(rs)) > count && !all {
 			i := len(rows) + len(rs) - count
 			rows = append(rs[i:], rows...)
@@ -117,6 +264,153 @@ func (env *Environment) GetHistory(count int, all bool) ([]*historyRow, error) {
 	return rows, nil
 }
 
+// SortHistory sorts the history rows in place, sorting by status puts the
+// failed commands first and sorting by duration puts the slowest first,
+// rows with the same key are kept ordered by time
+func SortHistory(rows []*historyRow, by string) error {
+	byTime := func(i, j int) bool {
+		return rows[i].Date.Before(rows[j].Date)
+	}
+	switch by {
+	case "", HistorySortTimeAsc:
+		sort.SliceStable(rows, byTime)
+	case HistorySortTimeDesc:
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Date.After(rows[j].Date)
+		})
+	case HistorySortStatus:
+		sort.SliceStable(rows, byTime)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Code != 0 && rows[j].Code == 0
+		})
+	case HistorySortDuration:
+		sort.SliceStable(rows, byTime)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Duration > rows[j].Duration
+		})
+	default:
+		return errors.Errorf("unsupported sort order %s, available values are [%s]", by, strings.Join(HistorySortOrders, ", "))
+	}
+	return nil
+}
+
+// GetComponentHistory returns all history rows running the component with
+// arg as one of the positional args, e.g. the operations on a cluster
+func (env *Environment) GetComponentHistory(component, arg string) ([]*historyRow, error) {
+	rows, err := env.GetHistory(0, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*historyRow, 0)
+	for _, r := range rows {
+		args, ok := r.ComponentArgs(component)
+		if !ok {
+			continue
+		}
+		for _, a := range args {
+			if a == arg {
+				res = append(res, r)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// ComponentArgs returns the args passed to the component if the row is running
+// it, `component:version` is also accepted
+func (r *historyRow) ComponentArgs(component string) ([]string, bool) {
+	fields := strings.Fields(r.Command)
+	for i, f := range fields {
+		if strings.HasPrefix(f, "-") {
+			continue
+		}
+		if f == component || strings.HasPrefix(f, component+":") {
+			return fields[i+1:], true
+		}
+		// the first positional arg of tiup is the component, except the binary itself
+		if i > 0 {
+			break
+		}
+	}
+	return nil, false
+}
+
+// FindHistory returns the history row selected by sel, which is either the
+// correlation ID of the row, or the position counted back from the latest row,
+// i.e. 1 is the latest one. The IDs are hex strings which could be all digits,
+// so they are matched first.
+func (env *Environment) FindHistory(sel string) (*historyRow, error) {
+	rows, err := env.GetHistory(0, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(rows) - 1; i >= 0; i-- {
+		if rows[i].CorrelationID == sel {
+			return rows[i], nil
+		}
+	}
+
+	n, err := strconv.Atoi(sel)
+	if err != nil {
+		return nil, errors.Errorf("no history record with ID %s", sel)
+	}
+	if n <= 0 {
+		return nil, errors.Errorf("invalid history position %d, it must be greater than 0", n)
+	}
+	if len(rows) < n {
+		return nil, errors.Errorf("there are only %d history records", len(rows))
+	}
+	return rows[len(rows)-n], nil
+}
+
+// isHistoryCommand tells whether the command is `tiup history` or one of its
+// subcommands, the args start with the binary
+func isHistoryCommand(args []string) bool {
+	for _, arg := range args[min(len(args), 1):] {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		return arg == "history"
+	}
+	return false
+}
+
+// ReplayArgs returns the args to run the command of the row again without the
+// binary itself. Commands with secrets redacted can't be replayed as the
+// secrets are not recorded.
+func (r *historyRow) ReplayArgs() ([]string, error) {
+	args := r.Args
+	if len(args) == 0 {
+		// rows written by older versions only have the joined command
+		args = strings.Fields(r.Command)
+	}
+	for _, arg := range args {
+		if arg == redactedValue || strings.HasSuffix(arg, "="+redactedValue) {
+			return nil, errors.Errorf("the command can not be replayed as its secrets are redacted in history: %s", r.Command)
+		}
+	}
+	if len(args) < 2 {
+		return nil, errors.Errorf("nothing to replay in command: %s", r.Command)
+	}
+	// rows of the history commands may be written by older versions
+	if isHistoryCommand(args) {
+		return nil, errors.Errorf("the history commands can not be replayed: %s", r.Command)
+	}
+	return args[1:], nil
+}
+
+// filterHistorySince returns rows not earlier than the cutoff
+func filterHistorySince(rows []*historyRow, cutoff time.Time) []*historyRow {
+	res := make([]*historyRow, 0, len(rows))
+	for _, r := range rows {
+		if !r.Date.Before(cutoff) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
 // DeleteHistory delete history file
 func (env *Environment) DeleteHistory(retainDays int, skipConfirm bool) error {
 	if retainDays < 0 {
@@ -124,8 +418,7 @@ func (env *Environment) DeleteHistory(retainDays int, skipConfirm bool) error {
 	}
 
 	// history file before `DelBeforeTime` will be deleted
-	oneDayDuration, _ := time.ParseDuration("-24h")
-	delBeforeTime := time.Now().Add(oneDayDuration * time.Duration(retainDays))
+	delBeforeTime := utils.RetainBefore(env, retainDays)
 
 	if !skipConfirm {
 		fmt.Printf("History logs before %s will be %s!\n",
@@ -158,6 +451,102 @@ func (env *Environment) DeleteHistory(retainDays int, skipConfirm bool) error {
 	return nil
 }
 
+// CompactHistory merges the history files last written more than the days ago
+// into monthly archives, the rows are appended to the archive of the month they
+// are recorded in, keeping their order. It returns the number of files merged.
+func (env *Environment) CompactHistory(days int) (int, error) {
+	if days < 0 {
+		return 0, errors.Errorf("days cannot be less than 0")
+	}
+	dir := env.LocalPath(HistoryDir)
+	fList, err := getHistoryFileList(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	before := utils.RetainBefore(env, days)
+	compacted := 0
+	// from the oldest numbered file, so the rows are appended in order
+	for i := len(fList) - 1; i >= 0; i-- {
+		f := fList[i]
+		if f.month != "" || f.info == nil {
+			continue
+		}
+		if !f.info.ModTime().Before(before) {
+			// the files after it are written later
+			break
+		}
+		if err := compactHistoryFile(dir, f); err != nil {
+			return compacted, err
+		}
+		compacted++
+	}
+	return compacted, nil
+}
+
+// compactHistoryFile appends the lines of the history file to the archives of
+// the months of its rows and removes it, lines that can't be parsed go along
+// with the row before them
+func compactHistoryFile(dir string, f historyItem) error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	month := f.info.ModTime().Format(historyArchiveLayout)
+	var months []string
+	lines := make(map[string][]byte)
+	last := make(map[string]time.Time)
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		r := &historyRow{}
+		if err := json.Unmarshal([]byte(line), r); err == nil && !r.Date.IsZero() {
+			month = r.Date.Format(historyArchiveLayout)
+			last[month] = r.Date
+		}
+		if _, ok := lines[month]; !ok {
+			months = append(months, month)
+		}
+		lines[month] = append(lines[month], line...)
+	}
+
+	for _, m := range months {
+		path := filepath.Join(dir, historyPrefix+m)
+		if err := appendHistoryArchive(path, lines[m], last[m]); err != nil {
+			return err
+		}
+	}
+	return os.Remove(f.path)
+}
+
+// appendHistoryArchive appends the lines to the archive, its modification time
+// is set to the latest row in it, so the archives are aged by their rows rather
+// than the time of compacting when listing and deleting history
+func appendHistoryArchive(path string, lines []byte, latest time.Time) error {
+	fi, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fi.Write(lines); err != nil {
+		fi.Close()
+		return err
+	}
+	if err := fi.Close(); err != nil {
+		return err
+	}
+	// the files are compacted from the oldest one, the appended rows are the
+	// latest ones in the archive
+	if latest.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, latest, latest)
+}
+
 // getHistory get tiup history execution row
 func (i *historyItem) getHistory() ([]*historyRow, error) {
 	rows := []*historyRow{}
@@ -190,6 +579,10 @@ func (i *historyItem) getHistory() ([]*historyRow, error) {
 func getHistoryFileList(dir string) ([]historyItem, error) {
 	fileInfos, err := os.ReadDir(dir)
 	if err != nil {
+		// the history dir is created on the first write, so there is no history yet
+		if os.IsNotExist(err) {
+			return []historyItem{}, nil
+		}
 		return nil, err
 	}
 
@@ -199,23 +592,36 @@ func getHistoryFileList(dir string) ([]historyItem, error) {
 			continue
 		}
 
+		suffix, ok := strings.CutPrefix(fi.Name(), historyPrefix)
+		if !ok {
+			continue
+		}
+		item := historyItem{path: filepath.Join(dir, fi.Name())}
 		// another suffix
 		// ex: tiup-history-0.bak
-		i, err := strconv.Atoi((strings.TrimPrefix(fi.Name(), historyPrefix)))
-		if err != nil {
+		if i, err := strconv.Atoi(suffix); err == nil {
+			item.index = i
+		} else if _, err := time.Parse(historyArchiveLayout, suffix); err == nil {
+			item.month = suffix
+		} else {
 			continue
 		}
 
-		fInfo, _ := fi.Info()
-		hfileList = append(hfileList, historyItem{
-			path:  filepath.Join(dir, fi.Name()),
-			index: i,
-			info:  fInfo,
-		})
+		item.info, _ = fi.Info()
+		hfileList = append(hfileList, item)
 	}
 
+	// the archives are compacted from the oldest files, so they are listed
+	// after all the numbered files
 	sort.Slice(hfileList, func(i, j int) bool {
-		return hfileList[i].index > hfileList[j].index
+		a, b := hfileList[i], hfileList[j]
+		if (a.month == "") != (b.month == "") {
+			return a.month == ""
+		}
+		if a.month != "" {
+			return a.month > b.month
+		}
+		return a.index > b.index
 	})
 
 	return hfileList, nil
@@ -224,8 +630,8 @@ func getHistoryFileList(dir string) ([]historyItem, error) {
 // getLatestHistoryFile get the latest history file, use index 0 if it doesn't exist
 func getLatestHistoryFile(dir string) (item historyItem) {
 	fileList, err := getHistoryFileList(dir)
-	// start from 0
-	if len(fileList) == 0 || err != nil {
+	// start from 0, records are never appended to the archives
+	if len(fileList) == 0 || err != nil || fileList[0].month != "" {
 		item.index = 0
 		item.path = filepath.Join(dir, fmt.Sprintf("%s%s", historyPrefix, strconv.Itoa(item.index)))
 		return
@@ -0,0 +1,340 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactCommand(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(
+		[]string{"tiup", "foo", "--db-password", "******", "-d", "dir"},
+		redactCommand([]string{"tiup", "foo", "--db-password", "secret", "-d", "dir"}),
+	)
+	assert.Equal(
+		[]string{"tiup", "cluster", "import", "--password=******"},
+		redactCommand([]string{"tiup", "cluster", "import", "--password=secret"}),
+	)
+	// --password is a boolean flag, the positional arg after it is kept as is
+	assert.Equal(
+		[]string{"tiup", "cluster", "deploy", "--password", "foo", "v6.1.0", "topo.yaml"},
+		redactCommand([]string{"tiup", "cluster", "deploy", "--password", "foo", "v6.1.0", "topo.yaml"}),
+	)
+	assert.Equal(
+		[]string{"tiup", "cluster", "deploy", "--password", "--user", "root"},
+		redactCommand([]string{"tiup", "cluster", "deploy", "--password", "--user", "root"}),
+	)
+
+	RegisterHistorySensitiveFlags("--my-key")
+	assert.Equal(
+		[]string{"tiup", "foo", "--my-key", "******"},
+		redactCommand([]string{"tiup", "foo", "--my-key", "abc"}),
+	)
+}
+
+func TestGetHistoryMissingDir(t *testing.T) {
+	assert := require.New(t)
+
+	root := filepath.Join(t.TempDir(), "not-exist")
+	env := &Environment{profile: localdata.NewProfile(root, nil)}
+
+	rows, err := env.GetHistory(100, false, 0)
+	assert.Nil(err)
+	assert.Empty(rows)
+
+	rows, err = env.GetHistory(0, true, time.Hour)
+	assert.Nil(err)
+	assert.Empty(rows)
+}
+
+func TestGetHistorySince(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	now := time.Now()
+	assert.Nil(HistoryRecord(env, []string{"tiup", "old"}, now.Add(-3*time.Hour), 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "new"}, now.Add(-time.Minute), 0, ""))
+
+	rows, err := env.GetHistory(100, false, 2*time.Hour)
+	assert.Nil(err)
+	assert.Len(rows, 1)
+	assert.Equal("tiup new", rows[0].Command)
+
+	rows, err = env.GetHistory(100, false, 0)
+	assert.Nil(err)
+	assert.Len(rows, 2)
+}
+
+func TestHistoryUserAndHost(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	historyDir := env.LocalPath(HistoryDir)
+	assert.Nil(os.MkdirAll(historyDir, 0755))
+	// a row written by old versions
+	assert.Nil(os.WriteFile(filepath.Join(historyDir, historyPrefix+"0"),
+		[]byte(`{"time":"2023-01-01T00:00:00Z","command":"tiup old","exit_code":0}`+"\n"), 0644))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "new"}, time.Now(), 0, ""))
+
+	rows, err := env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 2)
+	assert.Equal("tiup old", rows[0].Command)
+	assert.Empty(rows[0].User)
+	assert.Empty(rows[0].Host)
+
+	hostname, _ := os.Hostname()
+	assert.Equal(historyUser(), rows[1].User)
+	assert.Equal(hostname, rows[1].Host)
+}
+
+func TestHistoryStderr(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	stderr := strings.Repeat("é", HistoryStderrSize) + "\n\x1b[31mError: cluster foo not found\x1b[0m\n"
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "start", "foo"}, time.Now(), 1, stderr))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "start", "bar"}, time.Now(), 0, "some warnings"))
+
+	rows, err := env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 2)
+	assert.LessOrEqual(len(rows[0].Stderr), HistoryStderrSize)
+	assert.True(utf8.ValidString(rows[0].Stderr))
+	assert.True(strings.HasSuffix(rows[0].Stderr, "\nError: cluster foo not found"))
+	assert.Equal("Error: cluster foo not found", rows[0].FailureReason())
+
+	// nothing is kept for the succeeded commands
+	assert.Empty(rows[1].Stderr)
+	assert.Empty(rows[1].FailureReason())
+}
+
+func TestGetComponentHistory(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	now := time.Now()
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "start", "foo"}, now, 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster:v1.12.0", "stop", "foo", "-R", "tikv"}, now, 1, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "start", "bar"}, now, 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "dm", "start", "foo"}, now, 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "playground", "cluster", "foo"}, now, 0, ""))
+
+	rows, err := env.GetComponentHistory("cluster", "foo")
+	assert.Nil(err)
+	assert.Len(rows, 2)
+	args, ok := rows[1].ComponentArgs("cluster")
+	assert.True(ok)
+	assert.Equal([]string{"stop", "foo", "-R", "tikv"}, args)
+	assert.Equal(1, rows[1].Code)
+}
+
+func TestReplayHistory(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	now := time.Now()
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "exec", "foo", "--command", "ls -l"}, now, 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "deploy", "foo", "--password", "bar"}, now, 0, ""))
+	assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "deploy", "foo", "--password=bar"}, now, 0, ""))
+
+	row, err := env.FindHistory("3")
+	assert.Nil(err)
+	args, err := row.ReplayArgs()
+	assert.Nil(err)
+	assert.Equal([]string{"cluster", "exec", "foo", "--command", "ls -l"}, args)
+
+	row, err = env.FindHistory("2")
+	assert.Nil(err)
+	assert.Equal("tiup cluster deploy foo --password bar", row.Command)
+
+	row, err = env.FindHistory(row.CorrelationID)
+	assert.Nil(err)
+	assert.Equal("tiup cluster deploy foo --password=******", row.Command)
+	_, err = row.ReplayArgs()
+	assert.NotNil(err)
+
+	_, err = env.FindHistory("4")
+	assert.NotNil(err)
+	_, err = env.FindHistory("0")
+	assert.NotNil(err)
+
+	// the history commands are not recorded, nor replayed if written by old versions
+	assert.Nil(HistoryRecord(env, []string{"tiup", "history", "replay", "1", "-y"}, now, 0, ""))
+	rows, err := env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 3)
+	_, err = (&historyRow{Command: "tiup history replay 1 -y"}).ReplayArgs()
+	assert.NotNil(err)
+}
+
+func TestHistoryRotation(t *testing.T) {
+	assert := require.New(t)
+
+	defer func(size int64) { historySize = size }(historySize)
+	historySize = 256
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "display", strconv.Itoa(i)}, now, 0, ""))
+	}
+
+	files, err := getHistoryFileList(env.LocalPath(HistoryDir))
+	assert.Nil(err)
+	assert.Greater(len(files), 1)
+	// the latest file is listed first
+	assert.Equal(len(files)-1, files[0].index)
+
+	// records are still returned in chronological order across files
+	rows, err := env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 10)
+	for i, r := range rows {
+		assert.Equal("tiup cluster display "+strconv.Itoa(i), r.Command)
+	}
+	rows, err = env.GetHistory(3, false, 0)
+	assert.Nil(err)
+	assert.Len(rows, 3)
+	assert.Equal("tiup cluster display 7", rows[0].Command)
+}
+
+func TestCompactHistory(t *testing.T) {
+	assert := require.New(t)
+
+	defer func(size int64) { historySize = size }(historySize)
+	historySize = 256
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	dir := env.LocalPath(HistoryDir)
+	// rows across the end of January, the files are written one day after another
+	start := time.Date(2024, 1, 28, 12, 0, 0, 0, time.Local)
+	for i := 0; i < 10; i++ {
+		date := start.Add(time.Duration(i) * 24 * time.Hour)
+		assert.Nil(HistoryRecord(env, []string{"tiup", "cluster", "display", strconv.Itoa(i)}, date, 0, ""))
+		files, err := getHistoryFileList(dir)
+		assert.Nil(err)
+		assert.Nil(os.Chtimes(files[0].path, date, date))
+	}
+	files, err := getHistoryFileList(dir)
+	assert.Nil(err)
+	total := len(files)
+	assert.Greater(total, 3)
+
+	// the files last written before Feb 5 are compacted
+	env.SetClock(utils.FixedClock(time.Date(2024, 2, 7, 0, 0, 0, 0, time.Local)))
+	n, err := env.CompactHistory(2)
+	assert.Nil(err)
+	assert.Greater(n, 0)
+	assert.Less(n, total)
+
+	files, err = getHistoryFileList(dir)
+	assert.Nil(err)
+	// the numbered files are listed before the archives from the latest one
+	assert.Equal("", files[0].month)
+	assert.Equal("2024-02", files[len(files)-2].month)
+	assert.Equal("2024-01", files[len(files)-1].month)
+
+	rows, err := env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 10)
+	for i, r := range rows {
+		assert.Equal("tiup cluster display "+strconv.Itoa(i), r.Command)
+	}
+	rows, err = env.GetHistory(6, false, 0)
+	assert.Nil(err)
+	assert.Equal("tiup cluster display 4", rows[0].Command)
+
+	// new rows keep going to the numbered files, even if all of them are compacted
+	env.SetClock(utils.FixedClock(time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)))
+	_, err = env.CompactHistory(0)
+	assert.Nil(err)
+	files, err = getHistoryFileList(dir)
+	assert.Nil(err)
+	assert.Len(files, 2)
+	assert.Nil(HistoryRecord(env, []string{"tiup", "status"}, time.Now(), 0, ""))
+	rows, err = env.GetHistory(0, true, 0)
+	assert.Nil(err)
+	assert.Len(rows, 11)
+	assert.Equal("tiup status", rows[10].Command)
+	assert.Equal("tiup cluster display 0", rows[0].Command)
+}
+
+func TestDeleteHistoryRetainDays(t *testing.T) {
+	assert := require.New(t)
+
+	env := &Environment{profile: localdata.NewProfile(t.TempDir(), nil)}
+	assert.Nil(HistoryRecord(env, []string{"tiup", "status"}, time.Now(), 0, ""))
+	files, err := getHistoryFileList(env.LocalPath(HistoryDir))
+	assert.Nil(err)
+	assert.Len(files, 1)
+
+	// the history file was last written at 23:59 of day 1
+	written := time.Date(2023, 1, 1, 23, 59, 0, 0, time.Local)
+	assert.Nil(os.Chtimes(files[0].path, written, written))
+
+	// at 23:58 of day 3 it's still within the last two days
+	env.SetClock(utils.FixedClock(time.Date(2023, 1, 3, 23, 58, 0, 0, time.Local)))
+	assert.Nil(env.DeleteHistory(2, true))
+	files, err = getHistoryFileList(env.LocalPath(HistoryDir))
+	assert.Nil(err)
+	assert.Len(files, 1)
+
+	// at 00:01 of day 4 it expires
+	env.SetClock(utils.FixedClock(time.Date(2023, 1, 4, 0, 1, 0, 0, time.Local)))
+	assert.Nil(env.DeleteHistory(2, true))
+	files, err = getHistoryFileList(env.LocalPath(HistoryDir))
+	assert.Nil(err)
+	assert.Empty(files)
+}
+
+func TestSortHistory(t *testing.T) {
+	assert := require.New(t)
+
+	t0 := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	rows := []*historyRow{
+		{Date: t0.Add(time.Minute), Command: "b", Code: 0, Duration: time.Minute},
+		{Date: t0, Command: "a", Code: 1},
+		{Date: t0.Add(2 * time.Minute), Command: "c", Code: 1, Duration: time.Second},
+	}
+	commands := func() string {
+		res := ""
+		for _, r := range rows {
+			res += r.Command
+		}
+		return res
+	}
+
+	assert.Nil(SortHistory(rows, HistorySortTimeAsc))
+	assert.Equal("abc", commands())
+	assert.Nil(SortHistory(rows, HistorySortTimeDesc))
+	assert.Equal("cba", commands())
+	assert.Nil(SortHistory(rows, HistorySortStatus))
+	assert.Equal("acb", commands())
+	assert.Nil(SortHistory(rows, HistorySortDuration))
+	assert.Equal("bca", commands())
+	assert.NotNil(SortHistory(rows, "size"))
+}
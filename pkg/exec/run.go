@@ -16,6 +16,7 @@ package exec
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -33,6 +34,7 @@ import (
 	"github.com/pingcap/tiup/pkg/utils"
 	"github.com/pingcap/tiup/pkg/version"
 	"golang.org/x/mod/semver"
+	"golang.org/x/term"
 )
 
 // Skip displaying "Starting component ..." message for some commonly used components.
@@ -104,7 +106,12 @@ func RunComponent(env *environment.Environment, tag, spec, binPath string, args
 		}
 	}()
 
-	return c.Wait()
+	err = c.Wait()
+	// the daemons forked by the component may keep the stderr pipe open
+	if err == exec.ErrWaitDelay {
+		return nil
+	}
+	return err
 }
 
 func cleanDataDir(rm bool, dir string) {
@@ -175,11 +182,40 @@ func PrepareCommand(p *PrepareCommandParams) (*exec.Cmd, error) {
 	c.Env = envs
 	c.Stdin = os.Stdin
 	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
+	c.Stderr = componentStderr(os.Stderr)
+	if c.Stderr != os.Stderr {
+		c.WaitDelay = stderrWaitDelay
+	}
 
 	return c, nil
 }
 
+// stderrTail keeps the tail of the stderr of the components run, so it could
+// be saved to the history if they fail
+var stderrTail = utils.NewTailWriter(environment.HistoryStderrSize)
+
+// stderrWaitDelay is how long the stderr of a component is still copied after
+// it exits, for the daemons forked by it that inherit the stderr
+const stderrWaitDelay = time.Second
+
+// isTerminal tells whether the fd is a terminal
+var isTerminal = term.IsTerminal
+
+// componentStderr returns the stderr for the components. A terminal is passed
+// to them as is, so that they are able to interact with it, and its tail is
+// not recorded then. Otherwise it's teed to stderrTail.
+func componentStderr(stderr *os.File) io.Writer {
+	if isTerminal(int(stderr.Fd())) {
+		return stderr
+	}
+	return io.MultiWriter(stderr, stderrTail)
+}
+
+// StderrTail returns the tail of the stderr of the components run
+func StderrTail() string {
+	return stderrTail.String()
+}
+
 func cmdCheckUpdate(component string, version utils.Version) {
 	const (
 		slowTimeout   = 1 * time.Second // Timeout to display checking message
@@ -0,0 +1,54 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentStderr(t *testing.T) {
+	assert := require.New(t)
+	defer func(f func(int) bool) { isTerminal = f }(isTerminal)
+
+	// a terminal is passed to the component as the file itself
+	isTerminal = func(int) bool { return true }
+	assert.Equal(os.Stderr, componentStderr(os.Stderr))
+
+	isTerminal = func(int) bool { return false }
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	assert.Nil(err)
+	defer f.Close()
+	stderr := componentStderr(f)
+	assert.NotEqual(f, stderr)
+
+	// the daemon forked by the component doesn't block waiting for it
+	c := exec.Command("sh", "-c", "echo failed >&2; sleep 10 &")
+	c.Stderr = stderr
+	c.WaitDelay = stderrWaitDelay
+	start := time.Now()
+	assert.Nil(c.Start())
+	err = c.Wait()
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.True(err == nil || err == exec.ErrWaitDelay)
+	assert.Contains(StderrTail(), "failed")
+
+	data, err := os.ReadFile(f.Name())
+	assert.Nil(err)
+	assert.Equal("failed\n", string(data))
+}
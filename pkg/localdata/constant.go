@@ -93,6 +93,10 @@ const (
 	// EnvNameSCPPath is the variable name by which user can specific the executable scp binary path
 	EnvNameSCPPath = "TIUP_SCP_PATH"
 
+	// EnvNameSSHAllowedHosts is the variable name by which user can restrict the hosts TiUP may connect to
+	// with ssh, it's a comma separated list of hosts or CIDRs
+	EnvNameSSHAllowedHosts = "TIUP_SSH_ALLOWED_HOSTS"
+
 	// EnvNameKeepSourceTarget is the variable name by which user can keep the source target or not
 	EnvNameKeepSourceTarget = "TIUP_KEEP_SOURCE_TARGET"
 
@@ -102,6 +106,9 @@ const (
 	// EnvNameLogPath is the variable name by which user can write the log files into
 	EnvNameLogPath = "TIUP_LOG_PATH"
 
+	// EnvNameAuditDir is the variable name by which user can override the directory of cluster audit logs
+	EnvNameAuditDir = "TIUP_AUDIT_DIR"
+
 	// EnvNameDebug is the variable name by which user can set tiup runs in debug mode(eg. print panic logs)
 	EnvNameDebug = "TIUP_CLUSTER_DEBUG"
 
@@ -14,6 +14,7 @@
 package logger
 
 import (
+	logprinter "github.com/pingcap/tiup/pkg/logger/printer"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -24,6 +25,6 @@ func InitGlobalLogger() {
 		newAuditLogCore(),
 		newDebugLogCore(),
 	)
-	logger := zap.New(core)
+	logger := zap.New(core, zap.Fields(zap.String("correlation_id", logprinter.CorrelationID())))
 	zap.ReplaceGlobals(logger)
 }
@@ -0,0 +1,50 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logprinter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+)
+
+// EnvNameCorrelationID is the variable name of the correlation ID, it's set by
+// the first TiUP process of an invocation and inherited by the components
+const EnvNameCorrelationID = "TIUP_CORRELATION_ID"
+
+var (
+	correlationOnce sync.Once
+	correlationID   string
+)
+
+// CorrelationID returns the short ID shared by all history records, audit
+// records and structured log lines of a single TiUP invocation. It's read
+// from the environment if a parent process has set it, otherwise a new one
+// is generated and exported to the environment of child processes.
+func CorrelationID() string {
+	correlationOnce.Do(func() {
+		correlationID = os.Getenv(EnvNameCorrelationID)
+		if correlationID != "" {
+			return
+		}
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return
+		}
+		correlationID = hex.EncodeToString(b)
+		_ = os.Setenv(EnvNameCorrelationID, correlationID)
+	})
+	return correlationID
+}
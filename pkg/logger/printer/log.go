@@ -19,12 +19,14 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 var (
 	outputFmt = DisplayModeDefault // global output format of logger
+	logFmt    = DisplayModeDefault // global format of log lines, overrides the output format if set
 
 	stdout io.Writer = os.Stdout
 	stderr io.Writer = os.Stderr
@@ -53,16 +55,31 @@ func fmtDisplayMode(m string) DisplayMode {
 	return dp
 }
 
-func printLog(w io.Writer, mode DisplayMode, level, format string, args ...any) {
+func printLog(w io.Writer, mode DisplayMode, level string, fields []Field, format string, args ...any) {
+	if logFmt != DisplayModeDefault {
+		mode = logFmt
+	}
 	switch mode {
 	case DisplayModeJSON:
-		obj := struct {
+		var obj any = struct {
 			Level string `json:"level"`
 			Msg   string `json:"message"`
 		}{
 			Level: level,
 			Msg:   fmt.Sprintf(format, args...),
 		}
+		// structured log lines carry the time and context fields
+		if logFmt == DisplayModeJSON {
+			m := make(map[string]any, len(fields)+3)
+			for _, f := range fields {
+				m[f.Key] = f.Value
+			}
+			m["time"] = time.Now().Format(time.RFC3339)
+			m["correlation_id"] = CorrelationID()
+			m["level"] = level
+			m["message"] = fmt.Sprintf(format, args...)
+			obj = m
+		}
 		data, err := json.Marshal(obj)
 		if err != nil {
 			_, _ = fmt.Fprintf(w, "{\"error\":\"%s\"}", err)
@@ -89,6 +106,14 @@ func SetDisplayModeFromString(m string) {
 	outputFmt = fmtDisplayMode(m)
 }
 
+// SetLogFormatFromString changes the global format of log lines of all
+// loggers without changing how other outputs (e.g. tables) are displayed,
+// with "json" each line is an object with the level, message, time and the
+// context fields of the logger.
+func SetLogFormatFromString(m string) {
+	logFmt = fmtDisplayMode(m)
+}
+
 // Debugf output the debug message to console
 func Debugf(format string, args ...any) {
 	zap.L().Debug(fmt.Sprintf(format, args...))
@@ -98,21 +123,21 @@ func Debugf(format string, args ...any) {
 // Deprecated: Use zap.L().Info() instead
 func Infof(format string, args ...any) {
 	zap.L().Info(fmt.Sprintf(format, args...))
-	printLog(stdout, outputFmt, "info", format, args...)
+	printLog(stdout, outputFmt, "info", nil, format, args...)
 }
 
 // Warnf output the warning message to console
 // Deprecated: Use zap.L().Warn() instead
 func Warnf(format string, args ...any) {
 	zap.L().Warn(fmt.Sprintf(format, args...))
-	printLog(stderr, outputFmt, "warn", format, args...)
+	printLog(stderr, outputFmt, "warn", nil, format, args...)
 }
 
 // Errorf output the error message to console
 // Deprecated: Use zap.L().Error() instead
 func Errorf(format string, args ...any) {
 	zap.L().Error(fmt.Sprintf(format, args...))
-	printLog(stderr, outputFmt, "error", format, args...)
+	printLog(stderr, outputFmt, "error", nil, format, args...)
 }
 
 // SetStdout redirect stdout to a custom writer
@@ -36,6 +36,24 @@ type Logger struct {
 
 	stdout io.Writer
 	stderr io.Writer
+
+	fields []Field // context fields of the structured log lines
+}
+
+// Field is a key-value pair of context in structured log lines
+type Field struct {
+	Key   string
+	Value any
+}
+
+// With returns a copy of the logger which adds the field to structured log
+// lines, the human readable output is not changed
+func (l *Logger) With(key string, value any) *Logger {
+	nl := *l
+	nl.fields = make([]Field, 0, len(l.fields)+1)
+	nl.fields = append(nl.fields, l.fields...)
+	nl.fields = append(nl.fields, Field{Key: key, Value: value})
+	return &nl
 }
 
 // NewLogger creates a Logger with default settings
@@ -47,6 +65,14 @@ func NewLogger(m string) *Logger {
 	}
 }
 
+func (l *Logger) zapFields() []zap.Field {
+	fields := make([]zap.Field, 0, len(l.fields))
+	for _, f := range l.fields {
+		fields = append(fields, zap.Any(f.Key, f.Value))
+	}
+	return fields
+}
+
 // SetStdout redirect stdout to a custom writer
 func (l *Logger) SetStdout(w io.Writer) {
 	l.stdout = w
@@ -74,23 +100,23 @@ func (l *Logger) GetDisplayMode() DisplayMode {
 
 // Debugf output the debug message to console
 func (l *Logger) Debugf(format string, args ...any) {
-	zap.L().Debug(fmt.Sprintf(format, args...))
+	zap.L().Debug(fmt.Sprintf(format, args...), l.zapFields()...)
 }
 
 // Infof output the log message to console
 func (l *Logger) Infof(format string, args ...any) {
-	zap.L().Info(fmt.Sprintf(format, args...))
-	printLog(l.stdout, l.outputFmt, "info", format, args...)
+	zap.L().Info(fmt.Sprintf(format, args...), l.zapFields()...)
+	printLog(l.stdout, l.outputFmt, "info", l.fields, format, args...)
 }
 
 // Warnf output the warning message to console
 func (l *Logger) Warnf(format string, args ...any) {
-	zap.L().Warn(fmt.Sprintf(format, args...))
-	printLog(l.stderr, l.outputFmt, "warn", format, args...)
+	zap.L().Warn(fmt.Sprintf(format, args...), l.zapFields()...)
+	printLog(l.stderr, l.outputFmt, "warn", l.fields, format, args...)
 }
 
 // Errorf output the error message to console
 func (l *Logger) Errorf(format string, args ...any) {
-	zap.L().Error(fmt.Sprintf(format, args...))
-	printLog(l.stderr, l.outputFmt, "error", format, args...)
+	zap.L().Error(fmt.Sprintf(format, args...), l.zapFields()...)
+	printLog(l.stderr, l.outputFmt, "error", l.fields, format, args...)
 }
@@ -0,0 +1,100 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/term"
+)
+
+// ErrNotInteractive means an interactive prompt is requested while stdin is not a terminal
+var ErrNotInteractive = errNS.NewType("not_interactive", utils.ErrTraitPreCheck)
+
+// PromptForSelection prints the rows as a table numbered from 1 and lets the
+// user pick some of them by numbers and ranges, e.g. `1,3,5-7`, or `all`, the
+// indexes of the picked rows are returned in order. It fails if stdin is not
+// a terminal.
+func PromptForSelection(header []string, rows [][]string) ([]int, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, ErrNotInteractive.New("Unable to prompt for selection as stdin is not a terminal")
+	}
+
+	table := [][]string{append([]string{"#"}, header...)}
+	for i, row := range rows {
+		table = append(table, append([]string{strconv.Itoa(i + 1)}, row...))
+	}
+	PrintTable(table, true)
+
+	for {
+		ans := strings.TrimSpace(Prompt("Select by numbers and ranges, e.g. 1,3,5-7, or `all`:"))
+		if ans == "" {
+			return nil, errOperationAbort.New("Operation aborted by user (nothing selected)")
+		}
+		picked, err := parseSelection(ans, len(rows))
+		if err == nil {
+			return picked, nil
+		}
+		fmt.Println(err)
+	}
+}
+
+// parseSelection parses the numbers and ranges counted from 1 into sorted
+// unique indexes counted from 0 of n options
+func parseSelection(s string, n int) ([]int, error) {
+	if strings.EqualFold(s, "all") {
+		picked := make([]int, n)
+		for i := range picked {
+			picked[i] = i
+		}
+		return picked, nil
+	}
+
+	var picked []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection '%s'", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("invalid selection '%s'", part)
+			}
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("selection '%s' is out of range 1-%d", part, n)
+		}
+		for i := start; i <= end; i++ {
+			if !slices.Contains(picked, i-1) {
+				picked = append(picked, i-1)
+			}
+		}
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("nothing is selected")
+	}
+	slices.Sort(picked)
+	return picked, nil
+}
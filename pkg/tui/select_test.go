@@ -0,0 +1,55 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelection(t *testing.T) {
+	cases := []struct {
+		input  string
+		picked []int // nil if an error is expected
+	}{
+		{"1", []int{0}},
+		{"1,3", []int{0, 2}},
+		{" 3 , 1 ", []int{0, 2}},
+		{"2-4", []int{1, 2, 3}},
+		{"1,3,4-5", []int{0, 2, 3, 4}},
+		{"2-3,3-4,2", []int{1, 2, 3}},
+		{"1,,2,", []int{0, 1}},
+		{"4 - 5", []int{3, 4}},
+		{"all", []int{0, 1, 2, 3, 4}},
+		{"ALL", []int{0, 1, 2, 3, 4}},
+		{"0", nil},
+		{"6", nil},
+		{"3-6", nil},
+		{"4-2", nil},
+		{"a", nil},
+		{"1-b", nil},
+		{"-1", nil},
+		{",", nil},
+	}
+	for _, c := range cases {
+		picked, err := parseSelection(c.input, 5)
+		if c.picked == nil {
+			require.NotNil(t, err, c.input)
+			continue
+		}
+		require.Nil(t, err, c.input)
+		require.Equal(t, c.picked, picked, c.input)
+	}
+}
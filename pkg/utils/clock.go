@@ -0,0 +1,46 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import "time"
+
+// Clock tells the current time, time based logic should get the time from
+// it instead of calling time.Now() directly so that it could be tested
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the Clock of the system time
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+// Now implements Clock
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock is a Clock always telling the same time, e.g. in tests
+type FixedClock time.Time
+
+// Now implements Clock
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// RetainBefore returns the time before which the records should be deleted
+// if the records of the last retainDays days are kept
+func RetainBefore(c Clock, retainDays int) time.Time {
+	return c.Now().Add(-24 * time.Hour * time.Duration(retainDays))
+}
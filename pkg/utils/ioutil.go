@@ -307,6 +307,42 @@ func TailN(fname string, n int) (lines []string, err error) {
 	return
 }
 
+// TailWriter is a writer keeping only the last bytes written to it up to
+// the size, it's safe to be written concurrently
+type TailWriter struct {
+	mu   sync.Mutex
+	size int
+	buf  []byte
+}
+
+// NewTailWriter creates a TailWriter keeping the last size bytes
+func NewTailWriter(size int) *TailWriter {
+	return &TailWriter{size: size}
+}
+
+// Write implements io.Writer
+func (w *TailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	if n >= w.size {
+		p = p[n-w.size:]
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.size {
+		w.buf = append(w.buf[:0], w.buf[len(w.buf)-w.size:]...)
+	}
+	return n, nil
+}
+
+// String returns the bytes kept
+func (w *TailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
+
 func fileLock(path string) *sync.Mutex {
 	filesLock.Lock()
 	defer filesLock.Unlock()
@@ -213,3 +213,16 @@ func (s *TestIOUtilSuite) TestConcurrentSaveFileWithBackup(c *C) {
 		c.Assert(bytes.Equal(body, data), IsTrue)
 	}
 }
+
+func (s *TestIOUtilSuite) TestTailWriter(c *C) {
+	w := NewTailWriter(8)
+	c.Assert(w.String(), Equals, "")
+	_, _ = w.Write([]byte("abc"))
+	c.Assert(w.String(), Equals, "abc")
+	n, err := w.Write([]byte("defghij"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 7)
+	c.Assert(w.String(), Equals, "cdefghij")
+	_, _ = w.Write([]byte("0123456789"))
+	c.Assert(w.String(), Equals, "23456789")
+}
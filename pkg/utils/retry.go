@@ -14,7 +14,9 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"strings"
 	"time"
 )
@@ -35,9 +37,13 @@ func RetryUntil(f func() error, when func(error) bool) error {
 
 // RetryOption is options for Retry()
 type RetryOption struct {
-	Attempts int64
+	Attempts int64 // max number of attempts, default to as many as the timeout allows
 	Delay    time.Duration
 	Timeout  time.Duration
+	// Jitter randomizes each delay by up to the fraction of Delay in either
+	// direction, e.g. 0.2 for +/-20%, so that the retries of many callers
+	// started together spread out, default 0 means no jitter
+	Jitter float64
 }
 
 // default values for RetryOption
@@ -50,6 +56,12 @@ var (
 // Retry retries the func until it returns no error or reaches attempts limit or
 // timed out, either one is earlier
 func Retry(doFunc func() error, opts ...RetryOption) error {
+	return RetryWithContext(context.Background(), doFunc, opts...)
+}
+
+// RetryWithContext is the same as Retry, but also stops as soon as the context
+// is done and returns the error of the context
+func RetryWithContext(ctx context.Context, doFunc func() error, opts ...RetryOption) error {
 	var cfg RetryOption
 	if len(opts) > 0 {
 		cfg = opts[0]
@@ -73,6 +85,10 @@ func Retry(doFunc func() error, opts ...RetryOption) error {
 		cfg.Attempts = cfg.Timeout.Milliseconds()/cfg.Delay.Milliseconds() + 1
 	}
 
+	// not even a single attempt if the context is already done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	timeoutChan := time.After(cfg.Timeout)
 
 	// call the function
@@ -83,18 +99,38 @@ func Retry(doFunc func() error, opts ...RetryOption) error {
 			return nil
 		}
 
+		// no need to wait after the last attempt
+		if attemptCount+1 >= cfg.Attempts {
+			break
+		}
+
 		// check for timeout
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-timeoutChan:
 			return fmt.Errorf("operation timed out after %s", cfg.Timeout)
 		default:
-			time.Sleep(cfg.Delay)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitterDelay(cfg.Delay, cfg.Jitter)):
 		}
 	}
 
 	return fmt.Errorf("operation exceeds the max retry attempts of %d. error of last attempt: %s", cfg.Attempts, err)
 }
 
+// jitterDelay returns the delay randomized by up to the fraction of it in either direction
+func jitterDelay(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return delay
+	}
+	jitter = min(jitter, 1)
+	return delay + time.Duration((rand.Float64()*2-1)*jitter*float64(delay))
+}
+
 // IsTimeoutOrMaxRetry return true if it's timeout or reach max retry.
 func IsTimeoutOrMaxRetry(err error) bool {
 	if err == nil {
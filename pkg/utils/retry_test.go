@@ -0,0 +1,81 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+type retrySuite struct{}
+
+var _ = Suite(&retrySuite{})
+
+func (s *retrySuite) TestRetryAttempts(c *C) {
+	calls := 0
+	start := time.Now()
+	err := Retry(func() error {
+		calls++
+		return errors.New("failed")
+	}, RetryOption{
+		Attempts: 3,
+		Delay:    time.Millisecond * 10,
+		Timeout:  time.Minute,
+	})
+	c.Assert(err, NotNil)
+	c.Assert(IsTimeoutOrMaxRetry(err), IsTrue)
+	c.Assert(calls, Equals, 3)
+	// the attempts are exhausted long before the timeout
+	c.Assert(time.Since(start) < time.Second, IsTrue)
+}
+
+func (s *retrySuite) TestRetryContextDone(c *C) {
+	calls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RetryWithContext(ctx, func() error {
+		calls++
+		return errors.New("failed")
+	}, RetryOption{Delay: time.Millisecond * 10, Timeout: time.Minute})
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(calls, Equals, 0)
+
+	// a deadline of the context earlier than the timeout stops the retries
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	err = RetryWithContext(ctx, func() error {
+		calls++
+		return errors.New("failed")
+	}, RetryOption{Delay: time.Millisecond * 10, Timeout: time.Minute})
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	c.Assert(calls > 0, IsTrue)
+	c.Assert(time.Since(start) < time.Second, IsTrue)
+}
+
+func (s *retrySuite) TestJitterDelay(c *C) {
+	delay := time.Second
+	c.Assert(jitterDelay(delay, 0), Equals, delay)
+	for i := 0; i < 100; i++ {
+		d := jitterDelay(delay, 0.2)
+		c.Assert(d >= delay*8/10 && d <= delay*12/10, IsTrue, Commentf("delay %s", d))
+	}
+	// the fraction is capped so the delay is never negative
+	for i := 0; i < 100; i++ {
+		c.Assert(jitterDelay(delay, 5) >= 0, IsTrue)
+	}
+}